@@ -0,0 +1,103 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/dtomacheski/extract-data-go/internal/database"
+	"github.com/dtomacheski/extract-data-go/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// GetCurrentDocumentation returns the current processed documentation of a repository at the
+// ref query parameter, or the most recently stored one without it. With format=txt the
+// document is returned as plain text.
+func (h *Handler) GetCurrentDocumentation(c *gin.Context) {
+	if !h.requireDocumentStorage(c) {
+		return
+	}
+
+	owner, repo := c.Param("owner"), c.Param("repo")
+	doc, err := h.DocumentRepository.GetCurrentDocumentation(c.Request.Context(), owner, repo, c.Query("ref"))
+	if err != nil {
+		respondWithError(c, "documentation_not_found", err)
+		return
+	}
+	respondWithDocVersion(c, &database.DocVersion{
+		RepoName:      doc.RepoName,
+		Ref:           doc.Ref,
+		CommitSHA:     doc.CommitSHA,
+		Filename:      doc.Filename,
+		Size:          doc.Size,
+		SnippetsCount: doc.SnippetsCount,
+		Content:       doc.Content,
+		CreatedAt:     doc.CreatedAt,
+		UpdatedAt:     doc.UpdatedAt,
+	}, "Current documentation retrieved successfully")
+}
+
+// ListDocumentationVersions lists the stored versions of a repository's processed
+// documentation, most recent first; the optional ref query parameter narrows them to a ref
+func (h *Handler) ListDocumentationVersions(c *gin.Context) {
+	if !h.requireDocumentStorage(c) {
+		return
+	}
+
+	owner, repo := c.Param("owner"), c.Param("repo")
+	versions, err := h.DocumentRepository.ListDocumentationVersions(c.Request.Context(), owner, repo, c.Query("ref"))
+	if err != nil {
+		h.Logger.Printf("Failed to list documentation versions of %s/%s: %v", owner, repo, err)
+		respondWithError(c, "documentation_versions_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status:  http.StatusOK,
+		Message: "Documentation versions retrieved successfully",
+		Data:    versions,
+	})
+}
+
+// GetDocumentationVersion returns the processed documentation of a repository stored for
+// a commit. With format=txt the document is returned as plain text.
+func (h *Handler) GetDocumentationVersion(c *gin.Context) {
+	if !h.requireDocumentStorage(c) {
+		return
+	}
+
+	owner, repo := c.Param("owner"), c.Param("repo")
+	version, err := h.DocumentRepository.GetDocumentationVersion(c.Request.Context(), owner, repo, c.Param("sha"))
+	if err != nil {
+		respondWithError(c, "documentation_version_not_found", err)
+		return
+	}
+
+	respondWithDocVersion(c, version, "Documentation version retrieved successfully")
+}
+
+// respondWithDocVersion writes a stored document as JSON, or as plain text with format=txt
+func respondWithDocVersion(c *gin.Context, version *database.DocVersion, message string) {
+	if c.Query("format") == "txt" {
+		c.Header("Content-Type", "text/plain; charset=utf-8")
+		c.String(http.StatusOK, version.Content)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status:  http.StatusOK,
+		Message: message,
+		Data:    version,
+	})
+}
+
+// requireDocumentStorage answers 503 when processed documentation is not stored
+func (h *Handler) requireDocumentStorage(c *gin.Context) bool {
+	if h.DocumentRepository != nil && h.DocumentRepository.IsEnabled() {
+		return true
+	}
+	c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+		Error:   "storage_unavailable",
+		Message: "Documentation versions require MongoDB to be enabled",
+		Status:  http.StatusServiceUnavailable,
+	})
+	return false
+}
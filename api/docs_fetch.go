@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dtomacheski/extract-data-go/internal/cache"
+	"github.com/dtomacheski/extract-data-go/internal/models"
+	"github.com/dtomacheski/extract-data-go/internal/registry"
+	"github.com/dtomacheski/extract-data-go/internal/source"
+	"github.com/dtomacheski/extract-data-go/internal/utils"
+)
+
+// fetchDocumentationAtRef fetches the documentation of the repository a URL points to from the
+// source of its host, at the URL's ref and restricted to its sub path when they are set. An empty
+// ref resolves to the default branch of the repository. Results are cached per host, owner, repo,
+// ref and sub path.
+func (h *Handler) fetchDocumentationAtRef(ctx context.Context, src source.DocSource, location *utils.RepositoryURL) ([]models.Documentation, error) {
+	owner, repo, ref, subPath := location.Owner, location.Repo, location.Ref, location.SubPath
+
+	var defaultBranch string
+	if ref == "" {
+		repoDetails, err := src.GetRepository(ctx, owner, repo)
+		if err != nil {
+			return nil, err
+		}
+		defaultBranch = repoDetails.DefaultBranch
+	}
+
+	cacheRef := ref
+	if cacheRef == "" {
+		cacheRef = defaultBranch
+	}
+	cacheKey := h.KeyBuilder.RepositoryDocumentationPathKey(location.Host, owner, repo, cacheRef, subPath)
+
+	if h.Cache != nil && h.Cache.IsEnabled() {
+		var documentation []models.Documentation
+		cacheErr := h.Cache.Get(ctx, cacheKey, &documentation)
+		if cacheErr == nil {
+			h.Logger.Printf("Cache hit for documentation: %s/%s/%s@%s (path '%s')", location.Host, owner, repo, cacheRef, subPath)
+			return documentation, nil
+		} else if cacheErr != cache.ErrCacheMiss {
+			h.Logger.Printf("Cache error for documentation: %v", cacheErr)
+		}
+	}
+
+	documentation, err := src.GetRepositoryDocumentationInPath(ctx, owner, repo, defaultBranch, ref, subPath, h.WorkerPoolSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch documentation for %s/%s@%s: %w", owner, repo, cacheRef, err)
+	}
+
+	if h.Cache != nil && h.Cache.IsEnabled() && len(documentation) > 0 {
+		if cacheErr := h.Cache.Set(ctx, cacheKey, documentation); cacheErr != nil {
+			h.Logger.Printf("Failed to cache documentation: %v", cacheErr)
+		}
+	}
+
+	return documentation, nil
+}
+
+// resolveVersion resolves a version constraint such as ^3 or latest-stable to a tag of the repository
+func (h *Handler) resolveVersion(ctx context.Context, tags registry.TagLister, owner, repo, version string) (string, error) {
+	tag, err := registry.NewRegistry(tags).Resolve(ctx, owner, repo, version)
+	if err != nil {
+		return "", err
+	}
+
+	h.Logger.Printf("Resolved version '%s' of %s/%s to tag '%s'", version, owner, repo, tag)
+	return tag, nil
+}
@@ -45,11 +45,25 @@ func (h *Handler) GetCodeSnippetsFromURL(c *gin.Context) {
 		page = 1
 	}
 
-	// Extract branch/tag if specified
-	ref := c.Query("ref")
+	// Get token budget parameter (maximum number of LLM tokens to return)
+	tokenBudget := 0
+	if tokensStr := c.Query("tokens"); tokensStr != "" {
+		tokenBudget, err = strconv.Atoi(tokensStr)
+		if err != nil || tokenBudget <= 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Invalid 'tokens' parameter: must be a positive integer",
+				Status:  http.StatusBadRequest,
+			})
+			return
+		}
+	}
+
+	// Get topic parameter to focus the snippets
+	topic := strings.TrimSpace(c.Query("topic"))
 
-	// Extract owner and repo from the URL
-	owner, repo, err := utils.ExtractOwnerAndRepo(repoURL)
+	// Extract owner, repo and optional /tree/<ref>/<path> from the URL
+	repoLocation, err := utils.ParseRepositoryURL(repoURL)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "invalid_request",
@@ -59,6 +73,21 @@ func (h *Handler) GetCodeSnippetsFromURL(c *gin.Context) {
 		return
 	}
 
+	// An explicit branch/tag takes precedence over the one in the URL
+	repoLocation.OverrideRef(c.Query("ref"))
+
+	// Select the documentation source of the URL's host
+	src, err := h.Sources.ForHost(repoLocation.Host)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+			Status:  http.StatusBadRequest,
+		})
+		return
+	}
+	owner, repo := repoLocation.Owner, repoLocation.Repo
+
 	// Set up cancellation context
 	ctx, cancel := context.WithCancel(c.Request.Context())
 	defer cancel()
@@ -69,61 +98,36 @@ func (h *Handler) GetCodeSnippetsFromURL(c *gin.Context) {
 		cancel()
 	}()
 
-	// Get documentation
-	var documentation []models.Documentation
-	if ref != "" {
-		// If ref is provided, we would need to extend our client to support custom refs
-		// For now this is a placeholder for future implementation
-		c.JSON(http.StatusNotImplemented, models.ErrorResponse{
-			Error:   "not_implemented",
-			Message: "Custom branch/tag reference is not yet supported",
-			Status:  http.StatusNotImplemented,
-		})
-		return
-	} else {
-		var err error
-		// Get repository details to find the default branch
-		var defaultBranchToUse string
-		repoDetails, detailsErr := h.GitHubClient.GetRepository(ctx, owner, repo) // Assuming owner & repo are defined in this scope
-		if detailsErr != nil {
-			h.Logger.Printf("Error fetching repository details for %s/%s in enhanced_url_handler: %v", owner, repo, detailsErr)
-			// Handle error appropriately, perhaps return an error response
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed_to_get_repo_details", Message: detailsErr.Error()})
+	// A version constraint such as ^3 resolves to the highest matching tag
+	if version := strings.TrimSpace(c.Query("version")); version != "" {
+		if c.Query("ref") != "" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Use either 'ref' or 'version', not both",
+				Status:  http.StatusBadRequest,
+			})
 			return
-		} else if repoDetails != nil {
-			defaultBranchToUse = repoDetails.DefaultBranch
 		}
 
-		documentation, err = h.GitHubClient.GetRepositoryDocumentation(ctx, owner, repo, defaultBranchToUse, "", h.WorkerPoolSize)
+		tag, err := h.resolveVersion(ctx, src, owner, repo, version)
 		if err != nil {
-			statusCode := http.StatusInternalServerError
-			if err.Error() == "repository not found" {
-				statusCode = http.StatusNotFound
-			} else if err.Error() == "unauthorized: invalid GitHub token" {
-				statusCode = http.StatusUnauthorized
-			} else if err.Error() == "rate limit exceeded or access denied" {
-				statusCode = http.StatusTooManyRequests
-			} else if err.Error() == "no documentation found for repository" {
-				statusCode = http.StatusNotFound
-			}
-
-			c.JSON(statusCode, models.ErrorResponse{
-				Error:   "github_api_error",
-				Message: err.Error(),
-				Status:  statusCode,
-			})
+			respondWithError(c, "version_resolution_failed", err)
 			return
 		}
+		repoLocation.OverrideRef(tag)
+	}
+
+	// Get documentation
+	documentation, err := h.fetchDocumentationAtRef(ctx, src, repoLocation)
+	if err != nil {
+		respondWithError(c, "github_api_error", err)
+		return
 	}
 
 	// Get repository info to build URLs
-	repoInfo, err := h.GitHubClient.GetRepository(ctx, owner, repo)
+	repoInfo, err := src.GetRepository(ctx, owner, repo)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:   "github_api_error",
-			Message: "Failed to get repository information: " + err.Error(),
-			Status:  http.StatusInternalServerError,
-		})
+		respondWithError(c, "github_api_error", fmt.Errorf("failed to get repository information: %w", err))
 		return
 	}
 
@@ -133,6 +137,16 @@ func (h *Handler) GetCodeSnippetsFromURL(c *gin.Context) {
 	// Process the documentation to extract code snippets
 	processedResponse := docProcessor.ExtractSnippets(documentation, repoInfo.FullName, repoInfo.HTMLURL)
 
+	// Rank the snippets by relevance to the requested topic, dropping unrelated ones
+	if topic != "" {
+		processor.NewSnippetRanker().ApplyTopic(&processedResponse, topic)
+	}
+
+	// Fit the snippets into the token budget without splitting any of them
+	if tokenBudget > 0 {
+		processor.NewTextFormatter().ApplyTokenBudget(&processedResponse, tokenBudget)
+	}
+
 	// Format response in enhanced style
 	if c.Query("format") == "enhanced" {
 		formattedString, err := formatEnhancedStyle(processedResponse)
@@ -182,16 +196,38 @@ func formatEnhancedStyle(response models.DocumentationResponse) (string, error)
 	var sb strings.Builder
 
 	sb.WriteString("Repository: " + response.RepositoryName + "\n")
+	if response.CommitSHA != "" {
+		sb.WriteString("Revision: " + response.Ref + " (commit " + response.CommitSHA + ")\n")
+	}
+	if !response.ListingComplete {
+		sb.WriteString("Listing: incomplete, the repository tree was too large to list entirely\n")
+	}
 	sb.WriteString("Total Files: " + fmt.Sprintf("%d", response.TotalFiles) + "\n")
-	sb.WriteString("Total Snippets: " + fmt.Sprintf("%d", response.TotalSnippets) + "\n\n")
+	sb.WriteString("Total Snippets: " + fmt.Sprintf("%d", response.TotalSnippets) + "\n")
+	if response.TokenBudget > 0 {
+		sb.WriteString(fmt.Sprintf("Tokens: %d of %d (truncated: %t)\n", response.TokensUsed, response.TokenBudget, response.Truncated))
+	}
+	sb.WriteString("\n")
 
 	separator := "----------------------------------------\n\n"
 
 	for i, snippet := range response.Snippets {
 		sb.WriteString("TITLE: " + snippet.Title + "\n")
+		if snippet.Breadcrumb != "" {
+			sb.WriteString("BREADCRUMB: " + snippet.Breadcrumb + "\n")
+		}
 		sb.WriteString("DESCRIPTION: " + snippet.Description + "\n")
 		sb.WriteString("SOURCE: " + snippet.Source + "\n")
+		if snippet.StartLine > 0 {
+			sb.WriteString("LINES: " + processor.FormatLineRange(snippet.StartLine, snippet.EndLine) + "\n")
+		}
+		if snippet.URL != "" && snippet.URL != snippet.Source {
+			sb.WriteString("URL: " + snippet.URL + "\n")
+		}
 		sb.WriteString("LANGUAGE: " + snippet.Language + "\n")
+		if snippet.Filename != "" {
+			sb.WriteString("FILENAME: " + snippet.Filename + "\n")
+		}
 		sb.WriteString("CODE:\n```\n" + snippet.Code + "\n```\n")
 		
 		// Add separator only if it's not the last snippet
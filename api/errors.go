@@ -0,0 +1,64 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dtomacheski/extract-data-go/internal/database"
+	"github.com/dtomacheski/extract-data-go/internal/github"
+	"github.com/dtomacheski/extract-data-go/internal/indexer"
+	"github.com/dtomacheski/extract-data-go/internal/jobs"
+	"github.com/dtomacheski/extract-data-go/internal/local"
+	"github.com/dtomacheski/extract-data-go/internal/models"
+	"github.com/dtomacheski/extract-data-go/internal/registry"
+	"github.com/dtomacheski/extract-data-go/internal/scheduler"
+	"github.com/dtomacheski/extract-data-go/internal/source"
+	"github.com/dtomacheski/extract-data-go/internal/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+// getStatusCodeFromError returns the HTTP status code of an error
+func getStatusCodeFromError(err error) int {
+	switch {
+	case errors.Is(err, registry.ErrInvalidConstraint), errors.Is(err, source.ErrUnsupportedHost),
+		errors.Is(err, scheduler.ErrInvalidInterval), errors.Is(err, scheduler.ErrInvalidRepository),
+		errors.Is(err, webhook.ErrMalformedPayload):
+		return http.StatusBadRequest
+	case errors.Is(err, github.ErrNotFound), errors.Is(err, github.ErrNoDocs), errors.Is(err, registry.ErrNoMatchingVersion),
+		errors.Is(err, jobs.ErrJobNotFound), errors.Is(err, database.ErrTrackedRepoNotFound),
+		errors.Is(err, database.ErrDocVersionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, database.ErrTrackedRepoExists):
+		return http.StatusConflict
+	case errors.Is(err, github.ErrUnauthorized), errors.Is(err, webhook.ErrInvalidSignature):
+		return http.StatusUnauthorized
+	case errors.Is(err, github.ErrForbidden), errors.Is(err, local.ErrOutsideRoot):
+		return http.StatusForbidden
+	case errors.Is(err, github.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, indexer.ErrIndexUnavailable), errors.Is(err, jobs.ErrQueueFull):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondWithError writes the error response of err, with a Retry-After header when a
+// rate limit says how long to wait
+func respondWithError(c *gin.Context, errorType string, err error) {
+	statusCode := getStatusCodeFromError(err)
+
+	var rateErr *github.RateLimitError
+	if errors.As(err, &rateErr) {
+		if seconds, ok := rateErr.RetryAfterSeconds(); ok {
+			c.Header("Retry-After", strconv.Itoa(seconds))
+		}
+	}
+
+	c.JSON(statusCode, models.ErrorResponse{
+		Error:   errorType,
+		Message: err.Error(),
+		Status:  statusCode,
+	})
+}
@@ -12,8 +12,14 @@ import (
 	"github.com/dtomacheski/extract-data-go/internal/auth"
 	"github.com/dtomacheski/extract-data-go/internal/cache"
 	"github.com/dtomacheski/extract-data-go/internal/github"
+	"github.com/dtomacheski/extract-data-go/internal/indexer"
+	"github.com/dtomacheski/extract-data-go/internal/jobs"
+	"github.com/dtomacheski/extract-data-go/internal/mcp"
 	"github.com/dtomacheski/extract-data-go/internal/models"
 	"github.com/dtomacheski/extract-data-go/internal/repository"
+	"github.com/dtomacheski/extract-data-go/internal/scheduler"
+	"github.com/dtomacheski/extract-data-go/internal/source"
+	"github.com/dtomacheski/extract-data-go/internal/webhook"
 	"github.com/gin-gonic/gin"
 )
 
@@ -26,6 +32,24 @@ type Handler struct {
 	Cache              cache.Cache
 	KeyBuilder         *cache.KeyBuilder
 	MinDaysBetweenRefreshes int // Minimum days required between documentation refreshes
+
+	// Sources selects the documentation source of repository URLs by host
+	Sources            *source.Providers
+
+	// Indexer refreshes cached documentation incrementally by commit
+	Indexer            *indexer.Indexer
+
+	// Jobs runs indexing jobs in the background (nil disables the index job endpoints)
+	Jobs               *jobs.Manager
+
+	// Scheduler refreshes tracked repositories (nil disables the tracked repository endpoints)
+	Scheduler          *scheduler.Scheduler
+
+	// Webhooks handles GitHub webhook deliveries (nil disables /webhooks/github)
+	Webhooks           *webhook.Receiver
+
+	// MCP transport mounted under /mcp (nil disables the MCP endpoints)
+	MCPTransport       *mcp.HTTPTransport
 	
 	// Authentication services
 	userStore          *auth.UserStore
@@ -54,6 +78,8 @@ func NewHandler(client *github.Client, docRepo *repository.DocumentRepository, c
 		Cache:              cacheClient,
 		KeyBuilder:         keyBuilder,
 		MinDaysBetweenRefreshes: 3, // Default: minimum 3 days between refreshes
+		Sources:            source.NewProviders(client),
+		Indexer:            indexer.NewIndexer(client, cacheClient, keyBuilder, workerPoolSize, logger),
 		userStore:          userStore,  // Use injected userStore
 		jwtService:         jwtService, // Use injected jwtService
 	}
@@ -152,20 +178,7 @@ func (h *Handler) GetRepository(c *gin.Context) {
 		// Get basic repository information
 		repository, err = h.GitHubClient.GetRepository(c.Request.Context(), owner, repo)
 		if err != nil {
-			statusCode := http.StatusInternalServerError
-			if err.Error() == "repository not found" {
-				statusCode = http.StatusNotFound
-			} else if err.Error() == "unauthorized: invalid GitHub token" {
-				statusCode = http.StatusUnauthorized
-			} else if err.Error() == "rate limit exceeded or access denied" {
-				statusCode = http.StatusTooManyRequests
-			}
-
-			c.JSON(statusCode, models.ErrorResponse{
-				Error:   "github_api_error",
-				Message: err.Error(),
-				Status:  statusCode,
-			})
+			respondWithError(c, "github_api_error", err)
 			return
 		}
 
@@ -345,6 +358,36 @@ func (h *Handler) GetRepositoryDocumentation(c *gin.Context) {
 		cancel()
 	}()
 
+	// A version constraint such as ^3 resolves to the highest matching tag
+	if version := strings.TrimSpace(c.Query("version")); version != "" {
+		if tag != "" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Use either 'tag' or 'version', not both",
+				Status:  http.StatusBadRequest,
+			})
+			return
+		}
+
+		resolvedTag, err := h.resolveVersion(ctx, h.GitHubClient, owner, repo, version)
+		if err != nil {
+			respondWithError(c, "version_resolution_failed", err)
+			return
+		}
+		tag = resolvedTag
+	}
+
+	// A forced refresh re-indexes the repository first, fetching only the documents
+	// changed since the last indexed commit into the cache read below
+	if forceRefresh && h.Indexer != nil && h.Cache != nil && h.Cache.IsEnabled() {
+		summary, err := h.Indexer.Reindex(ctx, owner, repo, tag)
+		if err != nil {
+			h.Logger.Printf("Incremental re-indexing of %s/%s failed, falling back to the cache: %v", owner, repo, err)
+		} else {
+			h.Logger.Printf("Re-indexed %s/%s at %s: %d added, %d changed, %d removed", owner, repo, summary.CommitSHA, len(summary.Added), len(summary.Changed), len(summary.Removed))
+		}
+	}
+
 	// IMPLEMENTATION OF TWO-LAYER CACHING STRATEGY
 	// 1. First, check for metadata index in cache
 	var metadataIndex models.RepositoryDocumentationIndex
@@ -371,7 +414,7 @@ func (h *Handler) GetRepositoryDocumentation(c *gin.Context) {
 			// For each document in the metadata index, try to fetch it from content cache
 			for _, docMeta := range metadataIndex.Documents {
 				// Generate content cache key
-				contentCacheKey := h.KeyBuilder.DocumentContentKey(owner, repo, tag, docMeta.SHA)
+				contentCacheKey := h.KeyBuilder.DocumentContentKey(owner, repo, tag, docMeta.Path, docMeta.SHA)
 				
 				// Try to get document content from cache
 				var docContent models.Documentation
@@ -410,11 +453,7 @@ func (h *Handler) GetRepositoryDocumentation(c *gin.Context) {
 					repoInfo, repoErr := h.GitHubClient.GetRepository(ctx, owner, repo)
 					if repoErr != nil {
 						h.Logger.Printf("Failed to fetch repository info for %s/%s to get default branch: %v", owner, repo, repoErr)
-						c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-							Error:   "github_error",
-							Message: fmt.Sprintf("Failed to retrieve repository details for default branch: %s", repoErr.Error()),
-							Status:  http.StatusInternalServerError,
-						})
+						respondWithError(c, "github_error", fmt.Errorf("failed to retrieve repository details for default branch: %w", repoErr))
 						return
 					}
 					defaultBranchForGHClient = repoInfo.DefaultBranch
@@ -435,12 +474,7 @@ func (h *Handler) GetRepositoryDocumentation(c *gin.Context) {
 					// Continue with partial results if we have some
 					if len(documentationItems) == 0 {
 						// No documents at all, return error
-						statusCode := getStatusCodeFromError(err)
-						c.JSON(statusCode, models.ErrorResponse{
-							Error:   "github_api_error",
-							Message: err.Error(),
-							Status:  statusCode,
-						})
+						respondWithError(c, "github_api_error", err)
 						return
 					}
 				} else {
@@ -452,7 +486,7 @@ func (h *Handler) GetRepositoryDocumentation(c *gin.Context) {
 								
 								// Cache this document content
 								if h.Cache.IsEnabled() {
-									contentCacheKey := h.KeyBuilder.DocumentContentKey(owner, repo, tag, doc.SHA)
+									contentCacheKey := h.KeyBuilder.DocumentContentKey(owner, repo, tag, doc.Path, doc.SHA)
 									h.Cache.Set(ctx, contentCacheKey, doc)
 								}
 								break
@@ -478,11 +512,7 @@ func (h *Handler) GetRepositoryDocumentation(c *gin.Context) {
 			repoInfo, repoErr := h.GitHubClient.GetRepository(ctx, owner, repo)
 			if repoErr != nil {
 				h.Logger.Printf("Failed to fetch repository info for %s/%s to get default branch: %v", owner, repo, repoErr)
-				c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-					Error:   "github_error",
-					Message: fmt.Sprintf("Failed to retrieve repository details for default branch: %s", repoErr.Error()),
-					Status:  http.StatusInternalServerError,
-				})
+				respondWithError(c, "github_error", fmt.Errorf("failed to retrieve repository details for default branch: %w", repoErr))
 				return
 			}
 			defaultBranchForGHClient = repoInfo.DefaultBranch
@@ -500,12 +530,7 @@ func (h *Handler) GetRepositoryDocumentation(c *gin.Context) {
 		documentationItems, err = h.GitHubClient.GetRepositoryDocumentation(ctx, owner, repo, defaultBranchForGHClient, tag, h.WorkerPoolSize)
 		if err != nil {
 			h.Logger.Printf("Error fetching repository documentation for %s/%s (ref: %s): %v", owner, repo, tag, err)
-			statusCode := getStatusCodeFromError(err)
-			c.JSON(statusCode, models.ErrorResponse{
-				Error:   "github_api_error",
-				Message: err.Error(),
-				Status:  statusCode,
-			})
+			respondWithError(c, "github_api_error", err)
 			return
 		}
 		
@@ -514,10 +539,12 @@ func (h *Handler) GetRepositoryDocumentation(c *gin.Context) {
 			h.Logger.Printf("Caching metadata and content for %d documents", len(documentationItems))
 			
 			// Create metadata index
+			_, indexCommitSHA := models.RevisionOf(documentationItems)
 			metadataIndex = models.RepositoryDocumentationIndex{
 				RepositoryOwner: owner,
 				RepositoryName:  repo,
 				RepositoryRef:   tag,
+				CommitSHA:       indexCommitSHA,
 				DocumentCount:   len(documentationItems),
 				CreatedAt:       time.Now(),
 				Documents:       make([]models.DocumentMetadata, 0, len(documentationItems)),
@@ -534,7 +561,7 @@ func (h *Handler) GetRepositoryDocumentation(c *gin.Context) {
 				})
 				
 				// Cache document content
-				contentCacheKey := h.KeyBuilder.DocumentContentKey(owner, repo, tag, doc.SHA)
+				contentCacheKey := h.KeyBuilder.DocumentContentKey(owner, repo, tag, doc.Path, doc.SHA)
 				if cacheErr := h.Cache.Set(ctx, contentCacheKey, doc); cacheErr != nil {
 					h.Logger.Printf("Failed to cache document content for %s: %v", doc.Path, cacheErr)
 				}
@@ -559,13 +586,21 @@ func (h *Handler) GetRepositoryDocumentation(c *gin.Context) {
 		}
 	}
 
+	// Report the revision the documentation was read at
+	resolvedRef, commitSHA := models.RevisionOf(documentationItems)
+	if tag != "" {
+		resolvedRef = tag
+	}
+
 	// Construct the response using RepositoryDocsResponse
 	response := models.RepositoryDocsResponse{
 		Status:             http.StatusOK,
 		Message:            fmt.Sprintf("Successfully retrieved %d documentation files.", len(documentationItems)),
 		RepositoryOwner:    owner,
 		RepositoryName:     repo,
-		RepositoryRef:      tag,
+		RepositoryRef:      resolvedRef,
+		CommitSHA:          commitSHA,
+		ListingComplete:    models.ListingComplete(documentationItems),
 		ProcessedFilesCount: len(documentationItems),
 		DocumentationItems: documentationItems,
 	}
@@ -580,21 +615,6 @@ func (h *Handler) GetRepositoryDocumentation(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// Helper function to get HTTP status code from error
-func getStatusCodeFromError(err error) int {
-	statusCode := http.StatusInternalServerError
-	if strings.Contains(err.Error(), "repository not found") {
-		statusCode = http.StatusNotFound
-	} else if strings.Contains(err.Error(), "invalid GitHub token") {
-		statusCode = http.StatusUnauthorized
-	} else if strings.Contains(err.Error(), "rate limit exceeded") {
-		statusCode = http.StatusTooManyRequests
-	} else if strings.Contains(err.Error(), "no documentation files found") || strings.Contains(err.Error(), "no documentation content could be successfully retrieved") {
-		statusCode = http.StatusNotFound
-	}
-	return statusCode
-}
-
 // SearchRepositories handles searching for repositories
 func (h *Handler) SearchRepositories(c *gin.Context) {
 	query := c.Query("q")
@@ -635,18 +655,7 @@ func (h *Handler) SearchRepositories(c *gin.Context) {
 	// Execute search
 	repositories, nextPage, err := h.GitHubClient.SearchRepositories(c.Request.Context(), query, page, perPage)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		if err.Error() == "unauthorized: invalid GitHub token" {
-			statusCode = http.StatusUnauthorized
-		} else if err.Error() == "rate limit exceeded or access denied" {
-			statusCode = http.StatusTooManyRequests
-		}
-
-		c.JSON(statusCode, models.ErrorResponse{
-			Error:   "github_api_error",
-			Message: err.Error(),
-			Status:  statusCode,
-		})
+		respondWithError(c, "github_api_error", err)
 		return
 	}
 
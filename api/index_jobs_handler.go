@@ -0,0 +1,146 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dtomacheski/extract-data-go/internal/jobs"
+	"github.com/dtomacheski/extract-data-go/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// jobEventsPollInterval is how often an event stream reads the saved state of its job,
+// which is all it gets from jobs running in another process
+const jobEventsPollInterval = 5 * time.Second
+
+// IndexJobRequest is the payload of an indexing job submission
+type IndexJobRequest struct {
+	Owner string `json:"owner" binding:"required"`
+	Repo  string `json:"repo" binding:"required"`
+	Ref   string `json:"ref"` // Optional branch or tag, the default branch when empty
+}
+
+// CreateIndexJob queues a job fetching, extracting and storing the documentation of a
+// repository in the background, and returns it with its ID
+func (h *Handler) CreateIndexJob(c *gin.Context) {
+	if !h.requireJobs(c) {
+		return
+	}
+
+	var req IndexJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request format: " + err.Error(),
+			Status:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	job, err := h.Jobs.Submit(c.Request.Context(), strings.TrimSpace(req.Owner), strings.TrimSpace(req.Repo), strings.TrimSpace(req.Ref))
+	if err != nil {
+		h.Logger.Printf("Failed to queue indexing job for %s/%s: %v", req.Owner, req.Repo, err)
+		respondWithError(c, "job_submission_failed", err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/index-jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, models.SuccessResponse{
+		Status:  http.StatusAccepted,
+		Message: "Indexing job queued",
+		Data:    job,
+	})
+}
+
+// GetIndexJob reports the state, progress and errors of an indexing job
+func (h *Handler) GetIndexJob(c *gin.Context) {
+	if !h.requireJobs(c) {
+		return
+	}
+
+	job, err := h.Jobs.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondWithError(c, "job_not_found", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status:  http.StatusOK,
+		Message: "Indexing job " + string(job.State),
+		Data:    job,
+	})
+}
+
+// StreamIndexJobEvents streams the progress of an indexing job as Server-Sent Events:
+// a "job" event with its current state, then one event per progress event (strategy
+// chosen, paths found, files fetched or failed, snippets extracted, documentation
+// stored) until a "job_finished" or "job_interrupted" event ends the stream.
+// Clients must send "Accept: text/event-stream" so that the request timeout does not apply.
+func (h *Handler) StreamIndexJobEvents(c *gin.Context) {
+	if !h.requireJobs(c) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	id := c.Param("id")
+	job, events, unsubscribe, err := h.Jobs.Subscribe(ctx, id)
+	if err != nil {
+		respondWithError(c, "job_not_found", err)
+		return
+	}
+	defer unsubscribe()
+
+	// The stream lasts as long as the job, beyond the server's WriteTimeout
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent("job", job)
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(jobEventsPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			c.SSEvent(string(event.Type), event)
+			c.Writer.Flush()
+		case <-ticker.C:
+			// Also keeps the connection alive while a stage reports nothing
+			current, err := h.Jobs.Get(ctx, id)
+			if err != nil {
+				h.Logger.Printf("Failed to read indexing job %s for its event stream: %v", id, err)
+				continue
+			}
+			if current.Finished() {
+				c.SSEvent(string(jobs.EventJobFinished), jobs.FinishedEvent(current))
+				c.Writer.Flush()
+				return
+			}
+			c.SSEvent("job", current)
+			c.Writer.Flush()
+		}
+	}
+}
+
+// requireJobs answers 503 when background jobs are not configured
+func (h *Handler) requireJobs(c *gin.Context) bool {
+	if h.Jobs != nil {
+		return true
+	}
+	c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+		Error:   "jobs_unavailable",
+		Message: "Background indexing jobs are not enabled",
+		Status:  http.StatusServiceUnavailable,
+	})
+	return false
+}
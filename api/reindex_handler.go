@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/dtomacheski/extract-data-go/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ReindexRepository brings the cached documentation of a repository up to date with the
+// latest commit of a ref, fetching only the documents that were added or changed
+func (h *Handler) ReindexRepository(c *gin.Context) {
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+	tag := c.Query("tag") // Optional tag or branch name
+
+	if owner == "" || repo == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Owner and repository name are required",
+			Status:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// A version constraint such as ^3 resolves to the highest matching tag
+	if version := strings.TrimSpace(c.Query("version")); version != "" {
+		if tag != "" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Use either 'tag' or 'version', not both",
+				Status:  http.StatusBadRequest,
+			})
+			return
+		}
+
+		resolvedTag, err := h.resolveVersion(c.Request.Context(), h.GitHubClient, owner, repo, version)
+		if err != nil {
+			respondWithError(c, "version_resolution_failed", err)
+			return
+		}
+		tag = resolvedTag
+	}
+
+	summary, err := h.Indexer.Reindex(c.Request.Context(), owner, repo, tag)
+	if err != nil {
+		h.Logger.Printf("Error re-indexing %s/%s (ref: %s): %v", owner, repo, tag, err)
+		respondWithError(c, "reindex_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status:  http.StatusOK,
+		Message: "Repository documentation re-indexed successfully",
+		Data:    summary,
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/dtomacheski/extract-data-go/internal/models"
+	"github.com/dtomacheski/extract-data-go/internal/registry"
 	"github.com/gin-gonic/gin"
 )
 
@@ -101,6 +102,7 @@ func (h *Handler) QueryRepositories(c *gin.Context) {
 				if h.DocumentRepository != nil && h.DocumentRepository.IsEnabled() {
 					enrichRepositoryWithDocumentationStatus(ctx, h, repoDetails, owner, repo)
 				}
+				enrichRepositoryWithVersions(ctx, h, repoDetails, owner, repo)
 				
 				response.Meta.FromCache = true
 				response.Data.Repository = repoDetails
@@ -113,23 +115,7 @@ func (h *Handler) QueryRepositories(c *gin.Context) {
 		// Cache miss ou cache desabilitado, busca do GitHub
 		repository, err := h.GitHubClient.GetRepository(ctx, owner, repo)
 		if err != nil {
-			statusCode := http.StatusInternalServerError
-			errorMessage := err.Error()
-			
-			if strings.Contains(errorMessage, "not found") {
-				statusCode = http.StatusNotFound
-				errorMessage = "Repositório não encontrado"
-			} else if strings.Contains(errorMessage, "unauthorized") {
-				statusCode = http.StatusUnauthorized
-			} else if strings.Contains(errorMessage, "rate limit") {
-				statusCode = http.StatusTooManyRequests
-			}
-			
-			c.JSON(statusCode, models.ErrorResponse{
-				Error:   "github_api_error",
-				Message: errorMessage,
-				Status:  statusCode,
-			})
+			respondWithError(c, "github_api_error", err)
 			return
 		}
 		
@@ -140,6 +126,7 @@ func (h *Handler) QueryRepositories(c *gin.Context) {
 		if h.DocumentRepository != nil && h.DocumentRepository.IsEnabled() {
 			enrichRepositoryWithDocumentationStatus(ctx, h, repoDetails, owner, repo)
 		}
+		enrichRepositoryWithVersions(ctx, h, repoDetails, owner, repo)
 		
 		// Salvar no cache para consultas futuras
 		if h.Cache != nil && h.Cache.IsEnabled() && cacheKey != "" {
@@ -160,20 +147,7 @@ func (h *Handler) QueryRepositories(c *gin.Context) {
 	// Executar a busca
 	repositories, nextPage, err := h.GitHubClient.SearchRepositories(ctx, query, page, perPage)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		errorMessage := err.Error()
-		
-		if strings.Contains(errorMessage, "unauthorized") {
-			statusCode = http.StatusUnauthorized
-		} else if strings.Contains(errorMessage, "rate limit") {
-			statusCode = http.StatusTooManyRequests
-		}
-		
-		c.JSON(statusCode, models.ErrorResponse{
-			Error:   "github_api_error",
-			Message: errorMessage,
-			Status:  statusCode,
-		})
+		respondWithError(c, "github_api_error", err)
 		return
 	}
 	
@@ -327,11 +301,6 @@ func enrichRepositoryWithDocumentationStatus(ctx context.Context, h *Handler, re
 			
 			// Construir URL de documentação
 			repoDetails.DocsURL = "/api/v1/repos/" + owner + "/" + repo + "/docs"
-			
-			// Nota: GetAvailableVersions não está implementado ainda no DocumentRepository
-			// Implementaremos isso posteriormente
-			// Por enquanto, apenas deixamos AvailableVersions vazio
-			// Futuro: repoDetails.DocumentationStatus.AvailableVersions = obterVersoesDisponiveis()
 		} else {
 			// Sem documentação ainda
 			repoDetails.DocumentationStatus = models.DocumentationStatus{
@@ -340,3 +309,34 @@ func enrichRepositoryWithDocumentationStatus(ctx context.Context, h *Handler, re
 		}
 	}
 }
+
+// maxAvailableVersions limita o número de versões retornadas por repositório
+const maxAvailableVersions = 50
+
+// enrichRepositoryWithVersions preenche as versões disponíveis a partir das tags semver do repositório.
+// Listing the tags takes one GitHub request per 100 tags, so the versions are cached like the repository.
+func enrichRepositoryWithVersions(ctx context.Context, h *Handler, repoDetails *models.RepositoryDetails, owner, repo string) {
+	var cacheKey string
+	if h.Cache != nil && h.Cache.IsEnabled() {
+		cacheKey = h.KeyBuilder.RepositoryVersionsKey(owner, repo)
+		var cachedVersions []string
+		if err := h.Cache.Get(ctx, cacheKey, &cachedVersions); err == nil {
+			repoDetails.DocumentationStatus.AvailableVersions = cachedVersions
+			return
+		}
+	}
+
+	versions, err := registry.NewRegistry(h.GitHubClient).Versions(ctx, owner, repo)
+	if err != nil {
+		h.Logger.Printf("Failed to list versions for %s/%s: %v", owner, repo, err)
+		return
+	}
+
+	available := registry.Tags(versions, maxAvailableVersions)
+	repoDetails.DocumentationStatus.AvailableVersions = available
+	if cacheKey != "" {
+		if err := h.Cache.Set(ctx, cacheKey, available); err != nil {
+			h.Logger.Printf("Failed to cache versions for %s/%s: %v", owner, repo, err)
+		}
+	}
+}
@@ -2,9 +2,11 @@ package api
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/dtomacheski/extract-data-go/internal/auth"
+	"github.com/dtomacheski/extract-data-go/internal/mcp"
 	"github.com/gin-gonic/gin"
 )
 
@@ -33,6 +35,27 @@ func SetupRouter(handler *Handler) *gin.Engine {
 		authRoutes.POST("/refresh", handler.RefreshTokenHandler)
 	}
 
+	// GitHub webhooks, authenticated by their signature
+	if handler.Webhooks != nil {
+		router.POST("/webhooks/github", handler.GitHubWebhook)
+	}
+
+	// MCP endpoints (Streamable HTTP with legacy HTTP+SSE fallback)
+	// Protected by JWT authentication like the documentation endpoints
+	if handler.MCPTransport != nil {
+		mcpRoutes := router.Group("/mcp")
+		mcpRoutes.Use(auth.JWTMiddleware(handler.jwtService), mcpSubjectMiddleware())
+		{
+			mcpRoutes.POST("", gin.WrapH(handler.MCPTransport))
+			mcpRoutes.GET("", gin.WrapH(handler.MCPTransport))
+			mcpRoutes.DELETE("", gin.WrapH(handler.MCPTransport))
+
+			// Legacy HTTP+SSE transport
+			mcpRoutes.GET("/sse", gin.WrapF(handler.MCPTransport.ServeSSE))
+			mcpRoutes.POST("/messages", gin.WrapF(handler.MCPTransport.ServeMessages))
+		}
+	}
+
 	// API routes
 	v1 := router.Group("/api/v1")
 	{
@@ -60,6 +83,34 @@ func SetupRouter(handler *Handler) *gin.Engine {
 			// This was previously v1.GET("/repos/:owner/:repo/docs", handler.GetRepositoryDocumentation)
 			// Moving it here to be under the authenticated /docs group
 			docs.GET("/repos/:owner/:repo", handler.GetRepositoryDocumentation)
+
+			// Incremental re-indexing of a repository's cached documentation
+			docs.POST("/repos/:owner/:repo/reindex", handler.ReindexRepository)
+
+			// Stored versions of a repository's processed documentation
+			docs.GET("/repos/:owner/:repo/current", handler.GetCurrentDocumentation)
+			docs.GET("/repos/:owner/:repo/versions", handler.ListDocumentationVersions)
+			docs.GET("/repos/:owner/:repo/versions/:sha", handler.GetDocumentationVersion)
+		}
+		
+		// Asynchronous indexing jobs (protected)
+		indexJobs := v1.Group("/index-jobs")
+		indexJobs.Use(auth.JWTMiddleware(handler.jwtService))
+		{
+			indexJobs.POST("", handler.CreateIndexJob)
+			indexJobs.GET("/:id", handler.GetIndexJob)
+			indexJobs.GET("/:id/events", handler.StreamIndexJobEvents)
+		}
+		
+		// Tracked repositories refreshed by the scheduler (admin only)
+		trackedRepos := v1.Group("/admin/tracked-repos")
+		trackedRepos.Use(auth.JWTMiddleware(handler.jwtService), auth.RoleMiddleware("admin"))
+		{
+			trackedRepos.GET("", handler.ListTrackedRepos)
+			trackedRepos.POST("", handler.CreateTrackedRepo)
+			trackedRepos.GET("/:id", handler.GetTrackedRepo)
+			trackedRepos.PUT("/:id", handler.UpdateTrackedRepo)
+			trackedRepos.DELETE("/:id", handler.DeleteTrackedRepo)
 		}
 		
 		// Legacy endpoints (for backward compatibility)
@@ -81,8 +132,9 @@ func SetupRouter(handler *Handler) *gin.Engine {
 func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, Mcp-Session-Id, Mcp-Protocol-Version, Last-Event-ID")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "Mcp-Session-Id")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -93,11 +145,21 @@ func corsMiddleware() gin.HandlerFunc {
 	}
 }
 
+// mcpSubjectMiddleware passes the authenticated user to the MCP transport, which
+// ties each session to the user that opened it
+func mcpSubjectMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(mcp.WithSubject(c.Request.Context(), auth.GetCurrentUser(c)))
+		c.Next()
+	}
+}
+
 // timeoutMiddleware adds a timeout to the request context
 func timeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Skip for streaming requests
-		if c.GetHeader("Accept") == "text/event-stream" {
+		// Skip for requests accepting a stream, such as MCP clients
+		// (Accept: application/json, text/event-stream)
+		if strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
 			c.Next()
 			return
 		}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dtomacheski/extract-data-go/config"
+	"github.com/dtomacheski/extract-data-go/internal/auth"
+	"github.com/dtomacheski/extract-data-go/internal/mcp"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mcpAccept is the Accept header sent by Streamable HTTP clients
+const mcpAccept = "application/json, text/event-stream"
+
+// newMCPTestRouter mounts an MCP server whose "deadline" tool reports whether its
+// request context has a deadline, and returns an access token for each user
+func newMCPTestRouter(t *testing.T, users ...string) (*httptest.Server, map[string]string) {
+	gin.SetMode(gin.TestMode)
+
+	jwtService := auth.NewJWTService(&config.Config{JWTSecret: "test-secret", JWTAccessDuration: time.Hour, JWTIssuer: "test"})
+	tokens := make(map[string]string, len(users))
+	for _, user := range users {
+		token, err := jwtService.GenerateAccessToken(&auth.User{ID: user, Username: user, Role: "user"})
+		require.NoError(t, err)
+		tokens[user] = token
+	}
+
+	server := mcp.NewServer("test-server", "0.0.1", nil)
+	server.RegisterTool(mcp.Tool{
+		Name:        "deadline",
+		Description: "Reports whether the request has a deadline",
+		InputSchema: json.RawMessage(`{"type":"object"}`),
+	}, func(ctx context.Context, arguments json.RawMessage) (*mcp.CallToolResult, error) {
+		_, ok := ctx.Deadline()
+		return mcp.TextResult(fmt.Sprint(ok)), nil
+	})
+
+	handler := &Handler{jwtService: jwtService, MCPTransport: mcp.NewHTTPTransport(server, "/mcp/messages", nil)}
+	srv := httptest.NewServer(SetupRouter(handler))
+	t.Cleanup(srv.Close)
+	return srv, tokens
+}
+
+// postMCP posts a JSON-RPC message to /mcp with the Accept header of MCP clients
+func postMCP(t *testing.T, url, token, sessionID, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url+"/mcp", strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", mcpAccept)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if sessionID != "" {
+		req.Header.Set(mcp.SessionHeader, sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+// TestRouter_MCP tests that MCP requests are not cut by the request timeout and that
+// sessions stay with the user that opened them
+func TestRouter_MCP(t *testing.T) {
+	srv, tokens := newMCPTestRouter(t, "alice", "bob")
+
+	resp := postMCP(t, srv.URL, tokens["alice"], "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+	sessionID := resp.Header.Get(mcp.SessionHeader)
+	require.NotEmpty(t, sessionID)
+
+	// Tool calls stream their response without the request timeout
+	resp = postMCP(t, srv.URL, tokens["alice"], sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"deadline","arguments":{}}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, string(body), `"text":"false"`)
+
+	// Another user cannot reach the session
+	resp = postMCP(t, srv.URL, tokens["bob"], sessionID, `{"jsonrpc":"2.0","id":3,"method":"ping"}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dtomacheski/extract-data-go/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// TrackedRepoRequest is the payload tracking a repository for scheduled refreshes
+type TrackedRepoRequest struct {
+	Owner           string `json:"owner" binding:"required"`
+	Repo            string `json:"repo" binding:"required"`
+	Ref             string `json:"ref"`              // Optional branch or tag, the default branch when empty
+	IntervalMinutes int    `json:"interval_minutes"` // Optional, 24 hours when 0
+}
+
+// TrackedRepoUpdateRequest is the payload changing a tracked repository
+type TrackedRepoUpdateRequest struct {
+	Ref             string `json:"ref"`
+	IntervalMinutes int    `json:"interval_minutes"`
+}
+
+// ListTrackedRepos lists the repositories refreshed by the scheduler
+func (h *Handler) ListTrackedRepos(c *gin.Context) {
+	if !h.requireScheduler(c) {
+		return
+	}
+
+	repos, err := h.Scheduler.List(c.Request.Context())
+	if err != nil {
+		h.Logger.Printf("Failed to list tracked repositories: %v", err)
+		respondWithError(c, "tracked_repos_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status:  http.StatusOK,
+		Message: "Tracked repositories retrieved successfully",
+		Data:    repos,
+	})
+}
+
+// CreateTrackedRepo starts refreshing a repository on a schedule; its first refresh is due right away
+func (h *Handler) CreateTrackedRepo(c *gin.Context) {
+	if !h.requireScheduler(c) {
+		return
+	}
+
+	var req TrackedRepoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request format: " + err.Error(),
+			Status:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	tracked, err := h.Scheduler.Track(c.Request.Context(), req.Owner, req.Repo, req.Ref, time.Duration(req.IntervalMinutes)*time.Minute)
+	if err != nil {
+		respondWithError(c, "tracked_repo_creation_failed", err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/admin/tracked-repos/"+tracked.ID.Hex())
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Status:  http.StatusCreated,
+		Message: "Repository tracked",
+		Data:    tracked,
+	})
+}
+
+// GetTrackedRepo returns a tracked repository with the outcome of its last refresh
+func (h *Handler) GetTrackedRepo(c *gin.Context) {
+	if !h.requireScheduler(c) {
+		return
+	}
+
+	tracked, err := h.Scheduler.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondWithError(c, "tracked_repo_not_found", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status:  http.StatusOK,
+		Message: "Tracked repository retrieved successfully",
+		Data:    tracked,
+	})
+}
+
+// UpdateTrackedRepo changes the ref and refresh interval of a tracked repository
+func (h *Handler) UpdateTrackedRepo(c *gin.Context) {
+	if !h.requireScheduler(c) {
+		return
+	}
+
+	var req TrackedRepoUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid request format: " + err.Error(),
+			Status:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	tracked, err := h.Scheduler.Update(c.Request.Context(), c.Param("id"), req.Ref, time.Duration(req.IntervalMinutes)*time.Minute)
+	if err != nil {
+		respondWithError(c, "tracked_repo_update_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status:  http.StatusOK,
+		Message: "Tracked repository updated",
+		Data:    tracked,
+	})
+}
+
+// DeleteTrackedRepo stops refreshing a repository; its stored documentation is kept
+func (h *Handler) DeleteTrackedRepo(c *gin.Context) {
+	if !h.requireScheduler(c) {
+		return
+	}
+
+	if err := h.Scheduler.Untrack(c.Request.Context(), c.Param("id")); err != nil {
+		respondWithError(c, "tracked_repo_deletion_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Status:  http.StatusOK,
+		Message: "Repository no longer tracked",
+	})
+}
+
+// requireScheduler answers 503 when scheduled refreshes are not configured
+func (h *Handler) requireScheduler(c *gin.Context) bool {
+	if h.Scheduler != nil {
+		return true
+	}
+	c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+		Error:   "scheduler_unavailable",
+		Message: "Scheduled refreshes require MongoDB to be enabled",
+		Status:  http.StatusServiceUnavailable,
+	})
+	return false
+}
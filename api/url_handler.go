@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/dtomacheski/extract-data-go/internal/models"
 	"github.com/dtomacheski/extract-data-go/internal/utils"
@@ -22,11 +23,8 @@ func (h *Handler) GetRawDocsFromURL(c *gin.Context) {
 		return
 	}
 
-	// Extract branch/tag if specified
-	ref := c.Query("ref")
-
-	// Extract owner and repo from the URL
-	owner, repo, err := utils.ExtractOwnerAndRepo(repoURL)
+	// Extract owner, repo and optional /tree/<ref>/<path> from the URL
+	repoLocation, err := utils.ParseRepositoryURL(repoURL)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Error:   "invalid_request",
@@ -36,6 +34,20 @@ func (h *Handler) GetRawDocsFromURL(c *gin.Context) {
 		return
 	}
 
+	// An explicit branch/tag takes precedence over the one in the URL
+	repoLocation.OverrideRef(c.Query("ref"))
+
+	// Select the documentation source of the URL's host
+	src, err := h.Sources.ForHost(repoLocation.Host)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: err.Error(),
+			Status:  http.StatusBadRequest,
+		})
+		return
+	}
+
 	// Set up cancellation context
 	ctx, cancel := context.WithCancel(c.Request.Context())
 	defer cancel()
@@ -46,39 +58,30 @@ func (h *Handler) GetRawDocsFromURL(c *gin.Context) {
 		cancel()
 	}()
 
-	// Get documentation
-	var documentation []models.Documentation
-	if ref != "" {
-		// If ref is provided, we would need to extend our client to support custom refs
-		// For now this is a placeholder for future implementation
-		c.JSON(http.StatusNotImplemented, models.ErrorResponse{
-			Error:   "not_implemented",
-			Message: "Custom branch/tag reference is not yet supported",
-			Status:  http.StatusNotImplemented,
-		})
-		return
-	} else {
-		var err error
-		documentation, err = h.GitHubClient.GetRepositoryDocumentation(ctx, owner, repo, "", "", h.WorkerPoolSize)
-		if err != nil {
-			statusCode := http.StatusInternalServerError
-			if err.Error() == "repository not found" {
-				statusCode = http.StatusNotFound
-			} else if err.Error() == "unauthorized: invalid GitHub token" {
-				statusCode = http.StatusUnauthorized
-			} else if err.Error() == "rate limit exceeded or access denied" {
-				statusCode = http.StatusTooManyRequests
-			} else if err.Error() == "no documentation found for repository" {
-				statusCode = http.StatusNotFound
-			}
-
-			c.JSON(statusCode, models.ErrorResponse{
-				Error:   "github_api_error",
-				Message: err.Error(),
-				Status:  statusCode,
+	// A version constraint such as ^3 resolves to the highest matching tag
+	if version := strings.TrimSpace(c.Query("version")); version != "" {
+		if c.Query("ref") != "" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "invalid_request",
+				Message: "Use either 'ref' or 'version', not both",
+				Status:  http.StatusBadRequest,
 			})
 			return
 		}
+
+		tag, err := h.resolveVersion(ctx, src, repoLocation.Owner, repoLocation.Repo, version)
+		if err != nil {
+			respondWithError(c, "version_resolution_failed", err)
+			return
+		}
+		repoLocation.OverrideRef(tag)
+	}
+
+	// Get documentation
+	documentation, err := h.fetchDocumentationAtRef(ctx, src, repoLocation)
+	if err != nil {
+		respondWithError(c, "github_api_error", err)
+		return
 	}
 
 	// Return the documentation
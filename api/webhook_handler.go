@@ -0,0 +1,49 @@
+package api
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/dtomacheski/extract-data-go/internal/models"
+	"github.com/dtomacheski/extract-data-go/internal/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookPayloadSize is the largest payload GitHub sends
+const maxWebhookPayloadSize = 25 << 20
+
+// GitHubWebhook receives GitHub webhook deliveries. Push, release and tag creation
+// events drop the cached documentation of the ref and queue its re-indexing.
+func (h *Handler) GitHubWebhook(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookPayloadSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Failed to read webhook payload: " + err.Error(),
+			Status:  http.StatusBadRequest,
+		})
+		return
+	}
+
+	result, err := h.Webhooks.Handle(c.Request.Context(), webhook.Delivery{
+		ID:        c.GetHeader("X-GitHub-Delivery"),
+		Event:     c.GetHeader("X-GitHub-Event"),
+		Signature: c.GetHeader("X-Hub-Signature-256"),
+		Body:      body,
+	})
+	if err != nil {
+		h.Logger.Printf("Failed to handle GitHub webhook delivery %s: %v", c.GetHeader("X-GitHub-Delivery"), err)
+		respondWithError(c, "webhook_failed", err)
+		return
+	}
+
+	statusCode := http.StatusOK
+	if result.Status == webhook.StatusQueued {
+		statusCode = http.StatusAccepted
+	}
+	c.JSON(statusCode, models.SuccessResponse{
+		Status:  statusCode,
+		Message: "Webhook delivery " + result.Status,
+		Data:    result,
+	})
+}
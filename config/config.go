@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -12,6 +13,11 @@ import (
 // Config holds the application configuration
 type Config struct {
 	GitHubToken    string
+	GitHubTokens   []string // GITHUB_TOKEN followed by the tokens of GITHUB_TOKENS
+
+	// GitHub App authentication (GitHubAppID 0 disables it)
+	GitHubAppID         int64
+	GitHubAppPrivateKey []byte // PEM-encoded private key of the app
 	Port           string
 	WorkerPoolSize int
 	RequestTimeout time.Duration
@@ -21,6 +27,38 @@ type Config struct {
 	EnableCache    bool
 	CacheTTL       time.Duration
 	MinDaysBetweenRefreshes int // Minimum days required between documentation refreshes
+
+	// Retention of processed documentation history in MongoDB
+	DocHistoryLimit  int           // Versions kept per repository, ref and document
+	DocHistoryMaxAge time.Duration // Versions older than this are removed (0 keeps them)
+
+	// Additional documentation sources, selected by repository URL host
+	GitLabURL   string // Base URL of the GitLab instance (empty disables GitLab)
+	GitLabToken string
+	GiteaURL    string // Base URL of a Gitea or Forgejo instance (empty disables Gitea)
+	GiteaToken  string
+
+	// ArchiveFetchThreshold is the documentation file count above which GitHub repositories
+	// are fetched as a single tarball (0 disables archive fetches)
+	ArchiveFetchThreshold int
+
+	// GraphQLFetchThreshold is the documentation file count above which GitHub blobs are
+	// fetched in batched GraphQL queries (0 disables GraphQL fetches)
+	GraphQLFetchThreshold int
+
+	// Asynchronous indexing jobs
+	IndexJobWorkers int           // Number of jobs run at a time
+	IndexJobTimeout time.Duration // Maximum duration of a single job
+
+	// Scheduled refresh of tracked repositories (requires MongoDB)
+	SchedulerConcurrency  int           // Number of repositories refreshed at a time
+	SchedulerPollInterval time.Duration // How often due repositories are looked up
+
+	// GitHubWebhookSecret verifies the signature of GitHub webhook deliveries (empty disables webhooks)
+	GitHubWebhookSecret string
+
+	// LocalSourceRoot enables file:// repository URLs for directories and git repositories under it
+	LocalSourceRoot string
 	
 	// JWT Authentication settings
 	JWTSecret           string
@@ -34,10 +72,45 @@ func Load() (*Config, error) {
 	// Load .env file if it exists
 	_ = godotenv.Load()
 
-	// Get GitHub token
+	// Get local source root; with it the service can run without GitHub access
+	localSourceRoot := os.Getenv("LOCAL_SOURCE_ROOT")
+
+	// Get GitHub token and the optional pool of additional tokens
 	token := os.Getenv("GITHUB_TOKEN")
-	if token == "" {
-		return nil, fmt.Errorf("GITHUB_TOKEN environment variable is required")
+	var tokens []string
+	if token != "" {
+		tokens = append(tokens, token)
+	}
+	for _, t := range strings.Split(os.Getenv("GITHUB_TOKENS"), ",") {
+		if t = strings.TrimSpace(t); t != "" && t != token {
+			tokens = append(tokens, t)
+		}
+	}
+
+	// Get GitHub App settings; the private key is given inline or as a file
+	var appID int64
+	var appPrivateKey []byte
+	if appIDStr := os.Getenv("GITHUB_APP_ID"); appIDStr != "" {
+		var err error
+		appID, err = strconv.ParseInt(appIDStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GITHUB_APP_ID: %v", err)
+		}
+
+		appPrivateKey = []byte(os.Getenv("GITHUB_APP_PRIVATE_KEY"))
+		if keyPath := os.Getenv("GITHUB_APP_PRIVATE_KEY_PATH"); len(appPrivateKey) == 0 && keyPath != "" {
+			appPrivateKey, err = os.ReadFile(keyPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read GITHUB_APP_PRIVATE_KEY_PATH: %v", err)
+			}
+		}
+		if len(appPrivateKey) == 0 {
+			return nil, fmt.Errorf("GITHUB_APP_PRIVATE_KEY or GITHUB_APP_PRIVATE_KEY_PATH is required with GITHUB_APP_ID")
+		}
+	}
+
+	if len(tokens) == 0 && appID == 0 && localSourceRoot == "" {
+		return nil, fmt.Errorf("GITHUB_TOKEN, GITHUB_TOKENS or GITHUB_APP_ID environment variable is required")
 	}
 
 	// Get port
@@ -110,6 +183,122 @@ func Load() (*Config, error) {
 		}
 	}
 
+	// GitLab source, gitlab.com unless set to another instance or "disabled"
+	gitlabURL := os.Getenv("GITLAB_URL")
+	if gitlabURL == "" {
+		gitlabURL = "https://gitlab.com" // Default instance
+	} else if gitlabURL == "disabled" {
+		gitlabURL = ""
+	}
+
+	// Gitea/Forgejo source, disabled unless an instance is configured
+	giteaURL := os.Getenv("GITEA_URL")
+
+	// Get archive fetch threshold
+	archiveThresholdStr := os.Getenv("ARCHIVE_FETCH_THRESHOLD")
+	archiveThreshold := 50 // Default value
+	if archiveThresholdStr != "" {
+		var err error
+		archiveThreshold, err = strconv.Atoi(archiveThresholdStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ARCHIVE_FETCH_THRESHOLD: %v", err)
+		}
+	}
+
+	// Get GraphQL fetch threshold
+	graphqlThresholdStr := os.Getenv("GRAPHQL_FETCH_THRESHOLD")
+	graphqlThreshold := 5 // Default value
+	if graphqlThresholdStr != "" {
+		var err error
+		graphqlThreshold, err = strconv.Atoi(graphqlThresholdStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GRAPHQL_FETCH_THRESHOLD: %v", err)
+		}
+	}
+
+	// Get indexing job workers
+	indexJobWorkersStr := os.Getenv("INDEX_JOB_WORKERS")
+	indexJobWorkers := 2 // Default value
+	if indexJobWorkersStr != "" {
+		var err error
+		indexJobWorkers, err = strconv.Atoi(indexJobWorkersStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid INDEX_JOB_WORKERS: %v", err)
+		}
+		if indexJobWorkers <= 0 {
+			indexJobWorkers = 2 // Ensure a positive value
+		}
+	}
+
+	// Get indexing job timeout
+	indexJobTimeoutStr := os.Getenv("INDEX_JOB_TIMEOUT")
+	indexJobTimeout := 30 * time.Minute // Default value
+	if indexJobTimeoutStr != "" {
+		var err error
+		indexJobTimeout, err = time.ParseDuration(indexJobTimeoutStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid INDEX_JOB_TIMEOUT: %v", err)
+		}
+		if indexJobTimeout <= 0 {
+			indexJobTimeout = 30 * time.Minute // Ensure a positive value
+		}
+	}
+
+	// Get scheduler concurrency
+	schedulerConcurrencyStr := os.Getenv("SCHEDULER_CONCURRENCY")
+	schedulerConcurrency := 2 // Default value
+	if schedulerConcurrencyStr != "" {
+		var err error
+		schedulerConcurrency, err = strconv.Atoi(schedulerConcurrencyStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SCHEDULER_CONCURRENCY: %v", err)
+		}
+		if schedulerConcurrency <= 0 {
+			schedulerConcurrency = 2 // Ensure a positive value
+		}
+	}
+
+	// Get scheduler poll interval
+	schedulerPollStr := os.Getenv("SCHEDULER_POLL_INTERVAL")
+	schedulerPoll := time.Minute // Default value
+	if schedulerPollStr != "" {
+		var err error
+		schedulerPoll, err = time.ParseDuration(schedulerPollStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SCHEDULER_POLL_INTERVAL: %v", err)
+		}
+		if schedulerPoll <= 0 {
+			schedulerPoll = time.Minute // Ensure a positive value
+		}
+	}
+
+	// Get documentation history retention
+	docHistoryLimitStr := os.Getenv("DOC_HISTORY_LIMIT")
+	docHistoryLimit := 10 // Default value
+	if docHistoryLimitStr != "" {
+		var err error
+		docHistoryLimit, err = strconv.Atoi(docHistoryLimitStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DOC_HISTORY_LIMIT: %v", err)
+		}
+		if docHistoryLimit <= 0 {
+			docHistoryLimit = 10 // Ensure a positive value
+		}
+	}
+
+	docHistoryMaxAgeStr := os.Getenv("DOC_HISTORY_MAX_AGE")
+	var docHistoryMaxAge time.Duration // Default value: versions are not expired
+	if docHistoryMaxAgeStr != "" {
+		var err error
+		docHistoryMaxAge, err = time.ParseDuration(docHistoryMaxAgeStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DOC_HISTORY_MAX_AGE: %v", err)
+		}
+		if docHistoryMaxAge < 0 {
+			docHistoryMaxAge = 0
+		}
+	}
+
 	// JWT settings
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
@@ -152,6 +341,9 @@ func Load() (*Config, error) {
 
 	return &Config{
 		GitHubToken:    token,
+		GitHubTokens:   tokens,
+		GitHubAppID:    appID,
+		GitHubAppPrivateKey: appPrivateKey,
 		Port:           port,
 		WorkerPoolSize: workerPoolSize,
 		RequestTimeout: timeout,
@@ -161,6 +353,20 @@ func Load() (*Config, error) {
 		EnableCache:    enableCache,
 		CacheTTL:       cacheTTL,
 		MinDaysBetweenRefreshes: minDaysBetweenRefreshes,
+		DocHistoryLimit:    docHistoryLimit,
+		DocHistoryMaxAge:   docHistoryMaxAge,
+		GitLabURL:          gitlabURL,
+		GitLabToken:        os.Getenv("GITLAB_TOKEN"),
+		GiteaURL:           giteaURL,
+		GiteaToken:         os.Getenv("GITEA_TOKEN"),
+		LocalSourceRoot:    localSourceRoot,
+		ArchiveFetchThreshold: archiveThreshold,
+		GraphQLFetchThreshold: graphqlThreshold,
+		IndexJobWorkers:    indexJobWorkers,
+		IndexJobTimeout:    indexJobTimeout,
+		SchedulerConcurrency:  schedulerConcurrency,
+		SchedulerPollInterval: schedulerPoll,
+		GitHubWebhookSecret:   os.Getenv("GITHUB_WEBHOOK_SECRET"),
 		JWTSecret:          jwtSecret,
 		JWTAccessDuration:  accessDuration,
 		JWTRefreshDuration: refreshDuration,
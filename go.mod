@@ -10,9 +10,11 @@ require (
 	github.com/joho/godotenv v1.5.1
 	github.com/redis/go-redis/v9 v9.8.0
 	github.com/stretchr/testify v1.10.0
+	github.com/yuin/goldmark v1.7.13
 	go.mongodb.org/mongo-driver/v2 v2.2.0
 	golang.org/x/crypto v0.38.0
 	golang.org/x/oauth2 v0.29.0
+	golang.org/x/sync v0.14.0
 )
 
 require (
@@ -53,7 +55,6 @@ require (
 	github.com/yuin/gopher-lua v1.1.1 // indirect
 	golang.org/x/arch v0.17.0 // indirect
 	golang.org/x/net v0.40.0 // indirect
-	golang.org/x/sync v0.14.0 // indirect
 	golang.org/x/sys v0.33.0 // indirect
 	golang.org/x/text v0.25.0 // indirect
 	google.golang.org/protobuf v1.36.6 // indirect
@@ -22,6 +22,9 @@ type Cache interface {
 	// Delete removes a value from the cache
 	Delete(ctx context.Context, key string) error
 	
+	// DeleteByPrefix removes every value whose key starts with prefix
+	DeleteByPrefix(ctx context.Context, prefix string) error
+	
 	// FlushAll removes all entries from the cache
 	FlushAll(ctx context.Context) error
 	
@@ -49,6 +52,11 @@ func (kb *KeyBuilder) RepositoryKey(owner, repo string) string {
 	return kb.Prefix + ":repo:" + owner + ":" + repo
 }
 
+// RepositoryVersionsKey builds a cache key for the semver versions listed from a repository's tags
+func (kb *KeyBuilder) RepositoryVersionsKey(owner, repo string) string {
+	return kb.Prefix + ":repo_versions:" + owner + ":" + repo
+}
+
 // RepositoryDocumentationKey generates a unique cache key for repository documentation, including an optional ref (tag/branch).
 func (kb *KeyBuilder) RepositoryDocumentationKey(owner, repo, ref string) string {
 	// If ref is empty, we might cache it under a general key or a specific 'default' key.
@@ -56,14 +64,59 @@ func (kb *KeyBuilder) RepositoryDocumentationKey(owner, repo, ref string) string
 	return fmt.Sprintf("%s:repo_docs:%s:%s:%s", kb.Prefix, owner, repo, ref)
 }
 
+// RepositoryDocumentationPathKey generates a cache key for the documentation of a repository on a host
+// at a ref, optionally restricted to a sub path of the repository.
+func (kb *KeyBuilder) RepositoryDocumentationPathKey(host, owner, repo, ref, subPath string) string {
+	return kb.RepositoryDocumentationPathPrefix(host, owner, repo, ref) + strings.Trim(subPath, "/")
+}
+
+// RepositoryDocumentationPathPrefix generates the prefix shared by the documentation cache keys
+// of every sub path of a repository ref on a host
+func (kb *KeyBuilder) RepositoryDocumentationPathPrefix(host, owner, repo, ref string) string {
+	return fmt.Sprintf("%s:repo_docs_path:%s:%s:%s:%s:", kb.Prefix, host, owner, repo, ref)
+}
+
 // RepositoryDocumentationMetadataKey generates a unique cache key for repository documentation metadata index.
 func (kb *KeyBuilder) RepositoryDocumentationMetadataKey(owner, repo, ref string) string {
 	return fmt.Sprintf("%s:doc_metadata:%s:%s:%s", kb.Prefix, owner, repo, ref)
 }
 
-// DocumentContentKey generates a unique cache key for an individual document content.
-func (kb *KeyBuilder) DocumentContentKey(owner, repo, ref string, pathHash string) string {
-	return fmt.Sprintf("%s:doc_content:%s:%s:%s:%s", kb.Prefix, owner, repo, ref, pathHash)
+// RepositoryIndexKey generates a cache key for the long-lived index that incremental
+// re-indexing compares new commits against
+func (kb *KeyBuilder) RepositoryIndexKey(owner, repo, ref string) string {
+	return fmt.Sprintf("%s:repo_index:%s:%s:%s", kb.Prefix, owner, repo, ref)
+}
+
+// DocumentContentKey generates a unique cache key for an individual document content. Files
+// with the same blob share its SHA, so the key includes the path of the document.
+func (kb *KeyBuilder) DocumentContentKey(owner, repo, ref, path, blobSHA string) string {
+	return fmt.Sprintf("%s:doc_content:%s:%s:%s:%s:%s", kb.Prefix, owner, repo, ref, blobSHA, path)
+}
+
+// HTTPValidatorsKey generates a cache key for the stored response and ETag/Last-Modified
+// validators of an upstream HTTP request, identified by a hash of the request
+func (kb *KeyBuilder) HTTPValidatorsKey(requestHash string) string {
+	return fmt.Sprintf("%s:http_validators:%s", kb.Prefix, requestHash)
+}
+
+// JobKey generates the key of an indexing job
+func (kb *KeyBuilder) JobKey(id string) string {
+	return fmt.Sprintf("%s:jobs:job:%s", kb.Prefix, id)
+}
+
+// JobQueueKey generates the key of the list of queued indexing job IDs
+func (kb *KeyBuilder) JobQueueKey() string {
+	return kb.Prefix + ":jobs:queue"
+}
+
+// JobsInFlightKey generates the key of the list of indexing job IDs taken by a worker
+func (kb *KeyBuilder) JobsInFlightKey() string {
+	return kb.Prefix + ":jobs:in_flight"
+}
+
+// WebhookDeliveryKey generates the key marking a webhook delivery as received
+func (kb *KeyBuilder) WebhookDeliveryKey(deliveryID string) string {
+	return fmt.Sprintf("%s:webhooks:delivery:%s", kb.Prefix, deliveryID)
 }
 
 // SearchKey builds a cache key for repository search results
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -200,6 +201,60 @@ func (c *RedisClient) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// deleteByPrefixBatch is the number of keys scanned and deleted at a time by DeleteByPrefix
+const deleteByPrefixBatch = 100
+
+// DeleteByPrefix removes every value whose key starts with prefix, scanning the keyspace
+// instead of blocking Redis with KEYS
+func (c *RedisClient) DeleteByPrefix(ctx context.Context, prefix string) error {
+	if !c.IsEnabled() {
+		return ErrCacheDown
+	}
+
+	// Add logging
+	startTime := time.Now()
+	deleted := 0
+	defer func() {
+		if c.logger != nil {
+			c.logger.Printf("Cache DELETE by prefix '%s' removed %d keys in %v", prefix, deleted, time.Since(startTime))
+		}
+	}()
+
+	// Keys are deleted once the scan completes, so that deleting does not move the scan cursor
+	pattern := globEscaper.Replace(prefix) + "*"
+	var keys []string
+	var cursor uint64
+	for {
+		batch, next, err := c.client.Scan(ctx, cursor, pattern, deleteByPrefixBatch).Result()
+		if err != nil {
+			if c.logger != nil {
+				c.logger.Printf("Redis SCAN error: %v", err)
+			}
+			return err
+		}
+		keys = append(keys, batch...)
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	for start := 0; start < len(keys); start += deleteByPrefixBatch {
+		end := min(start+deleteByPrefixBatch, len(keys))
+		if err := c.client.Del(ctx, keys[start:end]...).Err(); err != nil {
+			if c.logger != nil {
+				c.logger.Printf("Redis DELETE error: %v", err)
+			}
+			return err
+		}
+		deleted = end
+	}
+	return nil
+}
+
+// globEscaper escapes the special characters of Redis glob-style patterns
+var globEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
 // FlushAll removes all entries from the cache
 func (c *RedisClient) FlushAll(ctx context.Context) error {
 	if !c.IsEnabled() {
@@ -240,3 +295,11 @@ func (c *RedisClient) Close() error {
 	}
 	return nil
 }
+
+// Client returns the underlying Redis client, nil when the cache is disabled
+func (c *RedisClient) Client() *redis.Client {
+	if !c.IsEnabled() {
+		return nil
+	}
+	return c.client
+}
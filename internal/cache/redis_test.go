@@ -140,6 +140,27 @@ func TestRedisClient_Delete(t *testing.T) {
 	assert.Equal(t, ErrCacheMiss, err)
 }
 
+// TestRedisClient_DeleteByPrefix tests that only the keys starting with the prefix are removed
+func TestRedisClient_DeleteByPrefix(t *testing.T) {
+	s, client := setupMockRedis(t)
+	defer s.Close()
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// More keys than a scan batch, and keys that only match once the prefix is escaped
+	for i := 0; i < deleteByPrefixBatch+10; i++ {
+		assert.NoError(t, client.Set(ctx, "test:docs:main:"+strconv.Itoa(i), i))
+	}
+	assert.NoError(t, client.Set(ctx, "test:docs:mainline:1", 1))
+	assert.NoError(t, client.Set(ctx, "test:docs:ma?n:1", 1))
+	assert.NoError(t, client.Set(ctx, "test:docs:ma[i]n:1", 1))
+
+	assert.NoError(t, client.DeleteByPrefix(ctx, "test:docs:main:"))
+	assert.NoError(t, client.DeleteByPrefix(ctx, "test:docs:ma?n:"))
+	assert.Equal(t, []string{"test:docs:ma[i]n:1", "test:docs:mainline:1"}, s.Keys())
+}
+
 // TestRedisClient_FlushAll tests the FlushAll operation
 func TestRedisClient_FlushAll(t *testing.T) {
 	s, client := setupMockRedis(t)
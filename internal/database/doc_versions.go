@@ -0,0 +1,287 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// DefaultHistoryLimit is the number of versions kept per repository, ref and filename
+const DefaultHistoryLimit = 10
+
+// ErrDocVersionNotFound is returned when no documentation was stored for a commit
+var ErrDocVersionNotFound = errors.New("documentation version not found")
+
+// DocVersion is the processed documentation of a repository at a commit
+type DocVersion struct {
+	RepoName      string    `bson:"repo_name" json:"repo_name"`
+	Ref           string    `bson:"ref" json:"ref"`
+	CommitSHA     string    `bson:"commit_sha" json:"commit_sha"`
+	Filename      string    `bson:"filename" json:"filename"`
+	Size          int       `bson:"size" json:"size"`
+	SnippetsCount int       `bson:"snippets_count" json:"snippets_count"`
+	Content       string    `bson:"content,omitempty" json:"content,omitempty"`
+	CreatedAt     time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// SetHistoryRetention limits the documentation history to the limit most recent versions
+// per repository, ref and filename, and drops versions older than maxAge (0 keeps them).
+// The current version is always kept.
+func (c *Client) SetHistoryRetention(limit int, maxAge time.Duration) {
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	c.historyLimit = limit
+	c.historyMaxAge = maxAge
+}
+
+// docsUniqueIndexName names the unique index of processed documentation per repository, ref
+// and filename. It is created once the documents stored before refs were recorded are migrated,
+// so its absence means the migration has not completed yet.
+const docsUniqueIndexName = "repo_name_1_ref_1_filename_1"
+
+// migrationTimeout bounds the migration of the documents stored before refs were recorded
+const migrationTimeout = 30 * time.Minute
+
+// ensureDocumentationIndexes makes the documentation history unique per commit
+func (c *Client) ensureDocumentationIndexes(ctx context.Context) error {
+	_, err := c.history.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "repo_name", Value: 1}, {Key: "ref", Value: 1}, {Key: "filename", Value: 1}, {Key: "commit_sha", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "repo_name", Value: 1}, {Key: "updated_at", Value: -1}},
+		},
+	})
+	return err
+}
+
+// startDocumentsMigration starts the migration of the processed documentation in the
+// background when it has not completed yet, so that a large collection does not hold up
+// the start of the service
+func (c *Client) startDocumentsMigration(ctx context.Context) error {
+	indexes, err := c.docs.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		if index.Name == docsUniqueIndexName {
+			close(c.migrated)
+			return nil
+		}
+	}
+
+	migrationCtx, cancel := context.WithTimeout(context.Background(), migrationTimeout)
+	c.cancelMigration = cancel
+	go func() {
+		defer close(c.migrated)
+		defer cancel()
+		if err := c.migrateDocuments(migrationCtx); err != nil {
+			c.logger.Printf("Failed to migrate processed documentation, retrying on the next start: %v", err)
+			return
+		}
+		c.logger.Printf("Migrated processed documentation to one document per repository, ref and filename")
+	}()
+	return nil
+}
+
+// migrateDocuments makes processed documentation unique per repository, ref and filename.
+// Documents stored before refs were recorded get an empty ref, and only the most recent of
+// their duplicates is kept.
+func (c *Client) migrateDocuments(ctx context.Context) error {
+	missingRef := bson.D{{Key: "ref", Value: bson.D{{Key: "$exists", Value: false}}}}
+	setEmptyRef := bson.D{{Key: "$set", Value: bson.D{{Key: "ref", Value: ""}, {Key: "commit_sha", Value: ""}}}}
+	if _, err := c.docs.UpdateMany(ctx, missingRef, setEmptyRef); err != nil {
+		return err
+	}
+	if err := c.removeDuplicateDocuments(ctx); err != nil {
+		return err
+	}
+
+	_, err := c.docs.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "repo_name", Value: 1}, {Key: "ref", Value: 1}, {Key: "filename", Value: 1}},
+		Options: options.Index().SetName(docsUniqueIndexName).SetUnique(true),
+	})
+	return err
+}
+
+// removeDuplicateDocuments deletes all but the most recent document of each repository,
+// ref and filename, left over from when every refresh inserted a new document
+func (c *Client) removeDuplicateDocuments(ctx context.Context) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "updated_at", Value: -1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "repo_name", Value: "$repo_name"},
+				{Key: "ref", Value: "$ref"},
+				{Key: "filename", Value: "$filename"},
+			}},
+			{Key: "ids", Value: bson.D{{Key: "$push", Value: "$_id"}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 1}}}}}},
+	}
+
+	// The whole collection is sorted and grouped, past the memory limit of a pipeline stage
+	cursor, err := c.docs.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		IDs []bson.ObjectID `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		stale := bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: group.IDs[1:]}}}}
+		result, err := c.docs.DeleteMany(ctx, stale)
+		if err != nil {
+			return err
+		}
+		c.logger.Printf("Removed %d duplicate processed documents", result.DeletedCount)
+	}
+	return nil
+}
+
+// storeVersion adds or replaces the version of a commit in the history
+func (c *Client) storeVersion(ctx context.Context, version DocVersion, now time.Time) error {
+	filter := bson.D{
+		{Key: "repo_name", Value: version.RepoName},
+		{Key: "ref", Value: version.Ref},
+		{Key: "filename", Value: version.Filename},
+		{Key: "commit_sha", Value: version.CommitSHA},
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "size", Value: version.Size},
+			{Key: "snippets_count", Value: version.SnippetsCount},
+			{Key: "content", Value: version.Content},
+			{Key: "updated_at", Value: now},
+		}},
+		{Key: "$setOnInsert", Value: bson.D{{Key: "created_at", Value: now}}},
+	}
+	_, err := c.history.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+	return err
+}
+
+// pruneHistory removes the versions beyond the retention limits, except the current one
+func (c *Client) pruneHistory(ctx context.Context, repoName, ref, filename, currentSHA string) error {
+	filter := bson.D{
+		{Key: "repo_name", Value: repoName},
+		{Key: "ref", Value: ref},
+		{Key: "filename", Value: filename},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: -1}}).
+		SetSkip(int64(c.historyLimit)).
+		SetProjection(bson.D{{Key: "_id", Value: 1}})
+
+	cursor, err := c.history.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	var stale []struct {
+		ID bson.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &stale); err != nil {
+		return err
+	}
+
+	ids := make([]bson.ObjectID, 0, len(stale))
+	for _, version := range stale {
+		ids = append(ids, version.ID)
+	}
+	expired := bson.A{bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}}}
+	if c.historyMaxAge > 0 {
+		expired = append(expired, bson.D{{Key: "updated_at", Value: bson.D{{Key: "$lt", Value: time.Now().Add(-c.historyMaxAge)}}}})
+	}
+
+	filter = append(filter,
+		bson.E{Key: "commit_sha", Value: bson.D{{Key: "$ne", Value: currentSHA}}},
+		bson.E{Key: "$or", Value: expired},
+	)
+	_, err = c.history.DeleteMany(ctx, filter)
+	return err
+}
+
+// GetCurrentDocumentation retrieves the current documentation of a repository at a ref,
+// the one stored by its most recent indexing
+func (c *Client) GetCurrentDocumentation(ctx context.Context, repoName, ref, filename string) (*DocStorage, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "repo_name", Value: repoName},
+		{Key: "ref", Value: ref},
+		{Key: "filename", Value: filename},
+	}
+
+	var doc DocStorage
+	if err := c.docs.FindOne(ctx, filter).Decode(&doc); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrDocVersionNotFound
+		}
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// ListDocumentationVersions lists the stored versions of a repository's documentation,
+// most recent first, without their content. An empty ref lists the versions of every ref.
+func (c *Client) ListDocumentationVersions(ctx context.Context, repoName, ref string) ([]DocVersion, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	filter := bson.D{{Key: "repo_name", Value: repoName}}
+	if ref != "" {
+		filter = append(filter, bson.E{Key: "ref", Value: ref})
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updated_at", Value: -1}}).
+		SetProjection(bson.D{{Key: "content", Value: 0}})
+
+	cursor, err := c.history.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	versions := []DocVersion{}
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetDocumentationVersion retrieves the documentation of a repository stored for a commit
+func (c *Client) GetDocumentationVersion(ctx context.Context, repoName, filename, commitSHA string) (*DocVersion, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	// The same commit may have been indexed under several refs, e.g. a branch and a tag
+	filter := bson.D{
+		{Key: "repo_name", Value: repoName},
+		{Key: "filename", Value: filename},
+		{Key: "commit_sha", Value: commitSHA},
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "updated_at", Value: -1}})
+
+	var version DocVersion
+	if err := c.history.FindOne(ctx, filter, opts).Decode(&version); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrDocVersionNotFound
+		}
+		return nil, err
+	}
+	return &version, nil
+}
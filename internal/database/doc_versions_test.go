@@ -0,0 +1,175 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// testDatabase returns the URI of the MongoDB server set in MONGO_TEST_URI and the name of a
+// database of its own, dropped at the end of the test
+func testDatabase(t *testing.T) (string, string) {
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("Skipping test: MONGO_TEST_URI not set")
+	}
+	name := fmt.Sprintf("go-mcpdocs-test-%d", time.Now().UnixNano())
+
+	t.Cleanup(func() {
+		client, err := mongo.Connect(options.Client().ApplyURI(uri))
+		if err != nil {
+			return
+		}
+		defer client.Disconnect(context.Background())
+		client.Database(name).Drop(context.Background())
+	})
+	return uri, name
+}
+
+// newTestClient connects to a test database once its documents are migrated
+func newTestClient(t *testing.T) *Client {
+	uri, name := testDatabase(t)
+	c, err := newClient(uri, name, log.New(io.Discard, "", 0))
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close(context.Background()) })
+	<-c.migrated
+	return c
+}
+
+// store stores a version of the acme/widgets documentation, leaving the stored timestamps apart
+func store(t *testing.T, c *Client, ref, commitSHA string) {
+	require.NoError(t, c.StoreProcessedDocumentation(context.Background(), "acme", "widgets", ref, commitSHA, "widgets.txt", "content at "+commitSHA, 1))
+	time.Sleep(5 * time.Millisecond)
+}
+
+// count counts the documents of a collection matching filter
+func count(t *testing.T, collection *mongo.Collection, filter bson.D) int64 {
+	n, err := collection.CountDocuments(context.Background(), filter)
+	require.NoError(t, err)
+	return n
+}
+
+// TestStoreProcessedDocumentation_Versions tests that storing keeps one current document per
+// ref and adds each commit to the history once
+func TestStoreProcessedDocumentation_Versions(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	store(t, c, "main", "sha1")
+	store(t, c, "main", "sha2")
+	store(t, c, "main", "sha2")
+	store(t, c, "v1.0.0", "sha1")
+
+	mainRef := bson.D{{Key: "repo_name", Value: "acme/widgets"}, {Key: "ref", Value: "main"}}
+	assert.Equal(t, int64(1), count(t, c.docs, mainRef))
+	assert.Equal(t, int64(2), count(t, c.history, mainRef))
+	assert.Equal(t, int64(2), count(t, c.docs, bson.D{{Key: "repo_name", Value: "acme/widgets"}}))
+
+	current, err := c.GetCurrentDocumentation(ctx, "acme/widgets", "main", "widgets.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "sha2", current.CommitSHA)
+	assert.Equal(t, "content at sha2", current.Content)
+	assert.Equal(t, "/acme/widgets/widgets.txt", current.ProcessedPath)
+
+	current, err = c.GetCurrentDocumentation(ctx, "acme/widgets", "v1.0.0", "widgets.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "sha1", current.CommitSHA)
+
+	_, err = c.GetCurrentDocumentation(ctx, "acme/widgets", "v2.0.0", "widgets.txt")
+	assert.ErrorIs(t, err, ErrDocVersionNotFound)
+
+	version, err := c.GetDocumentationVersion(ctx, "acme/widgets", "widgets.txt", "sha1")
+	require.NoError(t, err)
+	assert.Equal(t, "content at sha1", version.Content)
+	_, err = c.GetDocumentationVersion(ctx, "acme/widgets", "widgets.txt", "sha9")
+	assert.ErrorIs(t, err, ErrDocVersionNotFound)
+
+	versions, err := c.ListDocumentationVersions(ctx, "acme/widgets", "main")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "sha2", versions[0].CommitSHA)
+	assert.Empty(t, versions[0].Content)
+}
+
+// TestStoreProcessedDocumentation_Retention tests that the history is pruned to its limit and
+// maximum age, always keeping the current version
+func TestStoreProcessedDocumentation_Retention(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+	c.SetHistoryRetention(2, 0)
+
+	for _, sha := range []string{"sha1", "sha2", "sha3", "sha4"} {
+		store(t, c, "main", sha)
+	}
+	versions, err := c.ListDocumentationVersions(ctx, "acme/widgets", "main")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "sha4", versions[0].CommitSHA)
+	assert.Equal(t, "sha3", versions[1].CommitSHA)
+
+	// Versions past the maximum age go, except the current one
+	c.SetHistoryRetention(10, time.Hour)
+	old := bson.D{{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now().Add(-2 * time.Hour)}}}}
+	_, err = c.history.UpdateMany(ctx, bson.D{{Key: "repo_name", Value: "acme/widgets"}}, old)
+	require.NoError(t, err)
+
+	require.NoError(t, c.pruneHistory(ctx, "acme/widgets", "main", "widgets.txt", "sha4"))
+	versions, err = c.ListDocumentationVersions(ctx, "acme/widgets", "main")
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, "sha4", versions[0].CommitSHA)
+}
+
+// TestNewClient_MigratesLegacyDocuments tests that documents stored before versioning get an
+// empty ref and lose their older duplicates, in the background and only once
+func TestNewClient_MigratesLegacyDocuments(t *testing.T) {
+	uri, name := testDatabase(t)
+	ctx := context.Background()
+
+	raw, err := mongo.Connect(options.Client().ApplyURI(uri))
+	require.NoError(t, err)
+	defer raw.Disconnect(ctx)
+
+	now := time.Now()
+	legacy := []interface{}{
+		bson.D{{Key: "repo_name", Value: "acme/widgets"}, {Key: "filename", Value: "widgets.txt"}, {Key: "content", Value: "old"}, {Key: "updated_at", Value: now.Add(-time.Hour)}},
+		bson.D{{Key: "repo_name", Value: "acme/widgets"}, {Key: "filename", Value: "widgets.txt"}, {Key: "content", Value: "new"}, {Key: "updated_at", Value: now}},
+		bson.D{{Key: "repo_name", Value: "acme/gadgets"}, {Key: "filename", Value: "gadgets.txt"}, {Key: "content", Value: "only"}, {Key: "updated_at", Value: now}},
+	}
+	_, err = raw.Database(name).Collection(DocsCollectionName).InsertMany(ctx, legacy)
+	require.NoError(t, err)
+
+	c, err := newClient(uri, name, log.New(io.Discard, "", 0))
+	require.NoError(t, err)
+	defer c.Close(ctx)
+	require.NotNil(t, c.cancelMigration)
+	<-c.migrated
+
+	assert.Equal(t, int64(2), count(t, c.docs, bson.D{}))
+	current, err := c.GetCurrentDocumentation(ctx, "acme/widgets", "", "widgets.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "new", current.Content)
+	assert.Empty(t, current.CommitSHA)
+
+	// Connecting again finds the unique index and skips the migration
+	again, err := newClient(uri, name, log.New(io.Discard, "", 0))
+	require.NoError(t, err)
+	defer again.Close(ctx)
+	assert.Nil(t, again.cancelMigration)
+	select {
+	case <-again.migrated:
+	default:
+		t.Fatal("migration not skipped")
+	}
+	assert.Equal(t, int64(2), count(t, again.docs, bson.D{}))
+}
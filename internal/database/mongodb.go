@@ -16,15 +16,19 @@ import (
 
 // MongoDB connection and collection constants
 const (
-	DatabaseName       = "go-mcpdocs"
-	DocsCollectionName = "documentation"
-	DefaultTimeout     = 10 * time.Second
+	DatabaseName               = "go-mcpdocs"
+	DocsCollectionName         = "documentation"
+	DocsHistoryCollectionName  = "documentation_history"
+	TrackedReposCollectionName = "tracked_repositories"
+	DefaultTimeout             = 10 * time.Second
 )
 
 // DocStorage é uma versão leve da Documentation para armazenamento no DB
 type DocStorage struct {
 	RepoID        int64     `bson:"repo_id"`
 	RepoName      string    `bson:"repo_name"`
+	Ref           string    `bson:"ref"`            // Ref resolved when indexing, e.g. main
+	CommitSHA     string    `bson:"commit_sha"`     // Versão atual no histórico
 	Filename      string    `bson:"filename"`       // Ex: llms.txt
 	ProcessedPath string    `bson:"processed_path"` // Ex: /vercel/next.js/llms.txt
 	ContentType   string    `bson:"content_type"`
@@ -40,12 +44,27 @@ type Client struct {
 	client   *mongo.Client
 	database *mongo.Database
 	docs     *mongo.Collection
+	history  *mongo.Collection
+	tracked  *mongo.Collection
 	timeout  time.Duration
 	logger   *log.Logger
+
+	// Retention of the documentation history
+	historyLimit  int
+	historyMaxAge time.Duration
+
+	// Migration of the processed documentation, run in the background
+	migrated        chan struct{} // Closed once the migration ended or was not needed
+	cancelMigration context.CancelFunc
 }
 
 // NewClient creates a new MongoDB client
 func NewClient(uri string, logger *log.Logger) (*Client, error) {
+	return newClient(uri, DatabaseName, logger)
+}
+
+// newClient connects to a database and makes sure its indexes exist
+func newClient(uri, databaseName string, logger *log.Logger) (*Client, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
 
@@ -66,53 +85,101 @@ func NewClient(uri string, logger *log.Logger) (*Client, error) {
 		return nil, err
 	}
 
-	database := client.Database(DatabaseName)
+	database := client.Database(databaseName)
 	docs := database.Collection(DocsCollectionName)
 
-	return &Client{
+	c := &Client{
 		client:   client,
 		database: database,
 		docs:     docs,
+		history:  database.Collection(DocsHistoryCollectionName),
+		tracked:  database.Collection(TrackedReposCollectionName),
 		timeout:  DefaultTimeout,
 		logger:   logger,
-	}, nil
+
+		historyLimit: DefaultHistoryLimit,
+		migrated:     make(chan struct{}),
+	}
+	if err := c.ensureDocumentationIndexes(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.ensureTrackedRepoIndexes(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.startDocumentsMigration(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
 }
 
-// Close disconnects the client
+// Close stops a migration still in progress and disconnects the client
 func (c *Client) Close(ctx context.Context) error {
+	if c.cancelMigration != nil {
+		c.cancelMigration()
+		<-c.migrated
+	}
 	return c.client.Disconnect(ctx)
 }
 
-// StoreProcessedDocumentation armazena documentação processada no MongoDB
-func (c *Client) StoreProcessedDocumentation(ctx context.Context, repoOwner, repoName string, filename string, content string, snippetsCount int) error {
+// StoreProcessedDocumentation armazena documentação processada no MongoDB. The version of
+// the commit is added to the history, then the current document of the repository, ref and
+// filename is replaced with it; older versions beyond the retention limits are removed.
+func (c *Client) StoreProcessedDocumentation(ctx context.Context, repoOwner, repoName, ref, commitSHA, filename, content string, snippetsCount int) error {
 	if content == "" {
 		return errors.New("no content to store")
 	}
 
 	// Create the full path in the format /owner/repo/filename.txt
 	processedPath := fmt.Sprintf("/%s/%s/%s", repoOwner, repoName, filename)
+	repoFullName := fmt.Sprintf("%s/%s", repoOwner, repoName)
+	now := time.Now()
+
+	// Set context with timeout
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
 
-	// Create storage document
-	storeDoc := DocStorage{
-		RepoID:        0, // This could be set if needed
-		RepoName:      fmt.Sprintf("%s/%s", repoOwner, repoName),
+	version := DocVersion{
+		RepoName:      repoFullName,
+		Ref:           ref,
+		CommitSHA:     commitSHA,
 		Filename:      filename,
-		ProcessedPath: processedPath,
-		ContentType:   "text/plain",
 		Size:          len(content),
 		SnippetsCount: snippetsCount,
 		Content:       content,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+	}
+	if err := c.storeVersion(ctx, version, now); err != nil {
+		return fmt.Errorf("failed to store documentation version: %w", err)
 	}
 
-	// Set context with timeout
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
+	// The current document keeps a copy of the content so that it is read in one query
+	filter := bson.D{
+		{Key: "repo_name", Value: repoFullName},
+		{Key: "ref", Value: ref},
+		{Key: "filename", Value: filename},
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "commit_sha", Value: commitSHA},
+			{Key: "processed_path", Value: processedPath},
+			{Key: "content_type", Value: "text/plain"},
+			{Key: "size", Value: len(content)},
+			{Key: "snippets_count", Value: snippetsCount},
+			{Key: "content", Value: content},
+			{Key: "updated_at", Value: now},
+		}},
+		{Key: "$setOnInsert", Value: bson.D{
+			{Key: "repo_id", Value: int64(0)},
+			{Key: "created_at", Value: now},
+		}},
+	}
+	if _, err := c.docs.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true)); err != nil {
+		return err
+	}
 
-	// Insert the document
-	_, err := c.docs.InsertOne(ctx, storeDoc)
-	return err
+	if err := c.pruneHistory(ctx, repoFullName, ref, filename, commitSHA); err != nil {
+		c.logger.Printf("Failed to prune documentation history of %s (ref: %s): %v", repoFullName, ref, err)
+	}
+	return nil
 }
 
 // StoreDocumentation is kept for backward compatibility
@@ -146,9 +213,11 @@ func (c *Client) GetDocumentationByProcessedPath(ctx context.Context, processedP
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
 
+	// Every indexed ref of a repository has its own document, so prefer the most recent one
 	filter := bson.D{{Key: "processed_path", Value: processedPath}}
+	opts := options.FindOne().SetSort(bson.D{{Key: "updated_at", Value: -1}})
 	var result DocStorage
-	err := c.docs.FindOne(ctx, filter).Decode(&result)
+	err := c.docs.FindOne(ctx, filter, opts).Decode(&result)
 	if err != nil {
 		return nil, err
 	}
@@ -156,6 +225,32 @@ func (c *Client) GetDocumentationByProcessedPath(ctx context.Context, processedP
 	return &result, nil
 }
 
+// ListProcessedDocumentation lists the current version of every processed document and
+// ref, ordered by path and ref, without its content
+func (c *Client) ListProcessedDocumentation(ctx context.Context, skip, limit int64) ([]DocStorage, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "processed_path", Value: 1}, {Key: "ref", Value: 1}}).
+		SetProjection(bson.D{{Key: "content", Value: 0}}).
+		SetSkip(skip).
+		SetLimit(limit)
+
+	cursor, err := c.docs.Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []DocStorage
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
 // UpdateDocumentation updates an existing document
 func (c *Client) UpdateDocumentation(ctx context.Context, doc *DocStorage) error {
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
@@ -0,0 +1,217 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	// ErrTrackedRepoNotFound is returned for unknown tracked repository IDs
+	ErrTrackedRepoNotFound = errors.New("tracked repository not found")
+
+	// ErrTrackedRepoExists is returned when a repository is already tracked at the same ref
+	ErrTrackedRepoExists = errors.New("repository is already tracked at this ref")
+)
+
+// TrackedRepo is a repository whose documentation is refreshed on a schedule
+type TrackedRepo struct {
+	ID              bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	Owner           string        `bson:"owner" json:"owner"`
+	Repo            string        `bson:"repo" json:"repo"`
+	Ref             string        `bson:"ref" json:"ref,omitempty"` // Empty for the default branch
+	IntervalMinutes int           `bson:"interval_minutes" json:"interval_minutes"`
+	NextRunAt       time.Time     `bson:"next_run_at" json:"next_run_at"`
+
+	// Outcome of the last scheduled refresh
+	LastCheckedAt   *time.Time `bson:"last_checked_at,omitempty" json:"last_checked_at,omitempty"`
+	LastRefreshedAt *time.Time `bson:"last_refreshed_at,omitempty" json:"last_refreshed_at,omitempty"`
+	LastCommitSHA   string     `bson:"last_commit_sha,omitempty" json:"last_commit_sha,omitempty"`
+	LastJobID       string     `bson:"last_job_id,omitempty" json:"last_job_id,omitempty"`
+	LastError       string     `bson:"last_error,omitempty" json:"last_error,omitempty"`
+
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Interval returns the time between two refreshes of the repository
+func (t *TrackedRepo) Interval() time.Duration {
+	return time.Duration(t.IntervalMinutes) * time.Minute
+}
+
+// ensureTrackedRepoIndexes makes a repository trackable once per ref and due repositories quick to find
+func (c *Client) ensureTrackedRepoIndexes(ctx context.Context) error {
+	_, err := c.tracked.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "owner", Value: 1}, {Key: "repo", Value: 1}, {Key: "ref", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "next_run_at", Value: 1}},
+		},
+	})
+	return err
+}
+
+// CreateTrackedRepo starts tracking a repository and sets the ID of repo
+func (c *Client) CreateTrackedRepo(ctx context.Context, repo *TrackedRepo) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	now := time.Now()
+	repo.ID = bson.NewObjectID()
+	repo.CreatedAt, repo.UpdatedAt = now, now
+
+	if _, err := c.tracked.InsertOne(ctx, repo); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrTrackedRepoExists
+		}
+		return err
+	}
+	return nil
+}
+
+// ListTrackedRepos lists the tracked repositories ordered by owner, repo and ref
+func (c *Client) ListTrackedRepos(ctx context.Context) ([]TrackedRepo, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "owner", Value: 1}, {Key: "repo", Value: 1}, {Key: "ref", Value: 1}})
+	cursor, err := c.tracked.Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	results := []TrackedRepo{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetTrackedRepo retrieves a tracked repository by ID
+func (c *Client) GetTrackedRepo(ctx context.Context, id string) (*TrackedRepo, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrTrackedRepoNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var result TrackedRepo
+	if err := c.tracked.FindOne(ctx, bson.D{{Key: "_id", Value: objectID}}).Decode(&result); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrTrackedRepoNotFound
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateTrackedRepo saves the ref, interval, next run and last indexed commit of a tracked repository
+func (c *Client) UpdateTrackedRepo(ctx context.Context, repo *TrackedRepo) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	repo.UpdatedAt = time.Now()
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "ref", Value: repo.Ref},
+		{Key: "interval_minutes", Value: repo.IntervalMinutes},
+		{Key: "next_run_at", Value: repo.NextRunAt},
+		{Key: "last_commit_sha", Value: repo.LastCommitSHA},
+		{Key: "updated_at", Value: repo.UpdatedAt},
+	}}}
+
+	result, err := c.tracked.UpdateByID(ctx, repo.ID, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrTrackedRepoExists
+		}
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrTrackedRepoNotFound
+	}
+	return nil
+}
+
+// DeleteTrackedRepo stops tracking a repository
+func (c *Client) DeleteTrackedRepo(ctx context.Context, id string) error {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrTrackedRepoNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	result, err := c.tracked.DeleteOne(ctx, bson.D{{Key: "_id", Value: objectID}})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrTrackedRepoNotFound
+	}
+	return nil
+}
+
+// DueTrackedRepos lists up to limit tracked repositories whose next run is at or before now, most overdue first
+func (c *Client) DueTrackedRepos(ctx context.Context, now time.Time, limit int) ([]TrackedRepo, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	filter := bson.D{{Key: "next_run_at", Value: bson.D{{Key: "$lte", Value: now}}}}
+	opts := options.Find().SetSort(bson.D{{Key: "next_run_at", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := c.tracked.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []TrackedRepo
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ClaimTrackedRepo moves the next run of a due repository to nextRunAt, unless another
+// instance did it first since repo was read. It reports whether the run was claimed.
+func (c *Client) ClaimTrackedRepo(ctx context.Context, repo *TrackedRepo, nextRunAt time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	filter := bson.D{{Key: "_id", Value: repo.ID}, {Key: "next_run_at", Value: repo.NextRunAt}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "next_run_at", Value: nextRunAt}}}}
+	result, err := c.tracked.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	if result.ModifiedCount == 0 {
+		return false, nil
+	}
+	repo.NextRunAt = nextRunAt
+	return true, nil
+}
+
+// SaveTrackedRepoStatus saves the outcome of the last refresh of a tracked repository
+func (c *Client) SaveTrackedRepoStatus(ctx context.Context, repo *TrackedRepo) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "last_checked_at", Value: repo.LastCheckedAt},
+		{Key: "last_refreshed_at", Value: repo.LastRefreshedAt},
+		{Key: "last_commit_sha", Value: repo.LastCommitSHA},
+		{Key: "last_job_id", Value: repo.LastJobID},
+		{Key: "last_error", Value: repo.LastError},
+	}}}
+	_, err := c.tracked.UpdateByID(ctx, repo.ID, update)
+	return err
+}
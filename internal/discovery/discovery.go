@@ -0,0 +1,137 @@
+// Package discovery holds the documentation file selection and fetching logic
+// shared by the documentation sources that can list a whole repository tree.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dtomacheski/extract-data-go/internal/models"
+)
+
+// CommonDocDirs are the folders documentation usually lives in, in order of preference
+var CommonDocDirs = []string{
+	"docs",          // Next.js, Vue, etc
+	"src/content",   // React.dev
+	"src/docs",      // tailwind.css
+	"Documentation", // Swift, some Apple projects
+	"documentation",
+	"doc",
+}
+
+// ErrNoDocumentation is returned when no documentation file could be retrieved
+var ErrNoDocumentation = errors.New("no documentation content could be successfully retrieved")
+
+// IsMarkdownFile checks for markdown file extensions
+func IsMarkdownFile(filename string) bool {
+	ext := strings.ToLower(path.Ext(filename))
+	return ext == ".md" || ext == ".mdx"
+}
+
+// SelectDocPaths picks the documentation files among the file paths of a repository tree.
+// With a subPath, the markdown files under it are returned, or subPath itself when it is
+// a markdown file. Otherwise the markdown files of the first common documentation folder
+// found are returned, falling back to every markdown file of the repository.
+func SelectDocPaths(paths []string, subPath string) []string {
+	subPath = strings.Trim(subPath, "/")
+	if subPath != "" {
+		if IsMarkdownFile(subPath) {
+			for _, p := range paths {
+				if p == subPath {
+					return []string{p}
+				}
+			}
+			return nil
+		}
+		return markdownUnder(paths, subPath)
+	}
+
+	for _, dir := range CommonDocDirs {
+		if docPaths := markdownUnder(paths, dir); len(docPaths) > 0 {
+			return docPaths
+		}
+	}
+
+	return markdownUnder(paths, "")
+}
+
+// markdownUnder returns the markdown files under dir, sorted by path
+func markdownUnder(paths []string, dir string) []string {
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+
+	var docPaths []string
+	for _, p := range paths {
+		if strings.HasPrefix(p, prefix) && IsMarkdownFile(p) {
+			docPaths = append(docPaths, p)
+		}
+	}
+	sort.Strings(docPaths)
+	return docPaths
+}
+
+// FetchFunc fetches a single documentation file
+type FetchFunc func(ctx context.Context, path string) (*models.Documentation, error)
+
+// FetchDocuments fetches the given paths with at most concurrencyLimit requests in flight.
+// Files that fail are logged and skipped; an error is only returned when none succeed.
+func FetchDocuments(ctx context.Context, paths []string, concurrencyLimit int, fetch FetchFunc) ([]models.Documentation, error) {
+	if concurrencyLimit <= 0 {
+		concurrencyLimit = 1
+	}
+
+	var (
+		wg            sync.WaitGroup
+		mu            sync.Mutex
+		documentation = []models.Documentation{}
+		firstErr      error
+		semaphore     = make(chan struct{}, concurrencyLimit)
+	)
+
+	for _, p := range paths {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(p string) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			doc, err := fetch(ctx, p)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Printf("Error fetching content for %s: %v\n", p, err)
+				if firstErr == nil {
+					firstErr = fmt.Errorf("error fetching content for %s: %w", p, err)
+				}
+				return
+			}
+			if doc != nil {
+				documentation = append(documentation, *doc)
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	if len(documentation) == 0 {
+		if firstErr != nil {
+			return nil, fmt.Errorf("failed to fetch documentation content: %w", firstErr)
+		}
+		return nil, ErrNoDocumentation
+	}
+
+	sort.Slice(documentation, func(i, j int) bool {
+		return documentation[i].Path < documentation[j].Path
+	})
+	return documentation, nil
+}
@@ -0,0 +1,42 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dtomacheski/extract-data-go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSelectDocPaths tests documentation file selection from a repository tree
+func TestSelectDocPaths(t *testing.T) {
+	paths := []string{"README.md", "main.go", "docs/b.md", "docs/a.mdx", "docs/img.png", "guides/intro.md"}
+
+	assert.Equal(t, []string{"docs/a.mdx", "docs/b.md"}, SelectDocPaths(paths, ""))
+	assert.Equal(t, []string{"guides/intro.md"}, SelectDocPaths(paths, "/guides/"))
+	assert.Equal(t, []string{"README.md"}, SelectDocPaths(paths, "README.md"))
+	assert.Empty(t, SelectDocPaths(paths, "missing.md"))
+
+	// Without a documentation folder every markdown file is used
+	assert.Equal(t, []string{"README.md", "guides/intro.md"}, SelectDocPaths([]string{"guides/intro.md", "README.md"}, ""))
+}
+
+// TestFetchDocuments tests that failed files are skipped unless all fail
+func TestFetchDocuments(t *testing.T) {
+	fetch := func(ctx context.Context, path string) (*models.Documentation, error) {
+		if path == "bad.md" {
+			return nil, errors.New("boom")
+		}
+		return &models.Documentation{Path: path}, nil
+	}
+
+	docs, err := FetchDocuments(context.Background(), []string{"b.md", "bad.md", "a.md"}, 2, fetch)
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "a.md", docs[0].Path)
+
+	_, err = FetchDocuments(context.Background(), []string{"bad.md"}, 2, fetch)
+	assert.ErrorContains(t, err, "boom")
+}
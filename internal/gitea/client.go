@@ -0,0 +1,274 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dtomacheski/extract-data-go/internal/discovery"
+	"github.com/dtomacheski/extract-data-go/internal/github"
+	"github.com/dtomacheski/extract-data-go/internal/models"
+)
+
+// pageSize is the number of items requested per page. Gitea caps it with
+// its MAX_RESPONSE_ITEMS setting, 50 by default.
+const pageSize = 50
+
+// maxPages bounds how many pages of a paginated listing are fetched
+const maxPages = 50
+
+// Client represents a Gitea or Forgejo REST API (v1) client
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+	timeout    time.Duration
+}
+
+// NewClient creates a new API client for the Gitea or Forgejo instance at baseURL.
+// The token is optional for public repositories.
+func NewClient(baseURL, token string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{},
+		timeout:    timeout,
+	}
+}
+
+// repository is the subset of a Gitea repository used by the client
+type repository struct {
+	ID            int64     `json:"id"`
+	Name          string    `json:"name"`
+	FullName      string    `json:"full_name"`
+	Description   string    `json:"description"`
+	StarsCount    int       `json:"stars_count"`
+	ForksCount    int       `json:"forks_count"`
+	Language      string    `json:"language"`
+	Topics        []string  `json:"topics"`
+	DefaultBranch string    `json:"default_branch"`
+	HTMLURL       string    `json:"html_url"`
+	Website       string    `json:"website"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// GetRepository fetches a repository by owner and name
+func (c *Client) GetRepository(ctx context.Context, owner, repo string) (*models.Repository, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var r repository
+	if err := c.getJSON(ctx, repoPath(owner, repo), nil, &r); err != nil {
+		return nil, err
+	}
+
+	return &models.Repository{
+		ID:               r.ID,
+		Name:             r.Name,
+		FullName:         r.FullName,
+		Description:      r.Description,
+		Stars:            r.StarsCount,
+		Forks:            r.ForksCount,
+		Language:         r.Language,
+		Topics:           r.Topics,
+		DefaultBranch:    r.DefaultBranch,
+		CreatedAt:        r.CreatedAt,
+		UpdatedAt:        r.UpdatedAt,
+		URL:              c.baseURL + "/api/v1" + repoPath(owner, repo),
+		HTMLURL:          r.HTMLURL,
+		DocumentationURL: r.Website,
+	}, nil
+}
+
+// ListTags lists the tag names of a repository, newest first
+func (c *Client) ListTags(ctx context.Context, owner, repo string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var names []string
+	for page := 1; page <= maxPages; page++ {
+		var tags []struct {
+			Name string `json:"name"`
+		}
+		query := url.Values{"page": {strconv.Itoa(page)}, "limit": {strconv.Itoa(pageSize)}}
+		if err := c.getJSON(ctx, repoPath(owner, repo)+"/tags", query, &tags); err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			names = append(names, tag.Name)
+		}
+		if len(tags) < pageSize {
+			break
+		}
+	}
+
+	return names, nil
+}
+
+// GetRepositoryDocumentationInPath fetches the documentation of a repository at ref, or at
+// defaultBranch when ref is empty. When subPath is set only the markdown files under it are fetched.
+func (c *Client) GetRepositoryDocumentationInPath(ctx context.Context, owner, repo, defaultBranch, ref, subPath string, concurrencyLimit int) ([]models.Documentation, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	if ref == "" {
+		ref = defaultBranch
+	}
+	if ref == "" {
+		return nil, fmt.Errorf("default branch not provided for repository %s/%s and no specific ref given", owner, repo)
+	}
+
+	// Resolve the ref to a commit so that every document comes from the same revision
+	var commits []struct {
+		SHA string `json:"sha"`
+	}
+	query := url.Values{"sha": {ref}, "limit": {"1"}, "stat": {"false"}}
+	if err := c.getJSON(ctx, repoPath(owner, repo)+"/commits", query, &commits); err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("ref '%s' not found in %s/%s", ref, owner, repo)
+	}
+	commitSHA := commits[0].SHA
+	log.Printf("Resolved ref '%s' of %s/%s to commit %s", ref, owner, repo, commitSHA)
+
+	blobSHAs := make(map[string]string)
+	var paths []string
+	for page := 1; page <= maxPages; page++ {
+		var tree struct {
+			Tree []struct {
+				Path string `json:"path"`
+				Type string `json:"type"`
+				SHA  string `json:"sha"`
+			} `json:"tree"`
+			Truncated  bool `json:"truncated"`
+			TotalCount int  `json:"total_count"`
+		}
+		query := url.Values{"recursive": {"true"}, "page": {strconv.Itoa(page)}, "per_page": {"1000"}}
+		if err := c.getJSON(ctx, repoPath(owner, repo)+"/git/trees/"+url.PathEscape(commitSHA), query, &tree); err != nil {
+			return nil, err
+		}
+		for _, entry := range tree.Tree {
+			if entry.Type == "blob" {
+				paths = append(paths, entry.Path)
+				blobSHAs[entry.Path] = entry.SHA
+			}
+		}
+		if !tree.Truncated || len(tree.Tree) == 0 {
+			break
+		}
+	}
+
+	docPaths := discovery.SelectDocPaths(paths, subPath)
+	if len(docPaths) == 0 {
+		log.Printf("No documentation files found for %s/%s on ref '%s' (path '%s').\n", owner, repo, ref, subPath)
+		return nil, github.ErrNoDocs
+	}
+
+	webURL := c.baseURL + "/" + owner + "/" + repo
+	log.Printf("Fetching content for %d documentation paths for %s/%s from ref '%s' using concurrency %d...\n", len(docPaths), owner, repo, ref, concurrencyLimit)
+	return discovery.FetchDocuments(ctx, docPaths, concurrencyLimit, func(ctx context.Context, p string) (*models.Documentation, error) {
+		content, err := c.get(ctx, repoPath(owner, repo)+"/raw/"+escapePath(p), url.Values{"ref": {commitSHA}})
+		if err != nil {
+			return nil, err
+		}
+
+		return &models.Documentation{
+			RepoName:    owner + "/" + repo,
+			Path:        p,
+			Content:     string(content),
+			ContentType: "file",
+			Size:        len(content),
+			SHA:         blobSHAs[p],
+			URL:         webURL + "/src/commit/" + commitSHA + "/" + escapePath(p),
+			Ref:         ref,
+			CommitSHA:   commitSHA,
+		}, nil
+	})
+}
+
+// repoPath returns the API path of a repository
+func repoPath(owner, repo string) string {
+	return "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+}
+
+// escapePath escapes each segment of a file path
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// getJSON requests path and decodes the JSON response into v
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, v interface{}) error {
+	body, err := c.get(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to decode Gitea response: %w", err)
+	}
+	return nil
+}
+
+// get performs an authenticated GET request against the API and returns the response body
+func (c *Client) get(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	endpoint := c.baseURL + "/api/v1" + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, processGiteaError(resp.StatusCode, resp.Header, body)
+	}
+
+	return body, nil
+}
+
+// processGiteaError converts an error response to the errors used by the other sources
+func processGiteaError(statusCode int, header http.Header, body []byte) error {
+	switch statusCode {
+	case http.StatusNotFound:
+		return github.ErrNotFound
+	case http.StatusUnauthorized:
+		return fmt.Errorf("%w: invalid Gitea token", github.ErrUnauthorized)
+	case http.StatusForbidden:
+		return fmt.Errorf("%w: %s", github.ErrForbidden, strings.TrimSpace(string(body)))
+	case http.StatusTooManyRequests:
+		rateErr := &github.RateLimitError{}
+		if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds > 0 {
+			rateErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return rateErr
+	}
+	return fmt.Errorf("Gitea API error (status %d): %s", statusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,77 @@
+package gitea
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer stands in for the API of a Gitea or Forgejo instance
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	handle := func(path string, handler http.HandlerFunc) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+			handler(w, r)
+		})
+	}
+
+	handle("/api/v1/repos/team/lib", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 9, "name": "lib", "full_name": "team/lib", "default_branch": "main", "stars_count": 4, "html_url": "https://git.example.com/team/lib"}`))
+	})
+	handle("/api/v1/repos/team/lib/tags", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"name": "v1.1.0"}, {"name": "v1.0.0"}]`))
+	})
+	handle("/api/v1/repos/team/lib/commits", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "v1.1.0", r.URL.Query().Get("sha"))
+		w.Write([]byte(`[{"sha": "def456"}]`))
+	})
+	handle("/api/v1/repos/team/lib/git/trees/def456", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"sha": "def456", "truncated": false, "tree": [
+			{"path": "docs", "type": "tree", "sha": "t1"},
+			{"path": "docs/guide/setup.md", "type": "blob", "sha": "s1"},
+			{"path": "docs/api.md", "type": "blob", "sha": "s2"},
+			{"path": "README.md", "type": "blob", "sha": "s3"}
+		]}`))
+	})
+	handle("/api/v1/repos/team/lib/raw/docs/guide/setup.md", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "def456", r.URL.Query().Get("ref"))
+		w.Write([]byte("# Setup\n"))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestGetRepositoryDocumentation tests repository, tag and documentation fetching
+func TestGetRepositoryDocumentation(t *testing.T) {
+	server := newTestServer(t)
+	client := NewClient(server.URL, "secret", 5*time.Second)
+	ctx := context.Background()
+
+	repo, err := client.GetRepository(ctx, "team", "lib")
+	require.NoError(t, err)
+	assert.Equal(t, "team/lib", repo.FullName)
+	assert.Equal(t, 4, repo.Stars)
+
+	tags, err := client.ListTags(ctx, "team", "lib")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.1.0", "v1.0.0"}, tags)
+
+	docs, err := client.GetRepositoryDocumentationInPath(ctx, "team", "lib", "main", "v1.1.0", "docs/guide", 2)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "docs/guide/setup.md", docs[0].Path)
+	assert.Equal(t, "# Setup\n", docs[0].Content)
+	assert.Equal(t, "v1.1.0", docs[0].Ref)
+	assert.Equal(t, server.URL+"/team/lib/src/commit/def456/docs/guide/setup.md", docs[0].URL)
+
+	_, err = client.GetRepository(ctx, "team", "missing")
+	assert.EqualError(t, err, "repository not found")
+}
@@ -0,0 +1,267 @@
+package github
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/go-github/v53/github"
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
+)
+
+// installationTokenEarlyExpiry renews installation tokens this long before they expire
+const installationTokenEarlyExpiry = 5 * time.Minute
+
+// installationRefreshInterval limits how often the installations of the app are listed again
+// to look for an owner without a known installation
+const installationRefreshInterval = time.Minute
+
+// installationListTimeout bounds a listing of the installations, shared by every caller waiting for it
+const installationListTimeout = 30 * time.Second
+
+// ownerContextKey carries the repository owner of requests whose URL does not name it
+type ownerContextKey struct{}
+
+// withOwner records the repository owner a request is made for, used to pick its installation
+func withOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, ownerContextKey{}, owner)
+}
+
+// App authenticates as a GitHub App: a JWT signed with the app's private key is exchanged
+// for installation tokens, one per installation, renewed automatically before they expire
+type App struct {
+	appID  int64
+	key    *rsa.PrivateKey
+	client *github.Client
+
+	mu            sync.Mutex
+	installations map[string]int64 // Lowercase account login -> installation ID
+	listedAt      time.Time
+	tokens        map[int64]oauth2.TokenSource
+
+	// listing shares one listing of the installations between the owners looked up
+	// meanwhile; it runs without holding mu so that known owners are not blocked
+	listing singleflight.Group
+}
+
+// NewApp creates a GitHub App authenticator from the app ID and its PEM-encoded private key.
+// An empty baseURL uses https://api.github.com/.
+func NewApp(appID int64, privateKeyPEM []byte, baseURL string) (*App, error) {
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub App private key: %w", err)
+	}
+
+	app := &App{
+		appID:         appID,
+		key:           key,
+		installations: map[string]int64{},
+		tokens:        map[int64]oauth2.TokenSource{},
+	}
+
+	app.client = github.NewClient(&http.Client{Transport: &appJWTTransport{app: app, base: http.DefaultTransport}})
+	if baseURL != "" {
+		if !strings.HasSuffix(baseURL, "/") {
+			baseURL += "/"
+		}
+		if app.client.BaseURL, err = url.Parse(baseURL); err != nil {
+			return nil, fmt.Errorf("invalid GitHub API URL: %w", err)
+		}
+	}
+	return app, nil
+}
+
+// jwt returns a short-lived JWT identifying the app, as required by the /app endpoints
+func (a *App) jwt() (string, error) {
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		// Backdated to allow for clock drift, and below the 10 minute maximum
+		IssuedAt:  jwt.NewNumericDate(now.Add(-time.Minute)),
+		ExpiresAt: jwt.NewNumericDate(now.Add(9 * time.Minute)),
+		Issuer:    strconv.FormatInt(a.appID, 10),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(a.key)
+}
+
+// Token returns an installation token for the installation of the app on an owner's account.
+// The second return value is false when the app is not installed there.
+func (a *App) Token(ctx context.Context, owner string) (string, bool, error) {
+	id, ok, err := a.installationID(ctx, owner)
+	if err != nil || !ok {
+		return "", false, err
+	}
+
+	a.mu.Lock()
+	src, ok := a.tokens[id]
+	if !ok {
+		src = oauth2.ReuseTokenSourceWithExpiry(nil, &installationTokenSource{app: a, id: id}, installationTokenEarlyExpiry)
+		a.tokens[id] = src
+	}
+	a.mu.Unlock()
+
+	token, err := src.Token()
+	if err != nil {
+		return "", false, err
+	}
+	return token.AccessToken, true, nil
+}
+
+// installationID returns the installation of the app on an owner's account, listing the
+// installations again when the owner is unknown and the last listing is not too recent
+func (a *App) installationID(ctx context.Context, owner string) (int64, bool, error) {
+	owner = strings.ToLower(owner)
+
+	a.mu.Lock()
+	id, ok := a.installations[owner]
+	recent := time.Since(a.listedAt) < installationRefreshInterval
+	a.mu.Unlock()
+	if ok {
+		return id, true, nil
+	}
+	if recent {
+		return 0, false, nil
+	}
+
+	// The listing is shared by the concurrent callers, so it does not end with the first
+	// one: it runs on its own timeout while each caller waits on its own context
+	listing := a.listing.DoChan("installations", func() (interface{}, error) {
+		listCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), installationListTimeout)
+		defer cancel()
+		return a.listInstallations(listCtx)
+	})
+	select {
+	case <-ctx.Done():
+		return 0, false, ctx.Err()
+	case result := <-listing:
+		if result.Err != nil {
+			return 0, false, result.Err
+		}
+		id, ok = result.Val.(map[string]int64)[owner]
+		return id, ok, nil
+	}
+}
+
+// listInstallations lists the accounts the app is installed on and records them
+func (a *App) listInstallations(ctx context.Context) (map[string]int64, error) {
+	installations := map[string]int64{}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := a.client.Apps.ListInstallations(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list GitHub App installations: %w", processGitHubError(err))
+		}
+		for _, installation := range page {
+			installations[strings.ToLower(installation.GetAccount().GetLogin())] = installation.GetID()
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	log.Printf("GitHub App %d is installed on %d accounts", a.appID, len(installations))
+	a.mu.Lock()
+	a.installations, a.listedAt = installations, time.Now()
+	a.mu.Unlock()
+	return installations, nil
+}
+
+// installationTokenSource exchanges the app JWT for a token of one installation
+type installationTokenSource struct {
+	app *App
+	id  int64
+}
+
+// Token implements oauth2.TokenSource
+func (s *installationTokenSource) Token() (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	token, _, err := s.app.client.Apps.CreateInstallationToken(ctx, s.id, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create installation token: %w", processGitHubError(err))
+	}
+	log.Printf("Created GitHub App installation token for installation %d, expires at %s", s.id, token.GetExpiresAt().Format(time.RFC3339))
+	return &oauth2.Token{AccessToken: token.GetToken(), TokenType: "token", Expiry: token.GetExpiresAt().Time}, nil
+}
+
+// appJWTTransport authenticates requests to the /app endpoints with the app JWT
+type appJWTTransport struct {
+	app  *App
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *appJWTTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.app.jwt()
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(req)
+}
+
+// appTransport authenticates requests for owners the app is installed on with their
+// installation token and passes the others on to the fallback (token pool or anonymous)
+type appTransport struct {
+	app      *App
+	base     http.RoundTripper
+	fallback http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *appTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.app == nil {
+		return t.fallback.RoundTrip(req)
+	}
+
+	owner := requestOwner(req)
+	if owner == "" {
+		return t.fallback.RoundTrip(req)
+	}
+
+	token, ok, err := t.app.Token(req.Context(), owner)
+	if err != nil {
+		log.Printf("GitHub App authentication for %s failed, using the fallback credentials: %v", owner, err)
+	}
+	if !ok {
+		return t.fallback.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "token "+token)
+	return t.base.RoundTrip(req)
+}
+
+// requestOwner returns the repository owner a request is made for: from the context,
+// the /repos/{owner}/ path or a repo:{owner}/{repo} search qualifier
+func requestOwner(req *http.Request) string {
+	if owner, ok := req.Context().Value(ownerContextKey{}).(string); ok {
+		return owner
+	}
+
+	path := req.URL.Path
+	if i := strings.Index(path, "/repos/"); i >= 0 {
+		owner, _, _ := strings.Cut(path[i+len("/repos/"):], "/")
+		return owner
+	}
+	if strings.Contains(path, "/search/") {
+		for _, field := range strings.Fields(req.URL.Query().Get("q")) {
+			if repo, ok := strings.CutPrefix(field, "repo:"); ok {
+				owner, _, _ := strings.Cut(repo, "/")
+				return owner
+			}
+		}
+	}
+	return ""
+}
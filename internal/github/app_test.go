@@ -0,0 +1,164 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAppAuthentication tests installation token exchange, renewal and the per-owner fallback
+func TestAppAuthentication(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	// Tokens expire within the early expiry window, so each use renews them
+	expiresIn := 2 * time.Minute
+	issued := 0
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	requireAppJWT := func(r *http.Request) {
+		claims := &jwt.RegisteredClaims{}
+		_, err := jwt.ParseWithClaims(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), claims, func(*jwt.Token) (interface{}, error) {
+			return &key.PublicKey, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "42", claims.Issuer)
+	}
+	mux.HandleFunc("/app/installations", func(w http.ResponseWriter, r *http.Request) {
+		requireAppJWT(r)
+		fmt.Fprint(w, `[{"id":7,"account":{"login":"Acme"}}]`)
+	})
+	mux.HandleFunc("/app/installations/7/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		requireAppJWT(r)
+		issued++
+		fmt.Fprintf(w, `{"token":"installation-%d","expires_at":%q}`, issued, time.Now().Add(expiresIn).Format(time.RFC3339))
+	})
+	mux.HandleFunc("/repos/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"full_name":%q,"description":%q}`, strings.TrimPrefix(r.URL.Path, "/repos/"), r.Header.Get("Authorization"))
+	})
+
+	app, err := NewApp(42, keyPEM, server.URL)
+	require.NoError(t, err)
+
+	client := NewClientWithTokens([]string{"personal"}, 5*time.Second)
+	client.client.BaseURL, _ = url.Parse(server.URL + "/")
+	client.UseApp(app)
+	ctx := context.Background()
+
+	repository, err := client.GetRepository(ctx, "acme", "private")
+	require.NoError(t, err)
+	assert.Equal(t, "token installation-1", repository.Description)
+
+	repository, err = client.GetRepository(ctx, "acme", "private")
+	require.NoError(t, err)
+	assert.Equal(t, "token installation-2", repository.Description)
+
+	// Long-lived tokens are reused
+	expiresIn = time.Hour
+	_, err = client.GetRepository(ctx, "acme", "private")
+	require.NoError(t, err)
+	repository, err = client.GetRepository(ctx, "acme", "private")
+	require.NoError(t, err)
+	assert.Equal(t, "token installation-3", repository.Description)
+	assert.Equal(t, 3, issued)
+
+	// Owners without an installation use the personal token
+	repository, err = client.GetRepository(ctx, "other", "public")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer personal", repository.Description)
+}
+
+// TestApp_ListingDoesNotBlock tests that a slow listing of the installations does not block
+// the owners already known, and is shared by the unknown owners looked up meanwhile even
+// when the caller that started it gives up
+func TestApp_ListingDoesNotBlock(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var listings atomic.Int32
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/app/installations", func(w http.ResponseWriter, r *http.Request) {
+		if listings.Add(1) > 1 {
+			<-release
+		}
+		fmt.Fprint(w, `[{"id":7,"account":{"login":"acme"}},{"id":8,"account":{"login":"late"}}]`)
+	})
+	mux.HandleFunc("/app/installations/7/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"token":"installation-7","expires_at":%q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+	})
+
+	app, err := NewApp(42, keyPEM, server.URL)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, ok, err := app.Token(ctx, "acme")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// Unknown owners list the installations again once the refresh interval passed
+	app.mu.Lock()
+	delete(app.installations, "late")
+	app.listedAt = time.Time{}
+	app.mu.Unlock()
+
+	// The first caller starts the listing and gives up before it completes
+	firstCtx, cancelFirst := context.WithCancel(ctx)
+	firstDone := make(chan error, 1)
+	go func() {
+		_, _, err := app.installationID(firstCtx, "late")
+		firstDone <- err
+	}()
+	require.Eventually(t, func() bool { return listings.Load() == 2 }, time.Second, time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, ok, err := app.installationID(ctx, "late")
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, int64(8), id)
+		}()
+	}
+
+	token, ok, err := app.Token(ctx, "acme")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "installation-7", token)
+
+	// The shared listing outlives the caller that started it
+	cancelFirst()
+	assert.ErrorIs(t, <-firstDone, context.Canceled)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(2), listings.Load())
+}
+
+// TestNewApp_InvalidKey tests that malformed private keys are rejected
+func TestNewApp_InvalidKey(t *testing.T) {
+	_, err := NewApp(42, []byte("not a key"), "")
+	assert.Error(t, err)
+}
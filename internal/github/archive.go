@@ -0,0 +1,160 @@
+package github
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/dtomacheski/extract-data-go/internal/models"
+	"github.com/dtomacheski/extract-data-go/internal/progress"
+	"github.com/google/go-github/v53/github"
+)
+
+// DefaultArchiveFetchThreshold is the number of documentation files above which
+// the repository archive is downloaded instead of fetching each file
+const DefaultArchiveFetchThreshold = 50
+
+// maxArchiveFileSize is the largest documentation file read from an archive
+const maxArchiveFileSize = 10 << 20
+
+// SetArchiveFetchThreshold sets the number of documentation files above which a repository
+// is fetched as a single tarball of the resolved commit. Zero or less disables archive fetches.
+func (c *Client) SetArchiveFetchThreshold(threshold int) {
+	c.archiveThreshold = threshold
+}
+
+// useArchive reports whether fileCount documentation files should be fetched from the archive
+func (c *Client) useArchive(fileCount int) bool {
+	return c.archiveThreshold > 0 && fileCount > c.archiveThreshold
+}
+
+// fetchDocumentsFromArchive downloads the tarball of a commit once and keeps only the
+// given documentation paths, instead of one Contents API call per file
+func (c *Client) fetchDocumentsFromArchive(ctx context.Context, owner, repo, refToUse, commitSHA string, docPaths []string) ([]models.Documentation, error) {
+	log.Printf("Fetching %d documentation files for %s/%s from the tarball of commit %s", len(docPaths), owner, repo, commitSHA)
+
+	archiveURL, _, err := c.client.Repositories.GetArchiveLink(ctx, owner, repo, github.Tarball, &github.RepositoryContentGetOptions{Ref: commitSHA}, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get archive link: %w", processGitHubError(err))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, archiveURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download archive: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download archive: status %s", resp.Status)
+	}
+
+	contents, err := extractArchiveFiles(resp.Body, docPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	documentation := make([]models.Documentation, 0, len(contents))
+	var missing []string
+	for _, p := range docPaths {
+		content, ok := contents[p]
+		if !ok {
+			log.Printf("Documentation file %s missing from the archive of %s/%s", p, owner, repo)
+			missing = append(missing, p)
+			continue
+		}
+
+		documentation = append(documentation, models.Documentation{
+			RepoName:    fmt.Sprintf("%s/%s", owner, repo),
+			Path:        p,
+			Content:     string(content),
+			ContentType: "file",
+			Size:        len(content),
+			SHA:         gitBlobSHA(content),
+			URL:         fmt.Sprintf("https://github.com/%s/%s/blob/%s/%s", owner, repo, commitSHA, p),
+			Ref:         refToUse,
+			CommitSHA:   commitSHA,
+		})
+	}
+
+	if len(documentation) == 0 {
+		return nil, errNoDocContent
+	}
+
+	// Reported once the archive was read, as a failed download falls back to other strategies
+	for _, doc := range documentation {
+		progress.ReportFile(ctx, doc.Path, nil)
+	}
+	for _, p := range missing {
+		progress.ReportFile(ctx, p, errors.New("missing from the archive"))
+	}
+
+	log.Printf("Successfully retrieved content for %d documentation files from the archive of %s/%s", len(documentation), owner, repo)
+	return documentation, nil
+}
+
+// extractArchiveFiles streams a gzipped tarball and returns the content of the wanted
+// paths. GitHub archives put every file under a single top-level directory, which is
+// stripped before matching.
+func extractArchiveFiles(r io.Reader, paths []string) (map[string][]byte, error) {
+	wanted := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		wanted[p] = true
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	defer gz.Close()
+
+	contents := make(map[string][]byte, len(paths))
+	tr := tar.NewReader(gz)
+	for len(contents) < len(wanted) {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		_, p, ok := strings.Cut(header.Name, "/")
+		if !ok || !wanted[p] {
+			continue
+		}
+		if header.Size > maxArchiveFileSize {
+			log.Printf("Skipping %s from archive: %d bytes exceeds the size limit", p, header.Size)
+			continue
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from archive: %w", p, err)
+		}
+		contents[p] = content
+	}
+
+	return contents, nil
+}
+
+// gitBlobSHA computes the git blob SHA of a file, the same SHA the Contents API reports
+func gitBlobSHA(content []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
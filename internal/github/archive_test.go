@@ -0,0 +1,77 @@
+package github
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildTarball creates a gzipped tarball with files under a GitHub-style top-level directory
+func buildTarball(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "owner-repo-abc123/", Typeflag: tar.TypeDir, Mode: 0o755}))
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "owner-repo-abc123/" + name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+// TestFetchDocumentsFromArchive tests that a single tarball download yields the requested documents
+func TestFetchDocumentsFromArchive(t *testing.T) {
+	tarball := buildTarball(t, map[string]string{
+		"docs/a.md":   "# A\n",
+		"docs/b.mdx":  "# B\n",
+		"src/main.go": "package main\n",
+	})
+
+	requests := 0
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/repos/owner/repo/tarball/abc123", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Redirect(w, r, server.URL+"/codeload/abc123.tar.gz", http.StatusFound)
+	})
+	mux.HandleFunc("/codeload/abc123.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(tarball)
+	})
+
+	gh := github.NewClient(nil)
+	gh.BaseURL, _ = url.Parse(server.URL + "/")
+	client := &Client{client: gh, timeout: 5 * time.Second, archiveThreshold: 1}
+
+	assert.True(t, client.useArchive(2))
+	assert.False(t, client.useArchive(1))
+
+	docs, err := client.fetchDocumentsFromArchive(context.Background(), "owner", "repo", "main", "abc123", []string{"docs/a.md", "docs/b.mdx", "docs/missing.md"})
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, 2, requests)
+
+	assert.Equal(t, "docs/a.md", docs[0].Path)
+	assert.Equal(t, "# A\n", docs[0].Content)
+	assert.Equal(t, "main", docs[0].Ref)
+	assert.Equal(t, "abc123", docs[0].CommitSHA)
+	assert.Equal(t, "https://github.com/owner/repo/blob/abc123/docs/a.md", docs[0].URL)
+	// Same SHA as `git hash-object` for the content
+	assert.Equal(t, "7f3b95d297183eca8f6cf38ceaa253bee8c2d7cd", gitBlobSHA([]byte("# A\n")))
+}
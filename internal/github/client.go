@@ -10,29 +10,73 @@ import (
 	"sync"
 	"time"
 
+	"github.com/dtomacheski/extract-data-go/internal/discovery"
 	"github.com/dtomacheski/extract-data-go/internal/models"
+	"github.com/dtomacheski/extract-data-go/internal/progress"
 	"github.com/google/go-github/v53/github"
-	"golang.org/x/oauth2"
-	"path/filepath"
 )
 
 // Client represents a GitHub API client
 type Client struct {
 	client  *github.Client
 	timeout time.Duration
+
+	// archiveThreshold is the documentation file count above which the repository tarball is used
+	archiveThreshold int
+	// graphqlThreshold is the documentation file count above which blobs are batched through GraphQL
+	graphqlThreshold int
+	// authenticated is set when requests carry a token, which the GraphQL API requires
+	authenticated bool
+	// conditional revalidates stored responses once EnableConditionalRequests is called
+	conditional *conditionalTransport
+	// appAuth uses GitHub App installation tokens once UseApp is called
+	appAuth *appTransport
 }
 
-// NewClient creates a new GitHub API client with authentication
+// NewClient creates a new GitHub API client with authentication.
+// Without a token the client makes unauthenticated requests.
 func NewClient(token string, timeout time.Duration) *Client {
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
-	)
-	tc := oauth2.NewClient(context.Background(), ts)
+	return NewClientWithTokens([]string{token}, timeout)
+}
+
+// NewClientWithTokens creates a GitHub API client that spreads requests over a pool of
+// tokens, using the one with the most rate limit headroom for each request
+func NewClientWithTokens(tokens []string, timeout time.Duration) *Client {
+	conditional := &conditionalTransport{base: http.DefaultTransport}
+
+	pool := newTokenPool(tokens, conditional)
+	var fallback http.RoundTripper = conditional
+	if len(pool.tokens) > 0 {
+		fallback = pool
+	}
+	appAuth := &appTransport{base: conditional, fallback: fallback}
 
 	return &Client{
-		client:  github.NewClient(tc),
-		timeout: timeout,
+		client:           github.NewClient(&http.Client{Transport: appAuth}),
+		timeout:          timeout,
+		conditional:      conditional,
+		appAuth:          appAuth,
+		archiveThreshold: DefaultArchiveFetchThreshold,
+		graphqlThreshold: DefaultGraphQLFetchThreshold,
+		authenticated:    len(pool.tokens) > 0,
+	}
+}
+
+// UseApp authenticates requests for repositories of accounts the GitHub App is installed on
+// with installation tokens; other requests keep using the personal tokens, if any.
+// It must be called before the client is used.
+func (c *Client) UseApp(app *App) {
+	c.appAuth.app = app
+	c.authenticated = true
+}
+
+// operationContext bounds an operation made of many requests by the client timeout, unless
+// the caller set a deadline of its own: indexing jobs run under their longer job timeout
+func (c *Client) operationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
 	}
+	return context.WithTimeout(ctx, c.timeout)
 }
 
 // GetRepository fetches a GitHub repository by owner and repo name
@@ -48,14 +92,94 @@ func (c *Client) GetRepository(ctx context.Context, owner, repo string) (*models
 	return convertToRepositoryModel(repository), nil
 }
 
+// ResolveCommit returns the SHA of the commit a ref (the default branch when empty) points to.
+// Unchanged refs are answered from the stored response when conditional requests are enabled.
+func (c *Client) ResolveCommit(ctx context.Context, owner, repo, ref string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	if ref == "" {
+		ref = "HEAD"
+	}
+	sha, _, err := c.client.Repositories.GetCommitSHA1(ctx, owner, repo, ref, "")
+	if err != nil {
+		return "", processGitHubError(err)
+	}
+	return sha, nil
+}
+
 // GetRepositoryDocumentation fetches documentation for a repository with concurrency, targeting a specific ref (tag/branch).
 // If specificRef is empty, it defaults to the defaultBranchFromHandler.
 func (c *Client) GetRepositoryDocumentation(ctx context.Context, owner, repo, defaultBranchFromHandler, specificRef string, concurrencyLimit int) ([]models.Documentation, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	return c.GetRepositoryDocumentationInPath(ctx, owner, repo, defaultBranchFromHandler, specificRef, "", concurrencyLimit)
+}
+
+// DocumentationListing is the set of documentation files of a repository at a resolved commit
+type DocumentationListing struct {
+	Owner     string
+	Repo      string
+	Ref       string            // Branch, tag or commit the documentation was listed at
+	CommitSHA string            // Commit the ref resolved to; empty when it could not be resolved
+	Paths     []string          // Documentation file paths
+	BlobSHAs  map[string]string // Git blob SHA of each path, filled by ListDocumentation
+	Complete  bool              // False when the repository tree could not be listed entirely
+
+	rootTreeSHA string
+}
+
+// contentRef is the revision file contents are read at: the resolved commit, so that every
+// document matches the revision reported to clients even if the branch moves meanwhile
+func (l *DocumentationListing) contentRef() string {
+	if l.CommitSHA != "" {
+		return l.CommitSHA
+	}
+	return l.Ref
+}
+
+// GetRepositoryDocumentationInPath is GetRepositoryDocumentation scoped to a subtree of the repository.
+// When subPath is set, documentation discovery is skipped and every markdown file under subPath
+// (or subPath itself, if it is a file) is fetched.
+func (c *Client) GetRepositoryDocumentationInPath(ctx context.Context, owner, repo, defaultBranchFromHandler, specificRef, subPath string, concurrencyLimit int) ([]models.Documentation, error) {
+	listing, err := c.listDocumentation(ctx, owner, repo, defaultBranchFromHandler, specificRef, subPath)
+	if err != nil {
+		return nil, err
+	}
+	return c.FetchDocuments(ctx, listing, listing.Paths, concurrencyLimit)
+}
+
+// ListDocumentation resolves a ref (the default branch when empty) to a commit and lists its
+// documentation files together with their blob SHAs, without fetching any content. Comparing
+// blob SHAs against a previous listing tells which documents changed between two commits.
+func (c *Client) ListDocumentation(ctx context.Context, owner, repo, defaultBranch, ref string) (*DocumentationListing, error) {
+	listing, err := c.listDocumentation(ctx, owner, repo, defaultBranch, ref, "")
+	if err != nil {
+		return nil, err
+	}
+
+	listing.BlobSHAs = make(map[string]string, len(listing.Paths))
+	if listing.rootTreeSHA == "" {
+		return listing, nil
+	}
+
+	ctx, cancel := c.operationContext(ctx)
 	defer cancel()
 
-	// Define variable err at the function level so it's available throughout the function
-	var err error
+	// Paths missing from the tree keep an empty SHA and are always considered changed
+	entries, _, err := c.getDocBlobsFromTree(ctx, owner, repo, listing.rootTreeSHA)
+	if err != nil {
+		log.Printf("Error listing blob SHAs for %s/%s at %s: %v", owner, repo, listing.CommitSHA, err)
+		return listing, nil
+	}
+	for _, entry := range entries {
+		listing.BlobSHAs[entry.GetPath()] = entry.GetSHA()
+	}
+	return listing, nil
+}
+
+// listDocumentation resolves the ref to a commit and discovers the documentation paths
+func (c *Client) listDocumentation(ctx context.Context, owner, repo, defaultBranchFromHandler, specificRef, subPath string) (*DocumentationListing, error) {
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 
 	// Determine the ref to use (provided specificRef or defaultBranchFromHandler)
 	var refToUse string
@@ -75,37 +199,217 @@ func (c *Client) GetRepositoryDocumentation(ctx context.Context, owner, repo, de
 
 	log.Printf("Attempting to fetch documentation for %s/%s from ref '%s'", owner, repo, refToUse)
 
+	listing := &DocumentationListing{Owner: owner, Repo: repo, Ref: refToUse}
+
 	// Attempt to get the commit for the refToUse to get the tree SHA
-	var rootTreeSHA string
 	commit, _, err := c.client.Repositories.GetCommit(ctx, owner, repo, refToUse, nil)
 	if err != nil {
 		log.Printf("Error getting commit for ref %s in %s/%s: %v", refToUse, owner, repo, err)
 		// Proceed without tree SHA if commit fetch fails, relying on search code logic
 	} else if commit != nil && commit.Commit != nil && commit.Commit.Tree != nil && commit.Commit.Tree.SHA != nil {
-		rootTreeSHA = *commit.Commit.Tree.SHA
-		log.Printf("Successfully obtained root tree SHA: %s for ref %s", rootTreeSHA, refToUse)
+		listing.rootTreeSHA = *commit.Commit.Tree.SHA
+		listing.CommitSHA = commit.GetSHA()
+		log.Printf("Successfully obtained root tree SHA: %s for ref %s (commit %s)", listing.rootTreeSHA, refToUse, listing.CommitSHA)
 	} else {
 		log.Printf("Commit or tree SHA is nil for ref %s in %s/%s", refToUse, owner, repo)
 	}
 
-	var docPaths []string
-	searchInDocs := false
+	subPath = strings.Trim(subPath, "/")
+	if subPath != "" {
+		log.Printf("Restricting documentation of %s/%s to path '%s' on ref '%s'", owner, repo, subPath, refToUse)
+		progress.ReportStrategy(ctx, progress.StageDiscovery, "subtree")
+		listing.Paths, listing.Complete, err = c.listDocPathsInSubtree(ctx, owner, repo, subPath, listing.contentRef(), listing.rootTreeSHA)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		listing.Paths, listing.Complete = c.discoverDocPaths(ctx, owner, repo, refToUse, listing.rootTreeSHA)
+	}
+
+	// Check if any documentation files were found
+	if len(listing.Paths) == 0 {
+		log.Printf("No documentation files found for %s/%s on ref '%s' (path '%s').\n", owner, repo, refToUse, subPath)
+		return nil, ErrNoDocs
+	}
+
+	return listing, nil
+}
+
+// FetchDocuments reads the given paths of a listing at its resolved commit
+func (c *Client) FetchDocuments(ctx context.Context, listing *DocumentationListing, paths []string, concurrencyLimit int) ([]models.Documentation, error) {
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
+
+	documentation, err := c.fetchDocumentContents(ctx, listing.Owner, listing.Repo, listing.Ref, listing.CommitSHA, listing.contentRef(), paths, concurrencyLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Flag documents of a repository whose file listing could not be completed
+	if !listing.Complete {
+		log.Printf("Documentation listing of %s/%s on ref '%s' is incomplete", listing.Owner, listing.Repo, listing.Ref)
+		for i := range documentation {
+			documentation[i].PartialListing = true
+		}
+	}
+
+	return documentation, nil
+}
+
+// fetchDocumentContents reads the given paths with the strategy suited to their number:
+// a single archive download for large sets, batched GraphQL queries for medium-sized
+// ones and one Contents API call per file otherwise
+func (c *Client) fetchDocumentContents(ctx context.Context, owner, repo, refToUse, commitSHA, contentRef string, docPaths []string, concurrencyLimit int) ([]models.Documentation, error) {
+	progress.Report(ctx, progress.Event{Type: progress.PathsFound, Count: len(docPaths)})
+
+	// Large documentation sets are read from a single archive download of the commit
+	if commitSHA != "" && c.useArchive(len(docPaths)) {
+		progress.ReportStrategy(ctx, progress.StageFetch, "archive")
+		documentation, err := c.fetchDocumentsFromArchive(ctx, owner, repo, refToUse, commitSHA, docPaths)
+		if err == nil {
+			return documentation, nil
+		}
+		log.Printf("Archive fetch failed for %s/%s at %s: %v. Falling back to smaller requests.", owner, repo, commitSHA, err)
+	}
+
+	// Medium-sized documentation sets are read in batches of blobs through GraphQL
+	if commitSHA != "" && c.useGraphQL(len(docPaths)) {
+		progress.ReportStrategy(ctx, progress.StageFetch, "graphql")
+		documentation, err := c.fetchDocumentsWithGraphQL(ctx, owner, repo, refToUse, commitSHA, docPaths, concurrencyLimit)
+		if err == nil {
+			return documentation, nil
+		}
+		log.Printf("GraphQL fetch failed for %s/%s at %s: %v. Falling back to fetching each file.", owner, repo, commitSHA, err)
+	}
+
+	progress.ReportStrategy(ctx, progress.StageFetch, "contents")
+	return c.fetchDocuments(ctx, owner, repo, refToUse, commitSHA, contentRef, docPaths, concurrencyLimit)
+}
+
+// fetchDocuments downloads the given paths concurrently and converts them to documentation models
+func (c *Client) fetchDocuments(ctx context.Context, owner, repo, refToUse, commitSHA, contentRef string, docPaths []string, concurrencyLimit int) ([]models.Documentation, error) {
+	// 4. Fetch content for the determined docPaths
+	log.Printf("Fetching content for %d documentation paths for %s/%s from ref '%s' using concurrency %d...\n", len(docPaths), owner, repo, refToUse, concurrencyLimit)
+	var (
+		wg            sync.WaitGroup
+		mu            sync.Mutex
+		documentation = []models.Documentation{} // Initialize here instead of at the top
+		errChan       = make(chan error, len(docPaths))
+		semaphore     = make(chan struct{}, concurrencyLimit)
+	)
+
+	for _, path := range docPaths {
+		wg.Add(1)
+		semaphore <- struct{}{} // Acquire semaphore slot
+
+		go func(p string) {
+			defer func() {
+				<-semaphore // Release semaphore slot
+				wg.Done()
+			}()
+
+			// Fetch content at the resolved commit (or the ref when it could not be resolved)
+			doc, err := c.getFileContent(ctx, owner, repo, p, contentRef)
+			if err != nil {
+				progress.ReportFile(ctx, p, err)
+				// Log specific file fetch errors
+				log.Printf("Error fetching content for %s/%s path %s from ref '%s': %v\n", owner, repo, p, refToUse, err)
+				// Send a non-blocking error to avoid deadlock if channel buffer is full
+				select {
+				case errChan <- fmt.Errorf("error fetching content for %s: %w", p, err):
+				default:
+					log.Printf("Error channel full, discarding error for %s\n", p)
+				}
+				return
+			}
+
+			if doc == nil {
+				progress.ReportFile(ctx, p, errors.New("not found"))
+				log.Printf("Skipping nil content for path %s in %s/%s from ref '%s'\n", p, owner, repo, refToUse)
+				return // Skip if content fetching somehow returned nil without error
+			}
+
+			// Convert to model and add to result
+			content, err := doc.GetContent() // Handles base64 decoding
+			if err != nil {
+				progress.ReportFile(ctx, p, err)
+				log.Printf("Error getting/decoding content for %s from ref '%s': %v\n", p, refToUse, err)
+				select {
+				case errChan <- fmt.Errorf("error getting/decoding content for %s: %w", p, err):
+				default:
+					log.Printf("Error channel full, discarding content error for %s\n", p)
+				}
+				return
+			}
+
+			docModel := models.Documentation{
+				RepoID:      0, // Repository ID is not fetched in this function
+				RepoName:    fmt.Sprintf("%s/%s", owner, repo),
+				Path:        p,
+				Content:     content,
+				ContentType: doc.GetType(),
+				Size:        doc.GetSize(),
+				SHA:         doc.GetSHA(),
+				URL:         doc.GetHTMLURL(), // Use HTML URL for easier browser access if needed
+				Ref:         refToUse,
+				CommitSHA:   commitSHA,
+			}
+
+			mu.Lock()
+			documentation = append(documentation, docModel)
+			mu.Unlock()
+			progress.ReportFile(ctx, p, nil)
+		}(path)
+	}
+
+	wg.Wait()
+	close(errChan)
+
+	// Check for errors during fetch
+	var fetchErrors []string
+	var firstErr error
+	for err := range errChan {
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			fetchErrors = append(fetchErrors, err.Error())
+		}
+	}
+
+	if len(fetchErrors) > 0 {
+		// If we got *some* docs despite errors, return them but log the errors.
+		// If we got *no* docs and there were errors, return the error.
+		log.Printf("%d errors occurred during content fetch for %s/%s from ref '%s': %s\n", len(fetchErrors), owner, repo, refToUse, strings.Join(fetchErrors, "; "))
+		if len(documentation) == 0 {
+			return nil, fmt.Errorf("failed to fetch documentation content: %w", firstErr) // Return first error
+		}
+	}
 
-	// Define caminhos comuns de documentação em diferentes repositórios
-	commonDocPaths := []string{
-		"docs",          // Caminho padrão (Next.js, Vue, etc)
-		"src/content",   // React.dev
-		"src/docs",      // tailwind.css
-		"Documentation", // Swift, alguns projetos da Apple
-		"documentation", // Variação com letra minúscula
-		"doc",           // Alguns projetos antigos
+	if len(documentation) == 0 {
+		// This case now means either no paths were found initially, or all fetches failed.
+		log.Printf("No documentation content could be successfully retrieved for %s/%s from ref '%s'.\n", owner, repo, refToUse)
+		return nil, errNoDocContent
 	}
 
+	log.Printf("Successfully retrieved content for %d documentation files from %s/%s from ref '%s'\n", len(documentation), owner, repo, refToUse)
+	return documentation, nil
+}
+
+// discoverDocPaths looks for the documentation files of a repository: first in the
+// common documentation folders, then in documentation folders at the root, and
+// finally in the whole repository through the Git Tree or code search APIs.
+// It also reports whether the repository tree could be listed completely.
+func (c *Client) discoverDocPaths(ctx context.Context, owner, repo, refToUse, rootTreeSHA string) ([]string, bool) {
+	var docPaths []string
+	searchInDocs := false
+	listingComplete := true
+
 	// Variável para armazenar o caminho de documentação encontrado
 	var foundDocPath string
 
 	// 1. Verificar cada caminho possível de documentação
-	for _, path := range commonDocPaths {
+	for _, path := range discovery.CommonDocDirs {
 		log.Printf("Verificando existência da pasta %s para %s/%s no ref '%s'...\n", path, owner, repo, refToUse)
 		docsDirOpts := &github.RepositoryContentGetOptions{Ref: refToUse}
 
@@ -118,6 +422,7 @@ func (c *Client) GetRepositoryDocumentation(ctx context.Context, owner, repo, de
 			log.Printf("Pasta de documentação %s encontrada para %s/%s no ref '%s'.\n", path, owner, repo, refToUse)
 			searchInDocs = true
 			foundDocPath = path
+			progress.ReportStrategy(ctx, progress.StageDiscovery, "docs_folder")
 			break
 		} else {
 			// Verificar se o erro é 404 Not Found
@@ -151,6 +456,7 @@ func (c *Client) GetRepositoryDocumentation(ctx context.Context, owner, repo, de
 							log.Printf("Pasta de documentação '%s' encontrada na raiz de %s/%s no ref '%s'.\n", item.GetName(), owner, repo, refToUse)
 							foundDocPath = item.GetName()
 							searchInDocs = true
+							progress.ReportStrategy(ctx, progress.StageDiscovery, "root_folder")
 							break // Sai do loop de standardRootDocFolders
 						}
 					}
@@ -169,12 +475,14 @@ func (c *Client) GetRepositoryDocumentation(ctx context.Context, owner, repo, de
 	// and we have a rootTreeSHA, try to find all documentation files using the Git Tree API as a fallback.
 	if !searchInDocs && rootTreeSHA != "" {
 		log.Printf("No specific documentation folder found for %s/%s. Attempting to find all .md/.mdx files via Git Tree API using tree SHA %s", owner, repo, rootTreeSHA)
-		pathsFromTree, treeErr := c._getDocPathsFromTree(ctx, owner, repo, rootTreeSHA)
+		pathsFromTree, treeComplete, treeErr := c._getDocPathsFromTree(ctx, owner, repo, rootTreeSHA)
 		if treeErr != nil {
 			log.Printf("Error using Git Tree API for %s/%s (tree %s): %v. Proceeding without these results.", owner, repo, rootTreeSHA, treeErr)
 		} else if len(pathsFromTree) > 0 {
+			listingComplete = treeComplete
 			log.Printf("Found %d documentation files via Git Tree API for %s/%s.", len(pathsFromTree), owner, repo)
 			docPaths = pathsFromTree
+			progress.ReportStrategy(ctx, progress.StageDiscovery, "git_tree")
 			// When using Git Tree API for a global search, we assume all found .md/.mdx files are desired.
 			// The 'searchInDocs' flag and 'foundDocPath' might not be relevant in the same way,
 			// as we are not limiting to a sub-folder. The content fetching loop below will handle these paths.
@@ -225,6 +533,7 @@ func (c *Client) GetRepositoryDocumentation(ctx context.Context, owner, repo, de
 			// Note: searchForMarkdownFiles currently searches the default branch. If refToUse is critical here,
 			// searchForMarkdownFiles would need modification to include the ref in its query string.
 			// For now, we use it as is, which might be acceptable for a broad fallback.
+			progress.ReportStrategy(ctx, progress.StageDiscovery, "code_search")
 			globalPaths, err := c.searchForMarkdownFiles(ctx, owner, repo) // refToUse and extensions are implicit or handled within the method
 			if err != nil {
 				log.Printf("Erro na busca global por arquivos de documentação para %s/%s: %v\n", owner, repo, err)
@@ -235,142 +544,87 @@ func (c *Client) GetRepositoryDocumentation(ctx context.Context, owner, repo, de
 		}
 	}
 
-	// Check if any documentation files were found
-	if len(docPaths) == 0 {
-		log.Printf("No documentation files found for %s/%s on ref '%s'.\n", owner, repo, refToUse)
-		return nil, errors.New("no documentation files found")
-	}
-
-	// 4. Fetch content for the determined docPaths
-	log.Printf("Fetching content for %d documentation paths for %s/%s from ref '%s' using concurrency %d...\n", len(docPaths), owner, repo, refToUse, concurrencyLimit)
-	var (
-		wg            sync.WaitGroup
-		mu            sync.Mutex
-		documentation = []models.Documentation{} // Initialize here instead of at the top
-		errChan       = make(chan error, len(docPaths))
-		semaphore     = make(chan struct{}, concurrencyLimit)
-	)
-
-	for _, path := range docPaths {
-		wg.Add(1)
-		semaphore <- struct{}{} // Acquire semaphore slot
+	return docPaths, listingComplete
+}
 
-		go func(p string) {
-			defer func() {
-				<-semaphore // Release semaphore slot
-				wg.Done()
-			}()
+// listDocPathsInSubtree lists the markdown files under subPath. A subPath that
+// is itself a markdown file is returned as is. It also reports whether the listing is complete.
+func (c *Client) listDocPathsInSubtree(ctx context.Context, owner, repo, subPath, ref, rootTreeSHA string) ([]string, bool, error) {
+	if discovery.IsMarkdownFile(subPath) {
+		return []string{subPath}, true, nil
+	}
 
-			// Fetch content using the determined default branch
-			doc, err := c.getFileContent(ctx, owner, repo, p, refToUse)
-			if err != nil {
-				// Log specific file fetch errors
-				log.Printf("Error fetching content for %s/%s path %s from ref '%s': %v\n", owner, repo, p, refToUse, err)
-				// Send a non-blocking error to avoid deadlock if channel buffer is full
-				select {
-				case errChan <- fmt.Errorf("error fetching content for %s: %w", p, err):
-				default:
-					log.Printf("Error channel full, discarding error for %s\n", p)
+	var paths []string
+	if rootTreeSHA != "" {
+		treePaths, complete, err := c._getDocPathsFromTree(ctx, owner, repo, rootTreeSHA)
+		if err == nil {
+			prefix := subPath + "/"
+			for _, p := range treePaths {
+				if strings.HasPrefix(p, prefix) {
+					paths = append(paths, p)
 				}
-				return
-			}
-
-			if doc == nil {
-				log.Printf("Skipping nil content for path %s in %s/%s from ref '%s'\n", p, owner, repo, refToUse)
-				return // Skip if content fetching somehow returned nil without error
 			}
-
-			// Convert to model and add to result
-			content, err := doc.GetContent() // Handles base64 decoding
-			if err != nil {
-				log.Printf("Error getting/decoding content for %s from ref '%s': %v\n", p, refToUse, err)
-				select {
-				case errChan <- fmt.Errorf("error getting/decoding content for %s: %w", p, err):
-				default:
-					log.Printf("Error channel full, discarding content error for %s\n", p)
-				}
-				return
-			}
-
-			docModel := models.Documentation{
-				RepoID:      0, // Repository ID is not fetched in this function
-				RepoName:    fmt.Sprintf("%s/%s", owner, repo),
-				Path:        p,
-				Content:     content,
-				ContentType: doc.GetType(),
-				Size:        doc.GetSize(),
-				SHA:         doc.GetSHA(),
-				URL:         doc.GetHTMLURL(), // Use HTML URL for easier browser access if needed
-			}
-
-			mu.Lock()
-			documentation = append(documentation, docModel)
-			mu.Unlock()
-		}(path)
-	}
-
-	wg.Wait()
-	close(errChan)
-
-	// Check for errors during fetch
-	var fetchErrors []string
-	for err := range errChan {
-		if err != nil {
-			fetchErrors = append(fetchErrors, err.Error())
+			return paths, complete, nil
 		}
+		log.Printf("Error using Git Tree API for %s/%s path '%s': %v. Falling back to the contents API.", owner, repo, subPath, err)
 	}
 
-	if len(fetchErrors) > 0 {
-		// If we got *some* docs despite errors, return them but log the errors.
-		// If we got *no* docs and there were errors, return the error.
-		log.Printf("%d errors occurred during content fetch for %s/%s from ref '%s': %s\n", len(fetchErrors), owner, repo, refToUse, strings.Join(fetchErrors, "; "))
-		if len(documentation) == 0 {
-			return nil, fmt.Errorf("failed to fetch documentation content: %s", fetchErrors[0]) // Return first error
+	var files []string
+	if err := c.listFilesRecursively(ctx, owner, repo, subPath, ref, &files); err != nil {
+		return nil, false, processGitHubError(err)
+	}
+	for _, p := range files {
+		if discovery.IsMarkdownFile(p) {
+			paths = append(paths, p)
 		}
 	}
+	return paths, true, nil
+}
 
-	if len(documentation) == 0 {
-		// This case now means either no paths were found initially, or all fetches failed.
-		log.Printf("No documentation content could be successfully retrieved for %s/%s from ref '%s'.\n", owner, repo, refToUse)
-		return nil, errors.New("no documentation content could be successfully retrieved")
+// _getDocPathsFromTree fetches all documentation file paths from a repository using the Git Tree API.
+// It filters for .md and .mdx files. When the recursive tree is truncated, the tree is walked
+// subtree by subtree instead; the returned flag reports whether the listing is complete.
+func (c *Client) _getDocPathsFromTree(ctx context.Context, owner, repo, treeSHA string) ([]string, bool, error) {
+	entries, complete, err := c.getDocBlobsFromTree(ctx, owner, repo, treeSHA)
+	if err != nil {
+		return nil, false, err
 	}
 
-	log.Printf("Successfully retrieved content for %d documentation files from %s/%s from ref '%s'\n", len(documentation), owner, repo, refToUse)
-	return documentation, nil
+	docPaths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		docPaths = append(docPaths, entry.GetPath())
+	}
+	return docPaths, complete, nil
 }
 
-// _getDocPathsFromTree fetches all documentation file paths from a repository using the Git Tree API.
-// It filters for .md and .mdx files.
-func (c *Client) _getDocPathsFromTree(ctx context.Context, owner, repo, treeSHA string) ([]string, error) {
+// getDocBlobsFromTree returns the tree entries of the documentation files of a tree, with paths
+// relative to the tree root, and whether the listing is complete
+func (c *Client) getDocBlobsFromTree(ctx context.Context, owner, repo, treeSHA string) ([]*github.TreeEntry, bool, error) {
 	if treeSHA == "" {
-		return nil, fmt.Errorf("treeSHA cannot be empty for _getDocPathsFromTree")
+		return nil, false, fmt.Errorf("treeSHA cannot be empty for _getDocPathsFromTree")
 	}
 
 	log.Printf("Fetching Git tree for %s/%s using SHA: %s", owner, repo, treeSHA)
 	tree, _, err := c.client.Git.GetTree(ctx, owner, repo, treeSHA, true) // true for recursive
 	if err != nil {
-		return nil, fmt.Errorf("failed to get git tree for %s/%s (SHA: %s): %w", owner, repo, treeSHA, err)
+		return nil, false, fmt.Errorf("failed to get git tree for %s/%s (SHA: %s): %w", owner, repo, treeSHA, err)
 	}
 
 	if tree.GetTruncated() {
-		log.Printf("Warning: Git tree for %s/%s (SHA: %s) was truncated. Some files may be missing.", owner, repo, treeSHA)
-		// Consider if we need to handle this more actively, e.g., by returning an error or specific info.
+		log.Printf("Git tree for %s/%s (SHA: %s) was truncated. Walking its subtrees instead.", owner, repo, treeSHA)
+		entries, complete := c.walkTruncatedTree(ctx, owner, repo, treeSHA)
+		return entries, complete, nil
 	}
 
-	var docPaths []string
-	docExtensions := map[string]bool{".md": true, ".mdx": true}
-
+	var entries []*github.TreeEntry
 	for _, entry := range tree.Entries {
-		if entry.GetType() == "blob" {
-			ext := strings.ToLower(filepath.Ext(entry.GetPath()))
-			if docExtensions[ext] {
-				docPaths = append(docPaths, entry.GetPath())
-			}
+		if entry.GetType() == "blob" && discovery.IsMarkdownFile(entry.GetPath()) {
+			entries = append(entries, entry)
 		}
 	}
 
-	log.Printf("Found %d documentation files (.md, .mdx) via Git Tree API for %s/%s (tree %s)", len(docPaths), owner, repo, treeSHA)
-	return docPaths, nil
+	log.Printf("Found %d documentation files (.md, .mdx) via Git Tree API for %s/%s (tree %s)", len(entries), owner, repo, treeSHA)
+	return entries, true, nil
 }
 
 // getFileContent fetches the content of a file from GitHub
@@ -417,6 +671,33 @@ func (c *Client) SearchRepositories(ctx context.Context, query string, page, per
 	return repositories, resp.NextPage, nil
 }
 
+// ListTags lists all tag names of a repository. GitHub does not order tags by recency,
+// so every page is fetched for the highest versions not to be missed.
+func (c *Client) ListTags(ctx context.Context, owner, repo string) ([]string, error) {
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
+
+	opts := &github.ListOptions{PerPage: 100}
+	var tags []string
+	for {
+		repoTags, resp, err := c.client.Repositories.ListTags(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, processGitHubError(err)
+		}
+
+		for _, tag := range repoTags {
+			tags = append(tags, tag.GetName())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return tags, nil
+}
+
 // Helper functions
 
 // convertToRepositoryModel converts a GitHub repository to our model
@@ -458,29 +739,35 @@ func convertToRepositoryModel(repo *github.Repository) *models.Repository {
 	return r
 }
 
-// processGitHubError processes GitHub API errors
+// processGitHubError converts GitHub API errors to the errors of this package
 func processGitHubError(err error) error {
+	var rateErr *github.RateLimitError
+	if errors.As(err, &rateErr) {
+		return &RateLimitError{Reset: rateErr.Rate.Reset.Time}
+	}
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		return &RateLimitError{RetryAfter: abuseErr.GetRetryAfter()}
+	}
+
 	var ghErr *github.ErrorResponse
 	if errors.As(err, &ghErr) {
 		if ghErr.Response.StatusCode == http.StatusNotFound {
-			return errors.New("repository not found")
+			return ErrNotFound
 		}
 		if ghErr.Response.StatusCode == http.StatusUnauthorized {
-			return errors.New("unauthorized: invalid GitHub token")
+			return fmt.Errorf("%w: invalid GitHub token", ErrUnauthorized)
+		}
+		if ghErr.Response.StatusCode == http.StatusTooManyRequests || isRateLimited(ghErr.Response) {
+			return rateLimitErrorFromResponse(ghErr.Response)
 		}
 		if ghErr.Response.StatusCode == http.StatusForbidden {
-			return errors.New("rate limit exceeded or access denied")
+			return fmt.Errorf("%w: %s", ErrForbidden, ghErr.Message)
 		}
 	}
 	return err
 }
 
-// isMarkdownFile checks for markdown file extensions
-func isMarkdownFile(filename string) bool {
-	lcFilename := strings.ToLower(filename)
-	return strings.HasSuffix(lcFilename, ".md") || strings.HasSuffix(lcFilename, ".mdx")
-}
-
 // isDocumentationFile checks common documentation filenames
 func isDocumentationFile(filename string) bool {
 	lcFilename := strings.ToLower(filename)
@@ -508,19 +795,15 @@ func (c *Client) searchForMarkdownFiles(ctx context.Context, owner, repo string)
 	for {
 		result, resp, err := c.client.Search.Code(ctx, query, opts)
 		if err != nil {
-			// Handle rate limit specifically
-			if _, ok := err.(*github.RateLimitError); ok {
-				log.Printf("Rate limit hit during search, trying again after reset: %v\n", resp.Rate.Reset)
-				time.Sleep(time.Until(resp.Rate.Reset.Time))
-				continue // Retry the same page
-			}
+			// Rate limits are not waited out inside a request; the token pool
+			// already retried with the other tokens
 			log.Printf("Error searching code: %v\n", err)
 			return nil, processGitHubError(err) // Use centralized error processing
 		}
 
 		for _, item := range result.CodeResults {
 			if item.Path != nil {
-				if isMarkdownFile(*item.Path) || isDocumentationFile(*item.Name) {
+				if discovery.IsMarkdownFile(*item.Path) || isDocumentationFile(*item.Name) {
 					allPaths = append(allPaths, *item.Path)
 				}
 			}
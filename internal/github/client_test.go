@@ -2,11 +2,18 @@ package github
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetRepository(t *testing.T) {
@@ -81,3 +88,56 @@ func TestGetRepositoryDocumentation(t *testing.T) {
 		t.Error("README.md should be included in documentation")
 	}
 }
+
+// TestListTags_AllPages tests that tags are listed past the first thousand
+func TestListTags_AllPages(t *testing.T) {
+	const pages = 12
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		if page < pages {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/owner/repo/tags?per_page=100&page=%d>; rel="next"`, server.URL, page+1))
+		}
+		fmt.Fprintf(w, `[{"name":"v%d.0.0"}]`, page)
+	}))
+	defer server.Close()
+
+	client := NewClientWithTokens([]string{"token"}, 5*time.Second)
+	client.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	tags, err := client.ListTags(context.Background(), "owner", "repo")
+	require.NoError(t, err)
+	assert.Len(t, tags, pages)
+	assert.Equal(t, "v12.0.0", tags[pages-1])
+}
+
+// TestFetchDocuments_CallerDeadline tests that a job deadline replaces the request timeout
+// of the client for operations made of many requests
+func TestFetchDocuments_CallerDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Each file is well within the timeout, all of them together are not
+		time.Sleep(30 * time.Millisecond)
+		fmt.Fprint(w, `{"type":"file","encoding":"base64","content":"IyBEb2NzCg==","path":"docs/a.md","name":"a.md","sha":"abc"}`)
+	}))
+	defer server.Close()
+
+	client := NewClient("", 50*time.Millisecond)
+	client.client.BaseURL, _ = url.Parse(server.URL + "/")
+	listing := &DocumentationListing{Owner: "owner", Repo: "repo", Ref: "main", CommitSHA: "c1", Complete: true}
+	paths := []string{"docs/a.md", "docs/b.md", "docs/c.md"}
+
+	// Files fetched after the client timeout fail
+	docs, err := client.FetchDocuments(context.Background(), listing, paths, 1)
+	if err == nil {
+		assert.Less(t, len(docs), len(paths))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	docs, err = client.FetchDocuments(ctx, listing, paths, 1)
+	require.NoError(t, err)
+	assert.Len(t, docs, len(paths))
+}
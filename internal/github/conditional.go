@@ -0,0 +1,153 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/dtomacheski/extract-data-go/internal/cache"
+)
+
+// conditionalEntryTTL is how long validators and bodies of GitHub responses are kept
+const conditionalEntryTTL = 7 * 24 * time.Hour
+
+// maxConditionalBodySize is the largest response body stored for revalidation
+const maxConditionalBodySize = 1 << 20
+
+// conditionalEntry is a stored GitHub response and the validators to revalidate it with
+type conditionalEntry struct {
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+}
+
+// conditionalTransport sends GET requests with If-None-Match/If-Modified-Since when a
+// response for the same URL is stored, and answers 304 Not Modified responses, which
+// do not count against the GitHub rate limit, with the stored body
+type conditionalTransport struct {
+	base  http.RoundTripper
+	store cache.Cache
+	keys  *cache.KeyBuilder
+}
+
+// EnableConditionalRequests stores the ETag and Last-Modified validators of GitHub responses
+// in the cache and revalidates later requests for the same URL with conditional requests.
+// It must be called before the client is used.
+func (c *Client) EnableConditionalRequests(store cache.Cache, keys *cache.KeyBuilder) {
+	if store == nil || !store.IsEnabled() {
+		return
+	}
+	c.conditional.store = store
+	c.conditional.keys = keys
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *conditionalTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.store == nil || req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	key := t.cacheKey(req)
+
+	var entry conditionalEntry
+	cached := t.store.Get(ctx, key, &entry) == nil
+	if cached {
+		req = req.Clone(ctx)
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case cached && resp.StatusCode == http.StatusNotModified:
+		resp.Body.Close()
+		log.Printf("GitHub response for %s not modified, serving the stored copy", req.URL.Path)
+		return entry.response(req, resp), nil
+
+	case resp.StatusCode == http.StatusOK:
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if etag == "" && lastModified == "" {
+			return resp, nil
+		}
+
+		// Bodies above the size limit are passed through without being stored
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxConditionalBodySize+1))
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		if len(body) > maxConditionalBodySize {
+			resp.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+			return resp, nil
+		}
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		t.save(ctx, key, conditionalEntry{ETag: etag, LastModified: lastModified, Header: resp.Header, Body: body})
+		return resp, nil
+	}
+
+	return resp, nil
+}
+
+// save stores a response, logging failures since the request itself succeeded
+func (t *conditionalTransport) save(ctx context.Context, key string, entry conditionalEntry) {
+	if err := t.store.SetWithTTL(ctx, key, entry, conditionalEntryTTL); err != nil {
+		log.Printf("Failed to store GitHub response validators: %v", err)
+	}
+}
+
+// cacheKey identifies a response by URL, media type and credentials, so that
+// different representations and tokens never share stored bodies
+func (t *conditionalTransport) cacheKey(req *http.Request) string {
+	h := sha256.New()
+	io.WriteString(h, req.URL.String())
+	io.WriteString(h, "\x00"+req.Header.Get("Accept"))
+	io.WriteString(h, "\x00"+req.Header.Get("Authorization"))
+	return t.keys.HTTPValidatorsKey(hex.EncodeToString(h.Sum(nil)))
+}
+
+// response rebuilds a 200 response from the stored entry. Headers of the 304 response,
+// such as the current rate limit, take precedence over the stored ones.
+func (e *conditionalEntry) response(req *http.Request, notModified *http.Response) *http.Response {
+	header := e.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	for k, v := range notModified.Header {
+		if k != "Content-Length" {
+			header[k] = v
+		}
+	}
+	header.Set("X-From-Cache", "1")
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,121 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dtomacheski/extract-data-go/internal/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCache is an in-memory cache.Cache for tests
+type memoryCache struct {
+	mu    sync.Mutex
+	items map[string][]byte
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{items: map[string][]byte{}}
+}
+
+func (m *memoryCache) Get(ctx context.Context, key string, value interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.items[key]
+	if !ok {
+		return cache.ErrCacheMiss
+	}
+	return json.Unmarshal(data, value)
+}
+
+func (m *memoryCache) Set(ctx context.Context, key string, value interface{}) error {
+	return m.SetWithTTL(ctx, key, value, 0)
+}
+
+func (m *memoryCache) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items[key] = data
+	return nil
+}
+
+func (m *memoryCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.items, key)
+	return nil
+}
+
+func (m *memoryCache) DeleteByPrefix(ctx context.Context, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.items {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.items, key)
+		}
+	}
+	return nil
+}
+
+func (m *memoryCache) FlushAll(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items = map[string][]byte{}
+	return nil
+}
+
+func (m *memoryCache) IsEnabled() bool { return true }
+func (m *memoryCache) Close() error    { return nil }
+
+// TestConditionalRequests tests that stored responses are revalidated with If-None-Match
+// and that 304 responses are served from the stored copy
+func TestConditionalRequests(t *testing.T) {
+	var fullResponses, notModified int
+	etag := `"v1"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fullResponses++
+		w.Header().Set("ETag", etag)
+		fmt.Fprintf(w, `{"full_name":"owner/repo","default_branch":"main","stargazers_count":%d}`, fullResponses)
+	}))
+	defer server.Close()
+
+	client := NewClient("token", 5*time.Second)
+	client.client.BaseURL, _ = url.Parse(server.URL + "/")
+	client.EnableConditionalRequests(newMemoryCache(), cache.NewKeyBuilder("test"))
+
+	ctx := context.Background()
+	first, err := client.GetRepository(ctx, "owner", "repo")
+	require.NoError(t, err)
+	second, err := client.GetRepository(ctx, "owner", "repo")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, fullResponses)
+	assert.Equal(t, 1, notModified)
+	assert.Equal(t, first, second)
+	assert.Equal(t, "main", second.DefaultBranch)
+
+	// A changed resource is fetched and stored again
+	etag = `"v2"`
+	third, err := client.GetRepository(ctx, "owner", "repo")
+	require.NoError(t, err)
+	assert.Equal(t, 2, fullResponses)
+	assert.Equal(t, 2, third.Stars)
+}
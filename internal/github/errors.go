@@ -0,0 +1,92 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Errors returned by the documentation sources. Errors carrying more detail wrap them,
+// so callers check them with errors.Is.
+var (
+	// ErrNotFound reports a repository, ref or path that does not exist or is not visible
+	ErrNotFound = errors.New("repository not found")
+
+	// ErrUnauthorized reports missing or invalid credentials
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrForbidden reports credentials that are not allowed to access a resource
+	ErrForbidden = errors.New("access denied")
+
+	// ErrRateLimited reports a request refused by a rate limit, see RateLimitError
+	ErrRateLimited = errors.New("rate limit exceeded")
+
+	// ErrNoDocs reports a repository without documentation files, or whose files could not be read
+	ErrNoDocs = errors.New("no documentation files found")
+)
+
+// errNoDocContent reports documentation files that were all found but could not be read
+var errNoDocContent = fmt.Errorf("%w: no documentation content could be successfully retrieved", ErrNoDocs)
+
+// RateLimitError reports a request refused by a primary or secondary rate limit
+type RateLimitError struct {
+	// Reset is when the primary rate limit resets, zero if unknown
+	Reset time.Time
+
+	// RetryAfter is the wait requested by a secondary rate limit, zero if unknown
+	RetryAfter time.Duration
+}
+
+// Error implements error
+func (e *RateLimitError) Error() string {
+	switch {
+	case e.RetryAfter > 0:
+		return fmt.Sprintf("%s (secondary rate limit), retry after %s", ErrRateLimited, e.RetryAfter)
+	case !e.Reset.IsZero():
+		return fmt.Sprintf("%s, resets at %s", ErrRateLimited, e.Reset.Format(time.RFC3339))
+	default:
+		return ErrRateLimited.Error()
+	}
+}
+
+// Is makes errors.Is(err, ErrRateLimited) match rate limit errors
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// Wait returns how long to wait before retrying, zero if unknown
+func (e *RateLimitError) Wait() time.Duration {
+	if e.RetryAfter > 0 {
+		return e.RetryAfter
+	}
+	if e.Reset.IsZero() {
+		return 0
+	}
+	return max(time.Until(e.Reset), 0)
+}
+
+// RetryAfterSeconds returns the wait in whole seconds for a Retry-After header, at least 1,
+// and false when the wait is unknown
+func (e *RateLimitError) RetryAfterSeconds() (int, bool) {
+	wait := e.Wait()
+	if wait <= 0 && e.Reset.IsZero() {
+		return 0, false
+	}
+	return max(int(math.Ceil(wait.Seconds())), 1), true
+}
+
+// rateLimitErrorFromResponse builds a RateLimitError from the Retry-After and
+// X-RateLimit-Reset headers of a refused response
+func rateLimitErrorFromResponse(resp *http.Response) *RateLimitError {
+	rateErr := &RateLimitError{}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		rateErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil && reset > 0 {
+		rateErr.Reset = time.Unix(reset, 0)
+	}
+	return rateErr
+}
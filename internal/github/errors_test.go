@@ -0,0 +1,73 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestProcessGitHubError_Typed tests that API errors are converted to the errors of this package
+func TestProcessGitHubError_Typed(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/repos/owner/unauthorized":
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/repos/owner/limited":
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+		case "/repos/owner/secondary":
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"You have exceeded a secondary rate limit","documentation_url":"https://docs.github.com/rest/overview/rate-limits-for-the-rest-api#about-secondary-rate-limits"}`)
+			return
+		}
+		fmt.Fprint(w, `{"message":"error"}`)
+	}))
+	defer server.Close()
+
+	client := NewClientWithTokens([]string{"token"}, 5*time.Second)
+	client.client.BaseURL, _ = url.Parse(server.URL + "/")
+	ctx := context.Background()
+
+	_, err := client.GetRepository(ctx, "owner", "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = client.GetRepository(ctx, "owner", "unauthorized")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	var rateErr *RateLimitError
+	_, err = client.GetRepository(ctx, "owner", "secondary")
+	require.True(t, errors.As(err, &rateErr))
+	seconds, ok := rateErr.RetryAfterSeconds()
+	assert.True(t, ok)
+	assert.Equal(t, 30, seconds)
+
+	// Checked last: the client refuses further requests until the primary limit resets
+	_, err = client.GetRepository(ctx, "owner", "limited")
+	require.ErrorIs(t, err, ErrRateLimited)
+	require.True(t, errors.As(err, &rateErr))
+	assert.True(t, rateErr.Reset.Equal(reset))
+	seconds, ok = rateErr.RetryAfterSeconds()
+	assert.True(t, ok)
+	assert.InDelta(t, 3600, seconds, 5)
+}
+
+// TestRateLimitError_UnknownWait tests that no wait is reported when the response gives none
+func TestRateLimitError_UnknownWait(t *testing.T) {
+	_, ok := (&RateLimitError{}).RetryAfterSeconds()
+	assert.False(t, ok)
+	assert.ErrorIs(t, fmt.Errorf("failed to fetch documentation: %w", &RateLimitError{}), ErrRateLimited)
+}
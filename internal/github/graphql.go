@@ -0,0 +1,206 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/dtomacheski/extract-data-go/internal/models"
+	"github.com/dtomacheski/extract-data-go/internal/progress"
+)
+
+// DefaultGraphQLFetchThreshold is the number of documentation files above which
+// blobs are fetched in batches through the GraphQL API instead of one by one
+const DefaultGraphQLFetchThreshold = 5
+
+// graphqlBatchSize is the number of blobs requested per GraphQL query
+const graphqlBatchSize = 100
+
+// SetGraphQLFetchThreshold sets the number of documentation files above which blobs are
+// fetched in batched GraphQL queries. Zero or less disables GraphQL fetches.
+func (c *Client) SetGraphQLFetchThreshold(threshold int) {
+	c.graphqlThreshold = threshold
+}
+
+// useGraphQL reports whether fileCount documentation files should be fetched through GraphQL.
+// The GraphQL API requires authentication.
+func (c *Client) useGraphQL(fileCount int) bool {
+	return c.authenticated && c.graphqlThreshold > 0 && fileCount > c.graphqlThreshold
+}
+
+// graphqlBlob is the part of a Blob object read from the GraphQL API
+type graphqlBlob struct {
+	OID         string  `json:"oid"`
+	ByteSize    int     `json:"byteSize"`
+	IsBinary    bool    `json:"isBinary"`
+	IsTruncated bool    `json:"isTruncated"`
+	Text        *string `json:"text"`
+}
+
+// graphqlResponse is the response of a batched blob query
+type graphqlResponse struct {
+	Data struct {
+		Repository map[string]*graphqlBlob `json:"repository"`
+	} `json:"data"`
+	Errors []struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// fetchDocumentsWithGraphQL reads the given paths at a commit with one GraphQL query per
+// batch of up to graphqlBatchSize blobs. Blobs GraphQL cannot return as text (truncated
+// or binary) are fetched through the Contents API.
+func (c *Client) fetchDocumentsWithGraphQL(ctx context.Context, owner, repo, refToUse, commitSHA string, docPaths []string, concurrencyLimit int) ([]models.Documentation, error) {
+	log.Printf("Fetching %d documentation files for %s/%s at commit %s through GraphQL", len(docPaths), owner, repo, commitSHA)
+
+	documentation := make([]models.Documentation, 0, len(docPaths))
+	var restPaths []string
+	failed := make(map[string]string)
+	for start := 0; start < len(docPaths); start += graphqlBatchSize {
+		end := min(start+graphqlBatchSize, len(docPaths))
+		batch := docPaths[start:end]
+
+		blobs, err := c.queryBlobs(ctx, owner, repo, commitSHA, batch)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, p := range batch {
+			blob := blobs[fmt.Sprintf("f%d", i)]
+			switch {
+			case blob == nil:
+				log.Printf("Documentation file %s not found in %s/%s at %s", p, owner, repo, commitSHA)
+				failed[p] = "not found"
+			case blob.IsBinary:
+				log.Printf("Skipping binary documentation file %s in %s/%s", p, owner, repo)
+				failed[p] = "binary file"
+			case blob.IsTruncated || blob.Text == nil:
+				restPaths = append(restPaths, p)
+			default:
+				documentation = append(documentation, models.Documentation{
+					RepoName:    fmt.Sprintf("%s/%s", owner, repo),
+					Path:        p,
+					Content:     *blob.Text,
+					ContentType: "file",
+					Size:        blob.ByteSize,
+					SHA:         blob.OID,
+					URL:         fmt.Sprintf("https://github.com/%s/%s/blob/%s/%s", owner, repo, commitSHA, p),
+					Ref:         refToUse,
+					CommitSHA:   commitSHA,
+				})
+			}
+		}
+	}
+
+	// Reported once every batch succeeded, as a failed query falls back to other strategies.
+	// Truncated files are reported by the Contents API fetch below.
+	for _, doc := range documentation {
+		progress.ReportFile(ctx, doc.Path, nil)
+	}
+	for p, reason := range failed {
+		progress.ReportFile(ctx, p, errors.New(reason))
+	}
+
+	// Large files come back truncated from GraphQL, read them from the Contents API instead
+	if len(restPaths) > 0 {
+		log.Printf("Fetching %d truncated documentation files for %s/%s through the Contents API", len(restPaths), owner, repo)
+		restDocs, err := c.fetchDocuments(ctx, owner, repo, refToUse, commitSHA, commitSHA, restPaths, concurrencyLimit)
+		if err != nil {
+			log.Printf("Error fetching truncated documentation files for %s/%s: %v", owner, repo, err)
+		}
+		documentation = append(documentation, restDocs...)
+	}
+
+	if len(documentation) == 0 {
+		return nil, errNoDocContent
+	}
+
+	log.Printf("Successfully retrieved content for %d documentation files from %s/%s through GraphQL", len(documentation), owner, repo)
+	return documentation, nil
+}
+
+// queryBlobs requests a batch of blobs in a single GraphQL query, one aliased
+// object(expression: "<commit>:<path>") field per path. The result is keyed by alias.
+func (c *Client) queryBlobs(ctx context.Context, owner, repo, commitSHA string, paths []string) (map[string]*graphqlBlob, error) {
+	var query strings.Builder
+	variables := map[string]interface{}{"owner": owner, "name": repo}
+
+	query.WriteString("query($owner: String!, $name: String!")
+	for i := range paths {
+		fmt.Fprintf(&query, ", $e%d: String!", i)
+	}
+	query.WriteString(") { repository(owner: $owner, name: $name) {")
+	for i, p := range paths {
+		fmt.Fprintf(&query, " f%d: object(expression: $e%d) { ... on Blob { oid byteSize isBinary isTruncated text } }", i, i)
+		variables[fmt.Sprintf("e%d", i)] = commitSHA + ":" + p
+	}
+	query.WriteString(" } }")
+
+	body, err := json.Marshal(map[string]interface{}{"query": query.String(), "variables": variables})
+	if err != nil {
+		return nil, err
+	}
+
+	// The owner is not part of the GraphQL URL; it selects the GitHub App installation to use
+	req, err := http.NewRequestWithContext(withOwner(ctx, owner), http.MethodPost, c.graphqlURL(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GraphQL request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return nil, fmt.Errorf("%w: invalid GitHub token", ErrUnauthorized)
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		if resp.StatusCode == http.StatusTooManyRequests || isRateLimited(resp) {
+			return nil, rateLimitErrorFromResponse(resp)
+		}
+		return nil, fmt.Errorf("%w: GraphQL request refused", ErrForbidden)
+	default:
+		return nil, fmt.Errorf("GraphQL request failed: status %s", resp.Status)
+	}
+
+	var result graphqlResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid GraphQL response: %w", err)
+	}
+	if result.Data.Repository == nil {
+		if len(result.Errors) > 0 {
+			switch result.Errors[0].Type {
+			case "NOT_FOUND":
+				return nil, ErrNotFound
+			case "RATE_LIMITED":
+				return nil, rateLimitErrorFromResponse(resp)
+			}
+			return nil, fmt.Errorf("GraphQL query failed: %s", result.Errors[0].Message)
+		}
+		return nil, ErrNotFound
+	}
+
+	return result.Data.Repository, nil
+}
+
+// graphqlURL returns the GraphQL endpoint matching the REST base URL:
+// https://api.github.com/graphql, or https://host/api/graphql on GitHub Enterprise
+func (c *Client) graphqlURL() string {
+	u := *c.client.BaseURL
+	if strings.HasSuffix(u.Path, "/api/v3/") {
+		u.Path = strings.TrimSuffix(u.Path, "v3/") + "graphql"
+	} else {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/graphql"
+	}
+	return u.String()
+}
@@ -0,0 +1,78 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFetchDocumentsWithGraphQL tests that blobs are read in batched queries and
+// truncated blobs fall back to the Contents API
+func TestFetchDocumentsWithGraphQL(t *testing.T) {
+	queries := 0
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		queries++
+		var body struct {
+			Query     string            `json:"query"`
+			Variables map[string]string `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "owner", body.Variables["owner"])
+		assert.Equal(t, "abc123:docs/a.md", body.Variables["e0"])
+
+		text := "# A\n"
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"repository": map[string]interface{}{
+					"f0": graphqlBlob{OID: "sha-a", ByteSize: 4, Text: &text},
+					"f1": graphqlBlob{OID: "sha-b", IsTruncated: true},
+					"f2": nil,
+				},
+			},
+		})
+	})
+	mux.HandleFunc("/repos/owner/repo/contents/docs/b.md", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "abc123", r.URL.Query().Get("ref"))
+		w.Write([]byte(`{"type":"file","encoding":"base64","content":"IyBC","sha":"sha-b","size":3,"path":"docs/b.md"}`))
+	})
+
+	gh := github.NewClient(nil)
+	gh.BaseURL, _ = url.Parse(server.URL + "/")
+	client := &Client{client: gh, timeout: 5 * time.Second, graphqlThreshold: 1, authenticated: true}
+
+	assert.True(t, client.useGraphQL(2))
+	assert.False(t, (&Client{graphqlThreshold: 1}).useGraphQL(2))
+
+	docs, err := client.fetchDocumentsWithGraphQL(context.Background(), "owner", "repo", "main", "abc123", []string{"docs/a.md", "docs/b.md", "docs/missing.md"}, 2)
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, 1, queries)
+
+	assert.Equal(t, "docs/a.md", docs[0].Path)
+	assert.Equal(t, "# A\n", docs[0].Content)
+	assert.Equal(t, "sha-a", docs[0].SHA)
+	assert.Equal(t, "abc123", docs[0].CommitSHA)
+	assert.Equal(t, "https://github.com/owner/repo/blob/abc123/docs/a.md", docs[0].URL)
+	assert.Equal(t, "docs/b.md", docs[1].Path)
+	assert.Equal(t, "# B", docs[1].Content)
+}
+
+// TestGraphQLURL tests the GraphQL endpoint derived from the REST base URL
+func TestGraphQLURL(t *testing.T) {
+	client := &Client{client: github.NewClient(nil)}
+	assert.Equal(t, "https://api.github.com/graphql", client.graphqlURL())
+
+	client.client.BaseURL, _ = url.Parse("https://ghe.example.com/api/v3/")
+	assert.Equal(t, "https://ghe.example.com/api/graphql", client.graphqlURL())
+}
@@ -0,0 +1,177 @@
+package github
+
+import (
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rate limit resources of the GitHub API, as reported in X-RateLimit-Resource
+const (
+	resourceCore       = "core"
+	resourceSearch     = "search"
+	resourceCodeSearch = "code_search"
+	resourceGraphQL    = "graphql"
+)
+
+// rateState is the last known rate limit of a token for one resource
+type rateState struct {
+	remaining int
+	reset     time.Time
+}
+
+// pooledToken is a token and its rate limits per resource
+type pooledToken struct {
+	token  string
+	limits map[string]rateState
+}
+
+// tokenPool authenticates requests with the token that has the most rate limit headroom
+// for the requested resource, and retries a rate-limited request with another token
+type tokenPool struct {
+	base   http.RoundTripper
+	mu     sync.Mutex
+	tokens []*pooledToken
+	next   int
+}
+
+// newTokenPool creates a pool of the non-empty, distinct tokens
+func newTokenPool(tokens []string, base http.RoundTripper) *tokenPool {
+	pool := &tokenPool{base: base}
+	seen := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" || seen[token] {
+			continue
+		}
+		seen[token] = true
+		pool.tokens = append(pool.tokens, &pooledToken{token: token, limits: map[string]rateState{}})
+	}
+	return pool
+}
+
+// RoundTrip implements http.RoundTripper
+func (p *tokenPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	resource := requestResource(req)
+	tried := make(map[int]bool, len(p.tokens))
+
+	for {
+		i := p.pick(resource, tried)
+		tried[i] = true
+
+		attempt := req.Clone(req.Context())
+		if len(tried) > 1 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attempt.Body = body
+		}
+		attempt.Header.Set("Authorization", "Bearer "+p.tokens[i].token)
+
+		resp, err := p.base.RoundTrip(attempt)
+		if err != nil {
+			return nil, err
+		}
+		p.update(i, resource, resp)
+
+		// Another token with headroom gets the request instead of failing it
+		canReplay := req.Body == nil || req.GetBody != nil
+		if isRateLimited(resp) && canReplay && p.hasHeadroom(resource, tried) {
+			log.Printf("GitHub token %d of %d is rate limited for %s, retrying with another token", i+1, len(p.tokens), resource)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			continue
+		}
+		return resp, nil
+	}
+}
+
+// pick returns the untried token with the most remaining requests for the resource. Tokens
+// without a known limit, or whose limit was reset since, count as having full headroom.
+// Ties rotate so that fresh tokens share the load.
+func (p *tokenPool) pick(resource string, tried map[int]bool) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	best, bestRemaining := -1, -1
+	for n := 0; n < len(p.tokens); n++ {
+		i := (p.next + n) % len(p.tokens)
+		if tried[i] {
+			continue
+		}
+		if remaining := p.remaining(i, resource); remaining > bestRemaining {
+			best, bestRemaining = i, remaining
+		}
+	}
+	p.next = (best + 1) % len(p.tokens)
+	return best
+}
+
+// hasHeadroom reports whether an untried token may still serve the resource
+func (p *tokenPool) hasHeadroom(resource string, tried map[int]bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i := range p.tokens {
+		if !tried[i] && p.remaining(i, resource) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// remaining returns the remaining requests of a token for a resource; p.mu must be held
+func (p *tokenPool) remaining(i int, resource string) int {
+	state, ok := p.tokens[i].limits[resource]
+	if !ok || time.Now().After(state.reset) {
+		return math.MaxInt32
+	}
+	return state.remaining
+}
+
+// update records the rate limit headers of a response
+func (p *tokenPool) update(i int, resource string, resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+	if r := resp.Header.Get("X-RateLimit-Resource"); r != "" {
+		resource = r
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tokens[i].limits[resource] = rateState{remaining: remaining, reset: time.Unix(reset, 0)}
+}
+
+// requestResource returns the rate limit resource a request counts against
+func requestResource(req *http.Request) string {
+	switch path := req.URL.Path; {
+	case strings.HasSuffix(path, "/graphql"):
+		return resourceGraphQL
+	case strings.Contains(path, "/search/code"):
+		return resourceCodeSearch
+	case strings.Contains(path, "/search/"):
+		return resourceSearch
+	default:
+		return resourceCore
+	}
+}
+
+// isRateLimited reports whether a response was refused because of a primary or secondary rate limit
+func isRateLimited(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	return resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != ""
+}
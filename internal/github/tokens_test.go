@@ -0,0 +1,87 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTokenPool tests that rate-limited tokens are skipped in favour of the one with most headroom
+func TestTokenPool(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	var used []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("Authorization")
+		used = append(used, token)
+		w.Header().Set("X-RateLimit-Reset", reset)
+		w.Header().Set("X-RateLimit-Resource", "core")
+
+		switch token {
+		case "Bearer exhausted":
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"API rate limit exceeded"}`)
+		case "Bearer busy":
+			w.Header().Set("X-RateLimit-Remaining", "10")
+			fmt.Fprint(w, `{"full_name":"owner/repo"}`)
+		default:
+			w.Header().Set("X-RateLimit-Remaining", "4000")
+			fmt.Fprint(w, `{"full_name":"owner/repo"}`)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithTokens([]string{"exhausted", "busy", "fresh", ""}, 5*time.Second)
+	client.client.BaseURL, _ = url.Parse(server.URL + "/")
+	ctx := context.Background()
+
+	// Unknown limits count as full headroom: the first token is tried, then the next one
+	_, err := client.GetRepository(ctx, "owner", "repo")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bearer exhausted", "Bearer busy"}, used)
+
+	// With all limits known, the token with the most remaining requests is used
+	used = nil
+	_, err = client.GetRepository(ctx, "owner", "repo")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bearer fresh"}, used)
+
+	used = nil
+	_, err = client.GetRepository(ctx, "owner", "repo")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bearer fresh"}, used)
+}
+
+// TestProcessGitHubError_Forbidden tests that permission errors are told apart from rate limits
+func TestProcessGitHubError_Forbidden(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/owner/limited" {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		} else {
+			w.Header().Set("X-RateLimit-Remaining", "4000")
+		}
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message":"Resource not accessible by integration"}`)
+	}))
+	defer server.Close()
+
+	client := NewClientWithTokens([]string{"token"}, 5*time.Second)
+	client.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	_, err := client.GetRepository(context.Background(), "owner", "private")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access denied")
+
+	_, err = client.GetRepository(context.Background(), "owner", "limited")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rate limit exceeded")
+}
@@ -0,0 +1,111 @@
+package github
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/dtomacheski/extract-data-go/internal/discovery"
+	"github.com/google/go-github/v53/github"
+)
+
+// treeWalkConcurrency is the number of subtrees listed at the same time when walking a truncated tree
+const treeWalkConcurrency = 8
+
+// maxTreeWalkRequests bounds the number of subtrees listed to walk a single truncated tree
+const maxTreeWalkRequests = 2000
+
+// subtree is a tree to walk and the path it is found at
+type subtree struct {
+	path string
+	sha  string
+}
+
+// walkTruncatedTree lists the documentation files of a tree too large for a single recursive
+// Git Tree API response. The tree is walked level by level: each subtree is first requested
+// recursively and, when that response is truncated as well, listed non-recursively so that
+// its own subtrees are walked at the next level. It returns the documentation blobs with
+// paths relative to the tree root and whether the listing is complete.
+func (c *Client) walkTruncatedTree(ctx context.Context, owner, repo, treeSHA string) ([]*github.TreeEntry, bool) {
+	var (
+		mu       sync.Mutex
+		docBlobs []*github.TreeEntry
+		complete = true
+		requests = 0
+	)
+
+	level := []subtree{{sha: treeSHA}}
+	for depth := 0; len(level) > 0; depth++ {
+		log.Printf("Walking %d subtrees at depth %d of the truncated tree of %s/%s", len(level), depth, owner, repo)
+
+		var (
+			wg        sync.WaitGroup
+			next      []subtree
+			semaphore = make(chan struct{}, treeWalkConcurrency)
+		)
+		for _, st := range level {
+			if requests >= maxTreeWalkRequests {
+				log.Printf("Stopping walk of %s/%s after listing %d subtrees; the listing is incomplete", owner, repo, requests)
+				complete = false
+				break
+			}
+			requests++
+
+			wg.Add(1)
+			semaphore <- struct{}{}
+			go func(st subtree) {
+				defer func() {
+					<-semaphore
+					wg.Done()
+				}()
+
+				// The root is known to be truncated, so it is listed non-recursively right away
+				recursive := st.path != ""
+				entries, truncated, err := c.listTree(ctx, owner, repo, st.sha, recursive)
+				if err == nil && truncated && recursive {
+					recursive = false
+					entries, truncated, err = c.listTree(ctx, owner, repo, st.sha, false)
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil || truncated {
+					log.Printf("Failed to list subtree '%s' of %s/%s: %v (truncated: %t)", st.path, owner, repo, err, truncated)
+					complete = false
+					return
+				}
+				for _, entry := range entries {
+					p := entry.GetPath()
+					if st.path != "" {
+						p = st.path + "/" + p
+					}
+					switch entry.GetType() {
+					case "blob":
+						if discovery.IsMarkdownFile(p) {
+							docBlobs = append(docBlobs, &github.TreeEntry{Path: github.String(p), SHA: entry.SHA, Size: entry.Size, Type: entry.Type})
+						}
+					case "tree":
+						// Trees of a complete recursive listing are already covered
+						if !recursive {
+							next = append(next, subtree{path: p, sha: entry.GetSHA()})
+						}
+					}
+				}
+			}(st)
+		}
+		wg.Wait()
+		level = next
+	}
+
+	log.Printf("Found %d documentation files walking %d subtrees of the truncated tree of %s/%s (complete: %t)", len(docBlobs), requests, owner, repo, complete)
+	return docBlobs, complete
+}
+
+// listTree returns the entries of a tree and whether the response was truncated
+func (c *Client) listTree(ctx context.Context, owner, repo, sha string, recursive bool) ([]*github.TreeEntry, bool, error) {
+	tree, _, err := c.client.Git.GetTree(ctx, owner, repo, sha, recursive)
+	if err != nil {
+		return nil, false, processGitHubError(err)
+	}
+	return tree.Entries, tree.GetTruncated(), nil
+}
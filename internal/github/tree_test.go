@@ -0,0 +1,63 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetDocPathsFromTree_Truncated tests that truncated trees are walked subtree by subtree
+func TestGetDocPathsFromTree_Truncated(t *testing.T) {
+	trees := map[string]string{
+		// Recursive listings
+		"root?recursive=1": `{"sha":"root","truncated":true,"tree":[{"path":"README.md","type":"blob"}]}`,
+		"docs?recursive=1": `{"sha":"docs","truncated":false,"tree":[{"path":"guide","type":"tree","sha":"guide"},{"path":"guide/a.md","type":"blob"}]}`,
+		"src?recursive=1":  `{"sha":"src","truncated":true,"tree":[]}`,
+		"pkg?recursive=1":  `{"sha":"pkg","truncated":false,"tree":[{"path":"b.mdx","type":"blob"},{"path":"b.go","type":"blob"}]}`,
+		// Non-recursive listings
+		"root": `{"sha":"root","truncated":false,"tree":[{"path":"README.md","type":"blob"},{"path":"docs","type":"tree","sha":"docs"},{"path":"src","type":"tree","sha":"src"}]}`,
+		"src":  `{"sha":"src","truncated":false,"tree":[{"path":"x.md","type":"blob"},{"path":"pkg","type":"tree","sha":"pkg"}]}`,
+	}
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/repos/owner/repo/git/trees/", func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Path[len("/repos/owner/repo/git/trees/"):]
+		if r.URL.Query().Get("recursive") != "" {
+			key += "?recursive=1"
+		}
+		body, ok := trees[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, body)
+	})
+
+	gh := github.NewClient(nil)
+	gh.BaseURL, _ = url.Parse(server.URL + "/")
+	client := &Client{client: gh, timeout: 5 * time.Second}
+
+	paths, complete, err := client._getDocPathsFromTree(context.Background(), "owner", "repo", "root")
+	require.NoError(t, err)
+	assert.True(t, complete)
+	sort.Strings(paths)
+	assert.Equal(t, []string{"README.md", "docs/guide/a.md", "src/pkg/b.mdx", "src/x.md"}, paths)
+
+	// A subtree that cannot be listed leaves the listing incomplete
+	delete(trees, "pkg?recursive=1")
+	paths, complete, err = client._getDocPathsFromTree(context.Background(), "owner", "repo", "root")
+	require.NoError(t, err)
+	assert.False(t, complete)
+	assert.Len(t, paths, 3)
+}
@@ -0,0 +1,286 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dtomacheski/extract-data-go/internal/discovery"
+	"github.com/dtomacheski/extract-data-go/internal/github"
+	"github.com/dtomacheski/extract-data-go/internal/models"
+)
+
+// DefaultBaseURL is the base URL of gitlab.com
+const DefaultBaseURL = "https://gitlab.com"
+
+// maxPages bounds how many pages of a paginated listing are fetched
+const maxPages = 50
+
+// Client represents a GitLab REST API (v4) client
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+	timeout    time.Duration
+}
+
+// NewClient creates a new GitLab API client for the instance at baseURL.
+// The token is optional for public projects.
+func NewClient(baseURL, token string, timeout time.Duration) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{},
+		timeout:    timeout,
+	}
+}
+
+// project is the subset of a GitLab project used by the client
+type project struct {
+	ID                int64     `json:"id"`
+	Name              string    `json:"name"`
+	PathWithNamespace string    `json:"path_with_namespace"`
+	Description       string    `json:"description"`
+	StarCount         int       `json:"star_count"`
+	ForksCount        int       `json:"forks_count"`
+	DefaultBranch     string    `json:"default_branch"`
+	WebURL            string    `json:"web_url"`
+	Topics            []string  `json:"topics"`
+	CreatedAt         time.Time `json:"created_at"`
+	LastActivityAt    time.Time `json:"last_activity_at"`
+}
+
+// treeEntry is an entry of a repository tree listing
+type treeEntry struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+	Type string `json:"type"`
+}
+
+// GetRepository fetches a GitLab project. Owner may contain subgroups, e.g. "group/subgroup".
+func (c *Client) GetRepository(ctx context.Context, owner, repo string) (*models.Repository, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var p project
+	if _, err := c.getJSON(ctx, projectPath(owner, repo), nil, &p); err != nil {
+		return nil, err
+	}
+
+	return &models.Repository{
+		ID:            p.ID,
+		Name:          p.Name,
+		FullName:      p.PathWithNamespace,
+		Description:   p.Description,
+		Stars:         p.StarCount,
+		Forks:         p.ForksCount,
+		Topics:        p.Topics,
+		DefaultBranch: p.DefaultBranch,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.LastActivityAt,
+		URL:           c.baseURL + "/api/v4" + projectPath(owner, repo),
+		HTMLURL:       p.WebURL,
+	}, nil
+}
+
+// ListTags lists the tag names of a project, most recently updated first
+func (c *Client) ListTags(ctx context.Context, owner, repo string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var names []string
+	err := c.paginate(ctx, projectPath(owner, repo)+"/repository/tags", url.Values{}, func(body []byte) error {
+		var tags []struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(body, &tags); err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			names = append(names, tag.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// GetRepositoryDocumentationInPath fetches the documentation of a project at ref, or at
+// defaultBranch when ref is empty. When subPath is set only the markdown files under it are fetched.
+func (c *Client) GetRepositoryDocumentationInPath(ctx context.Context, owner, repo, defaultBranch, ref, subPath string, concurrencyLimit int) ([]models.Documentation, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	if ref == "" {
+		ref = defaultBranch
+	}
+	if ref == "" {
+		return nil, fmt.Errorf("default branch not provided for repository %s/%s and no specific ref given", owner, repo)
+	}
+
+	// Resolve the ref to a commit so that every document comes from the same revision
+	var commit struct {
+		ID string `json:"id"`
+	}
+	if _, err := c.getJSON(ctx, projectPath(owner, repo)+"/repository/commits/"+url.PathEscape(ref), nil, &commit); err != nil {
+		return nil, err
+	}
+	log.Printf("Resolved ref '%s' of %s/%s to commit %s", ref, owner, repo, commit.ID)
+
+	query := url.Values{"ref": {commit.ID}, "recursive": {"true"}}
+	if dir := strings.Trim(subPath, "/"); dir != "" && !discovery.IsMarkdownFile(dir) {
+		query.Set("path", dir)
+	}
+
+	blobIDs := make(map[string]string)
+	var paths []string
+	err := c.paginate(ctx, projectPath(owner, repo)+"/repository/tree", query, func(body []byte) error {
+		var entries []treeEntry
+		if err := json.Unmarshal(body, &entries); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.Type == "blob" {
+				paths = append(paths, entry.Path)
+				blobIDs[entry.Path] = entry.ID
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	docPaths := discovery.SelectDocPaths(paths, subPath)
+	if len(docPaths) == 0 {
+		log.Printf("No documentation files found for %s/%s on ref '%s' (path '%s').\n", owner, repo, ref, subPath)
+		return nil, github.ErrNoDocs
+	}
+
+	webURL := c.baseURL + "/" + owner + "/" + repo
+	log.Printf("Fetching content for %d documentation paths for %s/%s from ref '%s' using concurrency %d...\n", len(docPaths), owner, repo, ref, concurrencyLimit)
+	return discovery.FetchDocuments(ctx, docPaths, concurrencyLimit, func(ctx context.Context, p string) (*models.Documentation, error) {
+		content, err := c.getRaw(ctx, projectPath(owner, repo)+"/repository/files/"+url.PathEscape(p)+"/raw", url.Values{"ref": {commit.ID}})
+		if err != nil {
+			return nil, err
+		}
+
+		return &models.Documentation{
+			RepoName:    owner + "/" + repo,
+			Path:        p,
+			Content:     string(content),
+			ContentType: "file",
+			Size:        len(content),
+			SHA:         blobIDs[p],
+			URL:         webURL + "/-/blob/" + commit.ID + "/" + p,
+			Ref:         ref,
+			CommitSHA:   commit.ID,
+		}, nil
+	})
+}
+
+// projectPath returns the API path of a project, addressed by its URL-encoded full path
+func projectPath(owner, repo string) string {
+	return "/projects/" + url.PathEscape(owner+"/"+repo)
+}
+
+// paginate requests every page of a listing, passing each response body to handle
+func (c *Client) paginate(ctx context.Context, path string, query url.Values, handle func(body []byte) error) error {
+	query.Set("per_page", "100")
+	for page := 1; page > 0 && page <= maxPages; {
+		query.Set("page", strconv.Itoa(page))
+		body, header, err := c.get(ctx, path, query)
+		if err != nil {
+			return err
+		}
+		if err := handle(body); err != nil {
+			return fmt.Errorf("failed to decode GitLab response: %w", err)
+		}
+
+		page, _ = strconv.Atoi(header.Get("X-Next-Page"))
+	}
+	return nil
+}
+
+// getJSON requests path and decodes the JSON response into v
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, v interface{}) (http.Header, error) {
+	body, header, err := c.get(ctx, path, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return nil, fmt.Errorf("failed to decode GitLab response: %w", err)
+	}
+	return header, nil
+}
+
+// getRaw requests path and returns the raw response body
+func (c *Client) getRaw(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	body, _, err := c.get(ctx, path, query)
+	return body, err
+}
+
+// get performs an authenticated GET request against the API
+func (c *Client) get(ctx context.Context, path string, query url.Values) ([]byte, http.Header, error) {
+	endpoint := c.baseURL + "/api/v4" + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, processGitLabError(resp.StatusCode, resp.Header, body)
+	}
+
+	return body, resp.Header, nil
+}
+
+// processGitLabError converts an error response to the errors used by the other sources
+func processGitLabError(statusCode int, header http.Header, body []byte) error {
+	switch statusCode {
+	case http.StatusNotFound:
+		return github.ErrNotFound
+	case http.StatusUnauthorized:
+		return fmt.Errorf("%w: invalid GitLab token", github.ErrUnauthorized)
+	case http.StatusForbidden:
+		return fmt.Errorf("%w: %s", github.ErrForbidden, strings.TrimSpace(string(body)))
+	case http.StatusTooManyRequests:
+		rateErr := &github.RateLimitError{}
+		if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds > 0 {
+			rateErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return rateErr
+	}
+	return fmt.Errorf("GitLab API error (status %d): %s", statusCode, strings.TrimSpace(string(body)))
+}
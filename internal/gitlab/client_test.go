@@ -0,0 +1,81 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer stands in for the GitLab API of a project group/sub/repo
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	handle := func(path string, handler http.HandlerFunc) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "secret", r.Header.Get("PRIVATE-TOKEN"))
+			handler(w, r)
+		})
+	}
+
+	handle("/api/v4/projects/group%2Fsub%2Frepo", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 7, "name": "repo", "path_with_namespace": "group/sub/repo", "default_branch": "main", "star_count": 3, "web_url": "https://gitlab.example.com/group/sub/repo"}`))
+	})
+	handle("/api/v4/projects/group%2Fsub%2Frepo/repository/tags", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "1" {
+			w.Header().Set("X-Next-Page", "2")
+			w.Write([]byte(`[{"name": "v2.0.0"}]`))
+			return
+		}
+		w.Write([]byte(`[{"name": "v1.0.0"}]`))
+	})
+	handle("/api/v4/projects/group%2Fsub%2Frepo/repository/commits/main", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "abc123"}`))
+	})
+	handle("/api/v4/projects/group%2Fsub%2Frepo/repository/tree", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "abc123", r.URL.Query().Get("ref"))
+		w.Write([]byte(`[
+			{"id": "b1", "path": "docs", "type": "tree"},
+			{"id": "b2", "path": "docs/intro.md", "type": "blob"},
+			{"id": "b3", "path": "README.md", "type": "blob"}
+		]`))
+	})
+	handle("/api/v4/projects/group%2Fsub%2Frepo/repository/files/docs%2Fintro.md/raw", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("# Intro\n"))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestGetRepositoryDocumentation tests project, tag and documentation fetching
+func TestGetRepositoryDocumentation(t *testing.T) {
+	server := newTestServer(t)
+	client := NewClient(server.URL, "secret", 5*time.Second)
+	ctx := context.Background()
+
+	repo, err := client.GetRepository(ctx, "group/sub", "repo")
+	require.NoError(t, err)
+	assert.Equal(t, "group/sub/repo", repo.FullName)
+	assert.Equal(t, "main", repo.DefaultBranch)
+
+	tags, err := client.ListTags(ctx, "group/sub", "repo")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v2.0.0", "v1.0.0"}, tags)
+
+	docs, err := client.GetRepositoryDocumentationInPath(ctx, "group/sub", "repo", "main", "", "", 2)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "docs/intro.md", docs[0].Path)
+	assert.Equal(t, "# Intro\n", docs[0].Content)
+	assert.Equal(t, "b2", docs[0].SHA)
+	assert.Equal(t, "abc123", docs[0].CommitSHA)
+	assert.Equal(t, server.URL+"/group/sub/repo/-/blob/abc123/docs/intro.md", docs[0].URL)
+
+	_, err = client.GetRepository(ctx, "group", "missing")
+	assert.EqualError(t, err, "repository not found")
+}
@@ -0,0 +1,223 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/dtomacheski/extract-data-go/internal/cache"
+	"github.com/dtomacheski/extract-data-go/internal/github"
+	"github.com/dtomacheski/extract-data-go/internal/models"
+)
+
+// indexTTL is how long the index of a repository is kept to compare later commits against
+const indexTTL = 30 * 24 * time.Hour
+
+// ErrIndexUnavailable is returned when there is no cache to keep indexes in
+var ErrIndexUnavailable = errors.New("incremental indexing requires the cache to be enabled")
+
+// Source lists and fetches the documentation of GitHub repositories
+type Source interface {
+	GetRepository(ctx context.Context, owner, repo string) (*models.Repository, error)
+	ListDocumentation(ctx context.Context, owner, repo, defaultBranch, ref string) (*github.DocumentationListing, error)
+	FetchDocuments(ctx context.Context, listing *github.DocumentationListing, paths []string, concurrencyLimit int) ([]models.Documentation, error)
+}
+
+// Summary reports the outcome of a re-indexing run
+type Summary struct {
+	Repository        string   `json:"repository"`
+	Ref               string   `json:"ref"`
+	PreviousCommitSHA string   `json:"previous_commit_sha,omitempty"`
+	CommitSHA         string   `json:"commit_sha"`
+	Added             []string `json:"added"`
+	Changed           []string `json:"changed"`
+	Removed           []string `json:"removed"`
+	Unchanged         int      `json:"unchanged"`
+	ListingComplete   bool     `json:"listing_complete"`
+
+	// Documents is the documentation of the repository at CommitSHA, fetched or reused
+	Documents []models.Documentation `json:"-"`
+}
+
+// Indexer keeps the documentation cache of repositories up to date by fetching
+// only the documents whose blobs changed since the last indexed commit
+type Indexer struct {
+	Source      Source
+	Cache       cache.Cache
+	Keys        *cache.KeyBuilder
+	Concurrency int
+	Logger      *log.Logger
+}
+
+// NewIndexer creates a new incremental indexer
+func NewIndexer(source Source, cacheClient cache.Cache, keys *cache.KeyBuilder, concurrency int, logger *log.Logger) *Indexer {
+	return &Indexer{
+		Source:      source,
+		Cache:       cacheClient,
+		Keys:        keys,
+		Concurrency: concurrency,
+		Logger:      logger,
+	}
+}
+
+// Reindex resolves ref (the default branch when empty) to a commit and brings the cached
+// documentation of the repository up to date with it: documents whose blob SHA is new or
+// changed are fetched, documents no longer present are dropped and the others are kept.
+func (ix *Indexer) Reindex(ctx context.Context, owner, repo, ref string) (*Summary, error) {
+	if ix.Cache == nil || !ix.Cache.IsEnabled() {
+		return nil, ErrIndexUnavailable
+	}
+
+	var defaultBranch string
+	if ref == "" {
+		repoInfo, err := ix.Source.GetRepository(ctx, owner, repo)
+		if err != nil {
+			return nil, err
+		}
+		defaultBranch = repoInfo.DefaultBranch
+	}
+
+	listing, err := ix.Source.ListDocumentation(ctx, owner, repo, defaultBranch, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// The previous index; a missing one makes every document new
+	var previous models.RepositoryDocumentationIndex
+	indexKey := ix.Keys.RepositoryIndexKey(owner, repo, ref)
+	if err := ix.Cache.Get(ctx, indexKey, &previous); err != nil && err != cache.ErrCacheMiss {
+		ix.Logger.Printf("Failed to read documentation index of %s/%s (ref: %s): %v", owner, repo, ref, err)
+	}
+	previousDocs := make(map[string]models.DocumentMetadata, len(previous.Documents))
+	for _, doc := range previous.Documents {
+		previousDocs[doc.Path] = doc
+	}
+
+	summary := &Summary{
+		Repository:        owner + "/" + repo,
+		Ref:               listing.Ref,
+		PreviousCommitSHA: previous.CommitSHA,
+		CommitSHA:         listing.CommitSHA,
+		Added:             []string{},
+		Changed:           []string{},
+		Removed:           []string{},
+		ListingComplete:   listing.Complete,
+	}
+
+	// Compare blob SHAs; unchanged documents are reused from the content cache
+	var toFetch []string
+	var documents []models.Documentation
+	for _, p := range listing.Paths {
+		blobSHA := listing.BlobSHAs[p]
+		old, indexed := previousDocs[p]
+		switch {
+		case !indexed:
+			summary.Added = append(summary.Added, p)
+			toFetch = append(toFetch, p)
+		case blobSHA == "" || blobSHA != old.SHA:
+			summary.Changed = append(summary.Changed, p)
+			toFetch = append(toFetch, p)
+		default:
+			doc, ok := ix.cachedDocument(ctx, owner, repo, ref, p, old.SHA)
+			if !ok {
+				// Same blob, but its content expired from the cache
+				toFetch = append(toFetch, p)
+				summary.Unchanged++
+				continue
+			}
+			doc.Ref, doc.CommitSHA, doc.PartialListing = listing.Ref, listing.CommitSHA, !listing.Complete
+			documents = append(documents, doc)
+			summary.Unchanged++
+		}
+	}
+
+	// Documents missing from an incomplete listing may still exist, so they are only
+	// dropped when the whole tree was listed
+	listed := make(map[string]bool, len(listing.Paths))
+	for _, p := range listing.Paths {
+		listed[p] = true
+	}
+	for _, doc := range previous.Documents {
+		if listed[doc.Path] {
+			continue
+		}
+		if !listing.Complete {
+			if cached, ok := ix.cachedDocument(ctx, owner, repo, ref, doc.Path, doc.SHA); ok {
+				documents = append(documents, cached)
+				continue
+			}
+		}
+		summary.Removed = append(summary.Removed, doc.Path)
+		if err := ix.Cache.Delete(ctx, ix.Keys.DocumentContentKey(owner, repo, ref, doc.Path, doc.SHA)); err != nil {
+			ix.Logger.Printf("Failed to drop cached content of %s: %v", doc.Path, err)
+		}
+	}
+
+	if len(toFetch) > 0 {
+		ix.Logger.Printf("Fetching %d of %d documents of %s/%s at %s", len(toFetch), len(listing.Paths), owner, repo, listing.CommitSHA)
+		fetched, err := ix.Source.FetchDocuments(ctx, listing, toFetch, ix.Concurrency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch changed documents: %w", err)
+		}
+		documents = append(documents, fetched...)
+	}
+	sort.Slice(documents, func(i, j int) bool { return documents[i].Path < documents[j].Path })
+	sort.Strings(summary.Added)
+	sort.Strings(summary.Changed)
+	sort.Strings(summary.Removed)
+
+	if err := ix.store(ctx, owner, repo, ref, listing, documents); err != nil {
+		return nil, err
+	}
+	summary.Documents = documents
+
+	ix.Logger.Printf("Re-indexed %s/%s (ref: %s) at %s: %d added, %d changed, %d removed, %d unchanged",
+		owner, repo, listing.Ref, listing.CommitSHA, len(summary.Added), len(summary.Changed), len(summary.Removed), summary.Unchanged)
+	return summary, nil
+}
+
+// cachedDocument reads the cached content of the blob of a path
+func (ix *Indexer) cachedDocument(ctx context.Context, owner, repo, ref, path, blobSHA string) (models.Documentation, bool) {
+	var doc models.Documentation
+	if err := ix.Cache.Get(ctx, ix.Keys.DocumentContentKey(owner, repo, ref, path, blobSHA), &doc); err != nil {
+		return doc, false
+	}
+	return doc, true
+}
+
+// store caches the documents and the new index, both as the long-lived index and as the
+// metadata index the documentation endpoint serves from
+func (ix *Indexer) store(ctx context.Context, owner, repo, ref string, listing *github.DocumentationListing, documents []models.Documentation) error {
+	index := models.RepositoryDocumentationIndex{
+		RepositoryOwner: owner,
+		RepositoryName:  repo,
+		RepositoryRef:   ref,
+		CommitSHA:       listing.CommitSHA,
+		DocumentCount:   len(documents),
+		CreatedAt:       time.Now(),
+		Documents:       make([]models.DocumentMetadata, 0, len(documents)),
+	}
+
+	for _, doc := range documents {
+		index.Documents = append(index.Documents, models.DocumentMetadata{
+			Path:      doc.Path,
+			Size:      doc.Size,
+			SHA:       doc.SHA,
+			CreatedAt: time.Now(),
+		})
+		if err := ix.Cache.Set(ctx, ix.Keys.DocumentContentKey(owner, repo, ref, doc.Path, doc.SHA), doc); err != nil {
+			ix.Logger.Printf("Failed to cache document content for %s: %v", doc.Path, err)
+		}
+	}
+
+	if err := ix.Cache.SetWithTTL(ctx, ix.Keys.RepositoryIndexKey(owner, repo, ref), index, indexTTL); err != nil {
+		return fmt.Errorf("failed to store documentation index: %w", err)
+	}
+	if err := ix.Cache.Set(ctx, ix.Keys.RepositoryDocumentationMetadataKey(owner, repo, ref), index); err != nil {
+		ix.Logger.Printf("Failed to cache repository documentation metadata: %v", err)
+	}
+	return nil
+}
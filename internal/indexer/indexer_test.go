@@ -0,0 +1,134 @@
+package indexer
+
+import (
+	"context"
+	"io"
+	"log"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/dtomacheski/extract-data-go/internal/cache"
+	"github.com/dtomacheski/extract-data-go/internal/github"
+	"github.com/dtomacheski/extract-data-go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource serves a repository whose files are path -> blob SHA
+type fakeSource struct {
+	commit  string
+	files   map[string]string
+	fetched []string
+}
+
+func (f *fakeSource) GetRepository(ctx context.Context, owner, repo string) (*models.Repository, error) {
+	return &models.Repository{FullName: owner + "/" + repo, DefaultBranch: "main"}, nil
+}
+
+func (f *fakeSource) ListDocumentation(ctx context.Context, owner, repo, defaultBranch, ref string) (*github.DocumentationListing, error) {
+	listing := &github.DocumentationListing{Owner: owner, Repo: repo, Ref: defaultBranch, CommitSHA: f.commit, BlobSHAs: map[string]string{}, Complete: true}
+	for p, sha := range f.files {
+		listing.Paths = append(listing.Paths, p)
+		listing.BlobSHAs[p] = sha
+	}
+	return listing, nil
+}
+
+func (f *fakeSource) FetchDocuments(ctx context.Context, listing *github.DocumentationListing, paths []string, concurrencyLimit int) ([]models.Documentation, error) {
+	var docs []models.Documentation
+	for _, p := range paths {
+		f.fetched = append(f.fetched, p)
+		docs = append(docs, models.Documentation{Path: p, SHA: f.files[p], Content: "content of " + f.files[p], CommitSHA: listing.CommitSHA})
+	}
+	return docs, nil
+}
+
+// TestReindex tests that only added and changed blobs are fetched and removed ones dropped
+func TestReindex(t *testing.T) {
+	server := miniredis.RunT(t)
+	store, err := cache.NewRedisClient(cache.RedisConfig{RedisURI: "redis://" + server.Addr(), Enabled: true, DefaultTTL: time.Hour})
+	require.NoError(t, err)
+
+	keys := cache.NewKeyBuilder("test")
+	source := &fakeSource{commit: "c1", files: map[string]string{"docs/a.md": "a1", "docs/b.md": "b1", "docs/c.md": "c1"}}
+	ix := NewIndexer(source, store, keys, 2, log.New(io.Discard, "", 0))
+	ctx := context.Background()
+
+	summary, err := ix.Reindex(ctx, "owner", "repo", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs/a.md", "docs/b.md", "docs/c.md"}, summary.Added)
+	assert.Equal(t, "main", summary.Ref)
+	assert.Equal(t, "c1", summary.CommitSHA)
+
+	// b changes, c is deleted and d is added
+	source.commit = "c2"
+	source.files = map[string]string{"docs/a.md": "a1", "docs/b.md": "b2", "docs/d.md": "d1"}
+	source.fetched = nil
+
+	summary, err = ix.Reindex(ctx, "owner", "repo", "")
+	require.NoError(t, err)
+	assert.Equal(t, "c1", summary.PreviousCommitSHA)
+	assert.Equal(t, "c2", summary.CommitSHA)
+	assert.Equal(t, []string{"docs/d.md"}, summary.Added)
+	assert.Equal(t, []string{"docs/b.md"}, summary.Changed)
+	assert.Equal(t, []string{"docs/c.md"}, summary.Removed)
+	assert.Equal(t, 1, summary.Unchanged)
+	sort.Strings(source.fetched)
+	assert.Equal(t, []string{"docs/b.md", "docs/d.md"}, source.fetched)
+
+	// The documentation endpoint's metadata index points at the new commit
+	var index models.RepositoryDocumentationIndex
+	require.NoError(t, store.Get(ctx, keys.RepositoryDocumentationMetadataKey("owner", "repo", ""), &index))
+	assert.Equal(t, "c2", index.CommitSHA)
+	assert.Equal(t, 3, index.DocumentCount)
+
+	var doc models.Documentation
+	require.NoError(t, store.Get(ctx, keys.DocumentContentKey("owner", "repo", "", "docs/a.md", "a1"), &doc))
+	assert.Equal(t, "c2", doc.CommitSHA)
+	assert.Error(t, store.Get(ctx, keys.DocumentContentKey("owner", "repo", "", "docs/c.md", "c1"), &doc))
+}
+
+// TestReindex_SameBlob tests that files with identical content keep their own path
+func TestReindex_SameBlob(t *testing.T) {
+	server := miniredis.RunT(t)
+	store, err := cache.NewRedisClient(cache.RedisConfig{RedisURI: "redis://" + server.Addr(), Enabled: true, DefaultTTL: time.Hour})
+	require.NoError(t, err)
+
+	source := &fakeSource{commit: "c1", files: map[string]string{"docs/a.md": "x1", "docs/copy.md": "x1"}}
+	ix := NewIndexer(source, store, cache.NewKeyBuilder("test"), 2, log.New(io.Discard, "", 0))
+	ctx := context.Background()
+
+	_, err = ix.Reindex(ctx, "owner", "repo", "")
+	require.NoError(t, err)
+
+	// Both unchanged documents are reused with their own path
+	source.commit, source.fetched = "c2", nil
+	summary, err := ix.Reindex(ctx, "owner", "repo", "")
+	require.NoError(t, err)
+	assert.Empty(t, source.fetched)
+	require.Len(t, summary.Documents, 2)
+	assert.Equal(t, "docs/a.md", summary.Documents[0].Path)
+	assert.Equal(t, "docs/copy.md", summary.Documents[1].Path)
+
+	// Removing the copy keeps the content of the other path cached
+	source.commit, source.files = "c3", map[string]string{"docs/a.md": "x1"}
+	summary, err = ix.Reindex(ctx, "owner", "repo", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs/copy.md"}, summary.Removed)
+
+	source.commit = "c4"
+	summary, err = ix.Reindex(ctx, "owner", "repo", "")
+	require.NoError(t, err)
+	assert.Empty(t, source.fetched)
+	require.Len(t, summary.Documents, 1)
+	assert.Equal(t, "docs/a.md", summary.Documents[0].Path)
+}
+
+// TestReindex_CacheDisabled tests that indexing requires a cache
+func TestReindex_CacheDisabled(t *testing.T) {
+	ix := NewIndexer(&fakeSource{}, &cache.RedisClient{}, cache.NewKeyBuilder("test"), 2, log.New(io.Discard, "", 0))
+	_, err := ix.Reindex(context.Background(), "owner", "repo", "")
+	assert.ErrorIs(t, err, ErrIndexUnavailable)
+}
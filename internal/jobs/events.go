@@ -0,0 +1,122 @@
+package jobs
+
+import (
+	"sync"
+
+	"github.com/dtomacheski/extract-data-go/internal/progress"
+)
+
+// Events of the job itself, sent along with its progress events
+const (
+	// EventJobStarted reports that a worker took the job
+	EventJobStarted progress.EventType = "job_started"
+
+	// EventJobFinished reports the final state of the job; it is the last event of a stream
+	EventJobFinished progress.EventType = "job_finished"
+
+	// EventJobInterrupted reports that the job stopped with the process and is queued
+	// again; it also ends a stream
+	EventJobInterrupted progress.EventType = "job_interrupted"
+)
+
+// subscriberBuffer is the number of events kept for a slow subscriber before new ones are dropped
+const subscriberBuffer = 256
+
+// Event is a progress event of a job, with the state and progress of the job after it
+type Event struct {
+	progress.Event
+	JobID     string   `json:"job_id"`
+	State     State    `json:"state"`
+	Progress  Progress `json:"progress"`
+	CommitSHA string   `json:"commit_sha,omitempty"`
+}
+
+// NewEvent pairs a progress event with the current state of a job
+func NewEvent(job *Job, event progress.Event) Event {
+	return Event{
+		Event:     event,
+		JobID:     job.ID,
+		State:     job.State,
+		Progress:  job.Progress,
+		CommitSHA: job.CommitSHA,
+	}
+}
+
+// FinishedEvent is the last event of a job that finished
+func FinishedEvent(job *Job) Event {
+	return NewEvent(job, progress.Event{Type: EventJobFinished, Error: job.Error})
+}
+
+// broker passes the events of the jobs running in this process to their subscribers
+type broker struct {
+	mu   sync.Mutex
+	subs map[string]map[chan Event]struct{}
+}
+
+// newBroker creates an event broker without subscribers
+func newBroker() *broker {
+	return &broker{subs: make(map[string]map[chan Event]struct{})}
+}
+
+// subscribe registers a channel receiving the events of a job
+func (b *broker) subscribe(id string) chan Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Event, subscriberBuffer)
+	if b.subs[id] == nil {
+		b.subs[id] = make(map[chan Event]struct{})
+	}
+	b.subs[id][ch] = struct{}{}
+	return ch
+}
+
+// unsubscribe removes and closes a channel, unless the job's events already ended
+func (b *broker) unsubscribe(id string, ch chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subs[id][ch]; !ok {
+		return
+	}
+	delete(b.subs[id], ch)
+	if len(b.subs[id]) == 0 {
+		delete(b.subs, id)
+	}
+	close(ch)
+}
+
+// publish passes an event to the subscribers of its job without blocking the job:
+// subscribers too slow to empty their buffer miss events
+func (b *broker) publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs[event.JobID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// end passes the last event of a job to its subscribers and closes their channels.
+// The oldest buffered event gives way to it when a buffer is full.
+func (b *broker) end(last Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs[last.JobID] {
+		select {
+		case ch <- last:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- last
+		}
+		close(ch)
+	}
+	delete(b.subs, last.JobID)
+}
@@ -0,0 +1,105 @@
+// Package jobs runs repository indexing asynchronously: jobs are queued, picked up by
+// workers that fetch, extract and store the documentation, and report their progress.
+package jobs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// State is the lifecycle state of a job
+type State string
+
+// Job states
+const (
+	StateQueued    State = "queued"
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+)
+
+// Stages of a running job
+const (
+	StageFetching   = "fetching"
+	StageExtracting = "extracting"
+	StageStoring    = "storing"
+)
+
+// maxJobErrors bounds the per-file errors kept on a job
+const maxJobErrors = 50
+
+var (
+	// ErrJobNotFound is returned for unknown or expired job IDs
+	ErrJobNotFound = errors.New("job not found")
+
+	// ErrQueueFull is returned when the in-process queue cannot take more jobs
+	ErrQueueFull = errors.New("job queue is full")
+)
+
+// Progress reports how far a job got
+type Progress struct {
+	Stage             string `json:"stage,omitempty"`
+	FilesTotal        int    `json:"files_total"`
+	FilesFetched      int    `json:"files_fetched"`
+	FilesFailed       int    `json:"files_failed"`
+	SnippetsExtracted int    `json:"snippets_extracted"`
+}
+
+// Job is a request to index the documentation of a repository at a ref
+type Job struct {
+	ID       string   `json:"id"`
+	Owner    string   `json:"owner"`
+	Repo     string   `json:"repo"`
+	Ref      string   `json:"ref,omitempty"` // Empty for the default branch
+	State    State    `json:"state"`
+	Progress Progress `json:"progress"`
+
+	// CommitSHA is the commit the documentation was indexed at
+	CommitSHA string `json:"commit_sha,omitempty"`
+
+	// Error is why the job failed; Errors lists the files that could not be read
+	Error  string   `json:"error,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+
+	// Attempts counts the runs of the job, more than one when a restart interrupted it
+	Attempts int `json:"attempts"`
+
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// NewJob creates a queued job for a repository
+func NewJob(owner, repo, ref string) *Job {
+	return &Job{
+		ID:        newJobID(),
+		Owner:     owner,
+		Repo:      repo,
+		Ref:       ref,
+		State:     StateQueued,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Finished reports whether the job reached a final state
+func (j *Job) Finished() bool {
+	return j.State == StateSucceeded || j.State == StateFailed
+}
+
+// addError records a per-file error, keeping at most maxJobErrors of them
+func (j *Job) addError(message string) {
+	if len(j.Errors) < maxJobErrors {
+		j.Errors = append(j.Errors, message)
+	}
+}
+
+// newJobID returns a random job ID
+func newJobID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("jobs: failed to generate job ID: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
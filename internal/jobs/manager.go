@@ -0,0 +1,260 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/dtomacheski/extract-data-go/internal/progress"
+)
+
+// progressSaveInterval limits how often the progress of a running job is saved
+const progressSaveInterval = time.Second
+
+// Manager queues indexing jobs and runs them on a pool of workers
+type Manager struct {
+	queue   Queue
+	runner  Runner
+	workers int
+	timeout time.Duration
+	logger  *log.Logger
+	events  *broker
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewManager creates a job manager running up to workers jobs at a time, each for at most timeout
+func NewManager(queue Queue, runner Runner, workers int, timeout time.Duration, logger *log.Logger) *Manager {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &Manager{
+		queue:   queue,
+		runner:  runner,
+		workers: workers,
+		timeout: timeout,
+		logger:  logger,
+		events:  newBroker(),
+	}
+}
+
+// Submit queues a job indexing a repository at a ref (the default branch when empty)
+func (m *Manager) Submit(ctx context.Context, owner, repo, ref string) (*Job, error) {
+	job := NewJob(owner, repo, ref)
+	if err := m.queue.Enqueue(ctx, job); err != nil {
+		return nil, err
+	}
+	m.logger.Printf("Queued indexing job %s for %s/%s (ref: %s)", job.ID, owner, repo, ref)
+	return job, nil
+}
+
+// Get returns a job by ID
+func (m *Manager) Get(ctx context.Context, id string) (*Job, error) {
+	return m.queue.Get(ctx, id)
+}
+
+// Subscribe returns the current state of a job and a channel receiving its events
+// until it stops running, when the channel is closed after an EventJobFinished or
+// EventJobInterrupted event.
+// Only the events of jobs running in this process are received; the channel of a
+// job already finished holds its EventJobFinished event alone. unsubscribe must be
+// called once the events are no longer read.
+func (m *Manager) Subscribe(ctx context.Context, id string) (job *Job, events <-chan Event, unsubscribe func(), err error) {
+	// Subscribe before reading the job so that no event is lost in between
+	ch := m.events.subscribe(id)
+	job, err = m.queue.Get(ctx, id)
+	if err != nil {
+		m.events.unsubscribe(id, ch)
+		return nil, nil, nil, err
+	}
+	if job.Finished() {
+		m.events.unsubscribe(id, ch)
+		done := make(chan Event, 1)
+		done <- FinishedEvent(job)
+		close(done)
+		return job, done, func() {}, nil
+	}
+	return job, ch, func() { m.events.unsubscribe(id, ch) }, nil
+}
+
+// Start queues again the jobs interrupted by the last shutdown and starts the workers
+func (m *Manager) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+
+	if recovered, err := m.queue.Recover(ctx); err != nil {
+		m.logger.Printf("Failed to recover interrupted indexing jobs: %v", err)
+	} else if recovered > 0 {
+		m.logger.Printf("Queued %d interrupted indexing jobs again", recovered)
+	}
+
+	for i := 0; i < m.workers; i++ {
+		m.wg.Add(1)
+		go m.work(ctx)
+	}
+	m.logger.Printf("Started %d indexing job workers", m.workers)
+}
+
+// Stop cancels the running jobs and waits for the workers to exit. The cancelled
+// jobs stay queued and run again after a restart when the queue is persistent.
+func (m *Manager) Stop() {
+	if m.cancel == nil {
+		return
+	}
+	m.cancel()
+	m.wg.Wait()
+	m.logger.Println("Indexing job workers stopped")
+}
+
+// work runs queued jobs until ctx is cancelled
+func (m *Manager) work(ctx context.Context) {
+	defer m.wg.Done()
+
+	for {
+		job, err := m.queue.Dequeue(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			m.logger.Printf("Failed to take an indexing job: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		m.run(ctx, job)
+	}
+}
+
+// run runs one job, tracking its progress, and saves its outcome
+func (m *Manager) run(ctx context.Context, job *Job) {
+	now := time.Now()
+	job.State = StateRunning
+	job.StartedAt, job.FinishedAt = &now, nil
+	job.Attempts++
+	job.Progress, job.Error, job.Errors = Progress{}, "", nil
+	if err := m.queue.Save(ctx, job); err != nil {
+		m.logger.Printf("Failed to save indexing job %s: %v", job.ID, err)
+	}
+	m.logger.Printf("Running indexing job %s for %s/%s (ref: %s, attempt %d)", job.ID, job.Owner, job.Repo, job.Ref, job.Attempts)
+	m.events.publish(NewEvent(job, progress.Event{Type: EventJobStarted}))
+
+	t := newTracker(ctx, m.queue, job, m.events, m.logger)
+	runCtx := progress.WithReporter(ctx, t.report)
+	if m.timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(runCtx, m.timeout)
+		defer cancel()
+	}
+
+	commitSHA, err := m.runner.Run(runCtx, *job)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Interrupted by shutdown: left unacknowledged so that it runs again
+	if ctx.Err() != nil {
+		job.State = StateQueued
+		if err := m.queue.Save(context.Background(), job); err != nil {
+			m.logger.Printf("Failed to save interrupted indexing job %s: %v", job.ID, err)
+		}
+		m.events.end(NewEvent(job, progress.Event{Type: EventJobInterrupted}))
+		return
+	}
+
+	finished := time.Now()
+	job.FinishedAt = &finished
+	if err != nil {
+		job.State, job.Error = StateFailed, err.Error()
+		m.logger.Printf("Indexing job %s for %s/%s failed: %v", job.ID, job.Owner, job.Repo, err)
+	} else {
+		job.State, job.CommitSHA = StateSucceeded, commitSHA
+		m.logger.Printf("Indexing job %s for %s/%s finished at %s in %s", job.ID, job.Owner, job.Repo, commitSHA, finished.Sub(now).Round(time.Millisecond))
+	}
+
+	if err := m.queue.Save(ctx, job); err != nil {
+		m.logger.Printf("Failed to save indexing job %s: %v", job.ID, err)
+	}
+	if err := m.queue.Ack(ctx, job); err != nil {
+		m.logger.Printf("Failed to acknowledge indexing job %s: %v", job.ID, err)
+	}
+	m.events.end(FinishedEvent(job))
+}
+
+// tracker applies the progress events of a running job to it and saves it periodically
+type tracker struct {
+	ctx    context.Context
+	queue  Queue
+	events *broker
+	logger *log.Logger
+
+	mu       sync.Mutex
+	job      *Job
+	fetched  map[string]bool
+	failed   map[string]bool
+	lastSave time.Time
+}
+
+// newTracker creates the tracker of a running job
+func newTracker(ctx context.Context, queue Queue, job *Job, events *broker, logger *log.Logger) *tracker {
+	return &tracker{
+		ctx:      ctx,
+		queue:    queue,
+		events:   events,
+		logger:   logger,
+		job:      job,
+		fetched:  make(map[string]bool),
+		failed:   make(map[string]bool),
+		lastSave: time.Now(),
+	}
+}
+
+// report implements progress.Reporter and passes the event on to the job's subscribers.
+// Files are counted once even when a fetch strategy falls back to another one.
+func (t *tracker) report(event progress.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p := &t.job.Progress
+	save := false
+	switch event.Type {
+	case progress.StageStarted:
+		p.Stage = event.Stage
+		save = true
+	case progress.PathsFound:
+		p.FilesTotal = event.Count
+	case progress.FileFetched:
+		if t.fetched[event.Path] {
+			break
+		}
+		t.fetched[event.Path] = true
+		p.FilesFetched++
+		if t.failed[event.Path] {
+			delete(t.failed, event.Path)
+			p.FilesFailed--
+		}
+	case progress.FileFailed:
+		if t.fetched[event.Path] || t.failed[event.Path] {
+			break
+		}
+		t.failed[event.Path] = true
+		p.FilesFailed++
+		t.job.addError(event.Path + ": " + event.Error)
+	case progress.SnippetsExtracted:
+		p.SnippetsExtracted = event.Count
+		save = true
+	}
+	t.events.publish(NewEvent(t.job, event))
+
+	if save || time.Since(t.lastSave) >= progressSaveInterval {
+		t.lastSave = time.Now()
+		if err := t.queue.Save(t.ctx, t.job); err != nil && !errors.Is(err, context.Canceled) {
+			t.logger.Printf("Failed to save progress of indexing job %s: %v", t.job.ID, err)
+		}
+	}
+}
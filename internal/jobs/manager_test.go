@@ -0,0 +1,141 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/dtomacheski/extract-data-go/internal/progress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner reports the progress of a fetch with a fallback and fails for repo "broken"
+type fakeRunner struct{}
+
+func (fakeRunner) Run(ctx context.Context, job Job) (string, error) {
+	if job.Repo == "broken" {
+		return "", errors.New("repository not found")
+	}
+	progress.Report(ctx, progress.Event{Type: progress.StageStarted, Stage: StageFetching})
+	progress.Report(ctx, progress.Event{Type: progress.PathsFound, Count: 3})
+	progress.ReportFile(ctx, "README.md", nil)
+	progress.ReportFile(ctx, "docs/a.md", errors.New("timeout"))
+	progress.ReportFile(ctx, "docs/b.md", errors.New("not found"))
+	// A fallback strategy reads the files again
+	progress.ReportFile(ctx, "README.md", nil)
+	progress.ReportFile(ctx, "docs/a.md", nil)
+	progress.Report(ctx, progress.Event{Type: progress.SnippetsExtracted, Count: 7})
+	return "abc123", nil
+}
+
+// waitFinished polls a job until it reaches a final state
+func waitFinished(t *testing.T, m *Manager, id string) *Job {
+	var job *Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = m.Get(context.Background(), id)
+		return err == nil && job.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+// TestManager tests that jobs run in the background and report progress and errors
+func TestManager(t *testing.T) {
+	m := NewManager(NewMemoryQueue(10), fakeRunner{}, 2, time.Minute, log.New(io.Discard, "", 0))
+	m.Start()
+	defer m.Stop()
+	ctx := context.Background()
+
+	job, err := m.Submit(ctx, "owner", "repo", "v1")
+	require.NoError(t, err)
+	assert.Equal(t, StateQueued, job.State)
+
+	job = waitFinished(t, m, job.ID)
+	assert.Equal(t, StateSucceeded, job.State)
+	assert.Equal(t, "abc123", job.CommitSHA)
+	assert.Equal(t, Progress{Stage: StageFetching, FilesTotal: 3, FilesFetched: 2, FilesFailed: 1, SnippetsExtracted: 7}, job.Progress)
+	assert.Equal(t, []string{"docs/a.md: timeout", "docs/b.md: not found"}, job.Errors)
+	assert.Equal(t, 1, job.Attempts)
+
+	failed, err := m.Submit(ctx, "owner", "broken", "")
+	require.NoError(t, err)
+	failed = waitFinished(t, m, failed.ID)
+	assert.Equal(t, StateFailed, failed.State)
+	assert.Equal(t, "repository not found", failed.Error)
+
+	_, err = m.Get(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
+
+// TestMemoryQueue_Full tests that submissions beyond the queue capacity are refused
+func TestMemoryQueue_Full(t *testing.T) {
+	queue := NewMemoryQueue(1)
+	require.NoError(t, queue.Enqueue(context.Background(), NewJob("owner", "a", "")))
+	assert.ErrorIs(t, queue.Enqueue(context.Background(), NewJob("owner", "b", "")), ErrQueueFull)
+}
+
+// gatedRunner runs fakeRunner once release is closed
+type gatedRunner struct {
+	release chan struct{}
+}
+
+func (r gatedRunner) Run(ctx context.Context, job Job) (string, error) {
+	<-r.release
+	return fakeRunner{}.Run(ctx, job)
+}
+
+// TestManager_Subscribe tests that subscribers receive the events of a running job until it finishes
+func TestManager_Subscribe(t *testing.T) {
+	runner := gatedRunner{release: make(chan struct{})}
+	m := NewManager(NewMemoryQueue(10), runner, 1, time.Minute, log.New(io.Discard, "", 0))
+	m.Start()
+	defer m.Stop()
+	ctx := context.Background()
+
+	job, err := m.Submit(ctx, "owner", "repo", "")
+	require.NoError(t, err)
+
+	current, events, unsubscribe, err := m.Subscribe(ctx, job.ID)
+	require.NoError(t, err)
+	defer unsubscribe()
+	assert.False(t, current.Finished())
+	close(runner.release)
+
+	var types []progress.EventType
+	var last Event
+	for event := range events {
+		types = append(types, event.Type)
+		last = event
+	}
+	// The job may already be running when subscribing
+	if types[0] == EventJobStarted {
+		types = types[1:]
+	}
+	assert.Equal(t, []progress.EventType{
+		progress.StageStarted, progress.PathsFound,
+		progress.FileFetched, progress.FileFailed, progress.FileFailed,
+		progress.FileFetched, progress.FileFetched,
+		progress.SnippetsExtracted, EventJobFinished,
+	}, types)
+	assert.Equal(t, job.ID, last.JobID)
+	assert.Equal(t, StateSucceeded, last.State)
+	assert.Equal(t, "abc123", last.CommitSHA)
+	assert.Equal(t, 7, last.Progress.SnippetsExtracted)
+
+	// A finished job only yields its final event
+	_, events, unsubscribe, err = m.Subscribe(ctx, job.ID)
+	require.NoError(t, err)
+	defer unsubscribe()
+	final, ok := <-events
+	require.True(t, ok)
+	assert.Equal(t, EventJobFinished, final.Type)
+	_, ok = <-events
+	assert.False(t, ok)
+
+	_, _, _, err = m.Subscribe(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
@@ -0,0 +1,113 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/dtomacheski/extract-data-go/internal/github"
+	"github.com/dtomacheski/extract-data-go/internal/indexer"
+	"github.com/dtomacheski/extract-data-go/internal/models"
+	"github.com/dtomacheski/extract-data-go/internal/processor"
+	"github.com/dtomacheski/extract-data-go/internal/progress"
+)
+
+// Runner does the work of a job and returns the commit it indexed. Progress is
+// reported with progress.Report on the context.
+type Runner interface {
+	Run(ctx context.Context, job Job) (string, error)
+}
+
+// Source fetches the documentation of GitHub repositories
+type Source interface {
+	GetRepository(ctx context.Context, owner, repo string) (*models.Repository, error)
+	GetRepositoryDocumentation(ctx context.Context, owner, repo, defaultBranch, ref string, concurrencyLimit int) ([]models.Documentation, error)
+}
+
+// Reindexer refreshes the cached documentation of a repository incrementally
+type Reindexer interface {
+	Reindex(ctx context.Context, owner, repo, ref string) (*indexer.Summary, error)
+}
+
+// Store keeps the processed documentation of repositories
+type Store interface {
+	IsEnabled() bool
+	StoreProcessedDocumentation(ctx context.Context, repoOwner, repoName, ref, commitSHA, filename, formattedText string, snippetsCount int) error
+}
+
+// Pipeline is the Runner of indexing jobs: it fetches the documentation of the
+// repository, extracts its code snippets and stores the processed text
+type Pipeline struct {
+	Source      Source
+	Indexer     Reindexer // Optional; without it every file is fetched on each run
+	Store       Store
+	Formatter   *processor.TextFormatter
+	Concurrency int
+	Logger      *log.Logger
+}
+
+// NewPipeline creates the indexing pipeline
+func NewPipeline(source Source, ix Reindexer, store Store, concurrency int, logger *log.Logger) *Pipeline {
+	return &Pipeline{
+		Source:      source,
+		Indexer:     ix,
+		Store:       store,
+		Formatter:   processor.NewTextFormatter(),
+		Concurrency: concurrency,
+		Logger:      logger,
+	}
+}
+
+// Run implements Runner
+func (p *Pipeline) Run(ctx context.Context, job Job) (string, error) {
+	progress.Report(ctx, progress.Event{Type: progress.StageStarted, Stage: StageFetching})
+	documentation, err := p.fetch(ctx, job)
+	if err != nil {
+		return "", err
+	}
+	if len(documentation) == 0 {
+		return "", github.ErrNoDocs
+	}
+	ref, commitSHA := models.RevisionOf(documentation)
+
+	progress.Report(ctx, progress.Event{Type: progress.StageStarted, Stage: StageExtracting})
+	filename, formattedText, snippetsCount := p.Formatter.ProcessAndFormatDocumentationContext(ctx, documentation, job.Owner, job.Repo)
+	progress.Report(ctx, progress.Event{Type: progress.SnippetsExtracted, Count: snippetsCount})
+
+	if snippetsCount == 0 || p.Store == nil || !p.Store.IsEnabled() {
+		p.Logger.Printf("Job %s: nothing to store for %s/%s (%d snippets)", job.ID, job.Owner, job.Repo, snippetsCount)
+		return commitSHA, nil
+	}
+
+	progress.Report(ctx, progress.Event{Type: progress.StageStarted, Stage: StageStoring})
+	if err := p.Store.StoreProcessedDocumentation(ctx, job.Owner, job.Repo, ref, commitSHA, filename, formattedText, snippetsCount); err != nil {
+		return "", err
+	}
+	progress.Report(ctx, progress.Event{Type: progress.Stored, Path: filename})
+
+	return commitSHA, nil
+}
+
+// fetch reads the documentation of the job's repository, incrementally when the
+// indexer can keep an index in the cache
+func (p *Pipeline) fetch(ctx context.Context, job Job) ([]models.Documentation, error) {
+	if p.Indexer != nil {
+		summary, err := p.Indexer.Reindex(ctx, job.Owner, job.Repo, job.Ref)
+		if err == nil {
+			return summary.Documents, nil
+		}
+		if !errors.Is(err, indexer.ErrIndexUnavailable) {
+			return nil, err
+		}
+	}
+
+	var defaultBranch string
+	if job.Ref == "" {
+		repoInfo, err := p.Source.GetRepository(ctx, job.Owner, job.Repo)
+		if err != nil {
+			return nil, err
+		}
+		defaultBranch = repoInfo.DefaultBranch
+	}
+	return p.Source.GetRepositoryDocumentation(ctx, job.Owner, job.Repo, defaultBranch, job.Ref, p.Concurrency)
+}
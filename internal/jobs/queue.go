@@ -0,0 +1,125 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// jobRetention is how long finished jobs can still be looked up
+const jobRetention = 24 * time.Hour
+
+// Queue stores jobs and hands queued ones to the workers
+type Queue interface {
+	// Enqueue stores a new job and queues it
+	Enqueue(ctx context.Context, job *Job) error
+
+	// Dequeue blocks until a queued job is available or the context is done
+	Dequeue(ctx context.Context) (*Job, error)
+
+	// Save stores the state and progress of a job
+	Save(ctx context.Context, job *Job) error
+
+	// Get returns a job by ID, or ErrJobNotFound
+	Get(ctx context.Context, id string) (*Job, error)
+
+	// Ack marks a dequeued job as done with, so that it is not run again after a restart
+	Ack(ctx context.Context, job *Job) error
+
+	// Recover queues again the jobs a stopped process dequeued but did not acknowledge,
+	// returning how many there were
+	Recover(ctx context.Context) (int, error)
+}
+
+// MemoryQueue is a Queue kept in process memory; its jobs are lost on restart
+type MemoryQueue struct {
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	pending chan string
+}
+
+// NewMemoryQueue creates an in-process queue holding up to capacity queued jobs
+func NewMemoryQueue(capacity int) *MemoryQueue {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	return &MemoryQueue{
+		jobs:    make(map[string]*Job),
+		pending: make(chan string, capacity),
+	}
+}
+
+// Enqueue implements Queue
+func (q *MemoryQueue) Enqueue(ctx context.Context, job *Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	select {
+	case q.pending <- job.ID:
+	default:
+		return ErrQueueFull
+	}
+
+	q.prune()
+	stored := *job
+	q.jobs[job.ID] = &stored
+	return nil
+}
+
+// Dequeue implements Queue
+func (q *MemoryQueue) Dequeue(ctx context.Context) (*Job, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case id := <-q.pending:
+			if job, err := q.Get(ctx, id); err == nil {
+				return job, nil
+			}
+		}
+	}
+}
+
+// Save implements Queue
+func (q *MemoryQueue) Save(ctx context.Context, job *Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stored := *job
+	stored.Errors = append([]string(nil), job.Errors...)
+	q.jobs[job.ID] = &stored
+	return nil
+}
+
+// Get implements Queue
+func (q *MemoryQueue) Get(ctx context.Context, id string) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stored, ok := q.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	job := *stored
+	job.Errors = append([]string(nil), stored.Errors...)
+	return &job, nil
+}
+
+// Ack implements Queue; dequeued jobs are not tracked in memory
+func (q *MemoryQueue) Ack(ctx context.Context, job *Job) error {
+	return nil
+}
+
+// Recover implements Queue; an in-process queue has nothing to recover
+func (q *MemoryQueue) Recover(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// prune drops jobs finished more than jobRetention ago; q.mu must be held
+func (q *MemoryQueue) prune() {
+	for id, job := range q.jobs {
+		if job.FinishedAt != nil && time.Since(*job.FinishedAt) > jobRetention {
+			delete(q.jobs, id)
+		}
+	}
+}
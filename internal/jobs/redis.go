@@ -0,0 +1,129 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dtomacheski/extract-data-go/internal/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// unfinishedJobTTL is how long queued and running jobs are kept in Redis
+const unfinishedJobTTL = 7 * 24 * time.Hour
+
+// dequeueTimeout bounds each blocking wait for a job, so that cancellation is noticed
+const dequeueTimeout = 2 * time.Second
+
+// RedisQueue is a Queue kept in Redis, so that jobs survive restarts. Jobs are stored as
+// JSON under their own key; their IDs move from a queue list to an in-flight list when a
+// worker takes them, and leave it when the worker acknowledges them.
+//
+// Recover assumes a single process drains the queue: it queues again every in-flight job.
+type RedisQueue struct {
+	client *redis.Client
+	keys   *cache.KeyBuilder
+}
+
+// NewRedisQueue creates a queue stored in Redis under the keys of the key builder
+func NewRedisQueue(client *redis.Client, keys *cache.KeyBuilder) *RedisQueue {
+	return &RedisQueue{client: client, keys: keys}
+}
+
+// Enqueue implements Queue
+func (q *RedisQueue) Enqueue(ctx context.Context, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, q.keys.JobKey(job.ID), data, unfinishedJobTTL)
+		pipe.LPush(ctx, q.keys.JobQueueKey(), job.ID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return nil
+}
+
+// Dequeue implements Queue
+func (q *RedisQueue) Dequeue(ctx context.Context) (*Job, error) {
+	for {
+		id, err := q.client.BLMove(ctx, q.keys.JobQueueKey(), q.keys.JobsInFlightKey(), "RIGHT", "LEFT", dequeueTimeout).Result()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to dequeue job: %w", err)
+		}
+
+		job, err := q.Get(ctx, id)
+		if errors.Is(err, ErrJobNotFound) {
+			// Expired while queued
+			q.client.LRem(ctx, q.keys.JobsInFlightKey(), 1, id)
+			continue
+		}
+		return job, err
+	}
+}
+
+// Save implements Queue
+func (q *RedisQueue) Save(ctx context.Context, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	ttl := unfinishedJobTTL
+	if job.Finished() {
+		ttl = jobRetention
+	}
+	if err := q.client.Set(ctx, q.keys.JobKey(job.ID), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+	return nil
+}
+
+// Get implements Queue
+func (q *RedisQueue) Get(ctx context.Context, id string) (*Job, error) {
+	data, err := q.client.Get(ctx, q.keys.JobKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job: %w", err)
+	}
+
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("invalid job %s: %w", id, err)
+	}
+	return &job, nil
+}
+
+// Ack implements Queue
+func (q *RedisQueue) Ack(ctx context.Context, job *Job) error {
+	return q.client.LRem(ctx, q.keys.JobsInFlightKey(), 1, job.ID).Err()
+}
+
+// Recover implements Queue. In-flight jobs go to the front of the queue.
+func (q *RedisQueue) Recover(ctx context.Context) (int, error) {
+	recovered := 0
+	for {
+		_, err := q.client.LMove(ctx, q.keys.JobsInFlightKey(), q.keys.JobQueueKey(), "RIGHT", "RIGHT").Result()
+		if errors.Is(err, redis.Nil) {
+			return recovered, nil
+		}
+		if err != nil {
+			return recovered, fmt.Errorf("failed to recover jobs: %w", err)
+		}
+		recovered++
+	}
+}
@@ -0,0 +1,54 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/dtomacheski/extract-data-go/internal/cache"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRedisQueue tests that jobs taken but not acknowledged are queued again after a restart
+func TestRedisQueue(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	queue := NewRedisQueue(client, cache.NewKeyBuilder("test"))
+	ctx := context.Background()
+
+	first, second := NewJob("owner", "first", ""), NewJob("owner", "second", "main")
+	require.NoError(t, queue.Enqueue(ctx, first))
+	require.NoError(t, queue.Enqueue(ctx, second))
+
+	// Jobs are taken in submission order
+	job, err := queue.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, job.ID)
+	job.State = StateSucceeded
+	require.NoError(t, queue.Save(ctx, job))
+	require.NoError(t, queue.Ack(ctx, job))
+
+	job, err = queue.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, job.ID)
+	assert.Equal(t, "main", job.Ref)
+
+	// The process stops before acknowledging the second job
+	recovered, err := queue.Recover(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, recovered)
+
+	job, err = queue.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, job.ID)
+
+	stored, err := queue.Get(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StateSucceeded, stored.State)
+
+	_, err = queue.Get(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
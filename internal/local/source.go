@@ -0,0 +1,293 @@
+// Package local reads documentation from directories and git repositories on
+// the local filesystem, without any network access.
+package local
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dtomacheski/extract-data-go/internal/discovery"
+	"github.com/dtomacheski/extract-data-go/internal/github"
+	"github.com/dtomacheski/extract-data-go/internal/models"
+)
+
+// Owner is the owner reported for local repositories, so that their
+// documentation is stored as local/<name>
+const Owner = "local"
+
+// ErrOutsideRoot is returned for paths outside the configured source root
+var ErrOutsideRoot = errors.New("path is outside the local source root")
+
+// skippedDirs are never walked when reading plain directories
+var skippedDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// Source reads documentation from directories and bare or non-bare git
+// repositories located under a root directory. Repositories are addressed
+// by their parent directory (owner) and directory name (repo).
+type Source struct {
+	root    string
+	timeout time.Duration
+}
+
+// NewSource creates a local source serving the repositories under root
+func NewSource(root string, timeout time.Duration) (*Source, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid local source root %s: %w", root, err)
+	}
+
+	return &Source{
+		root:    resolved,
+		timeout: timeout,
+	}, nil
+}
+
+// GetRepository describes the directory or git repository at owner/repo
+func (s *Source) GetRepository(ctx context.Context, owner, repo string) (*models.Repository, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	dir, err := s.resolve(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	info, _ := os.Stat(dir)
+	name := strings.TrimSuffix(filepath.Base(dir), ".git")
+	repository := &models.Repository{
+		Name:      name,
+		FullName:  Owner + "/" + name,
+		UpdatedAt: info.ModTime(),
+		URL:       "file://" + dir,
+		HTMLURL:   "file://" + dir,
+	}
+
+	if isGitRepository(ctx, dir) {
+		// The branch HEAD points at, also in bare repositories
+		if branch, err := git(ctx, dir, "symbolic-ref", "--short", "HEAD"); err == nil {
+			repository.DefaultBranch = strings.TrimSpace(string(branch))
+		} else {
+			repository.DefaultBranch = "HEAD"
+		}
+	}
+
+	return repository, nil
+}
+
+// ListTags lists the tags of a git repository. Plain directories have none.
+func (s *Source) ListTags(ctx context.Context, owner, repo string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	dir, err := s.resolve(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	if !isGitRepository(ctx, dir) {
+		return nil, nil
+	}
+
+	out, err := git(ctx, dir, "tag", "--list", "--sort=-creatordate")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// GetRepositoryDocumentationInPath reads the documentation of owner/repo using the same
+// discovery rules as the remote sources. Git repositories are read from the commit ref
+// (or defaultBranch, or HEAD) resolves to, so uncommitted changes are ignored; plain
+// directories are read as they are on disk and do not support refs.
+func (s *Source) GetRepositoryDocumentationInPath(ctx context.Context, owner, repo, defaultBranch, ref, subPath string, concurrencyLimit int) ([]models.Documentation, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	dir, err := s.resolve(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	repoName := Owner + "/" + strings.TrimSuffix(filepath.Base(dir), ".git")
+
+	if !isGitRepository(ctx, dir) {
+		if ref != "" {
+			return nil, fmt.Errorf("ref '%s' requested but %s is not a git repository", ref, dir)
+		}
+		return s.readDirectory(ctx, dir, repoName, subPath, concurrencyLimit)
+	}
+
+	if ref == "" {
+		ref = defaultBranch
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	return s.readGitRevision(ctx, dir, repoName, ref, subPath, concurrencyLimit)
+}
+
+// readGitRevision reads the documentation files of a git repository at ref
+func (s *Source) readGitRevision(ctx context.Context, dir, repoName, ref, subPath string, concurrencyLimit int) ([]models.Documentation, error) {
+	out, err := git(ctx, dir, "rev-parse", "--verify", "--end-of-options", ref+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("ref '%s' not found in %s", ref, dir)
+	}
+	commitSHA := strings.TrimSpace(string(out))
+	log.Printf("Resolved ref '%s' of %s to commit %s", ref, dir, commitSHA)
+
+	out, err = git(ctx, dir, "ls-tree", "-r", "-z", "--full-tree", commitSHA)
+	if err != nil {
+		return nil, err
+	}
+
+	// Entries have the form "<mode> blob <sha>\t<path>"
+	blobSHAs := make(map[string]string)
+	var paths []string
+	for _, entry := range bytes.Split(out, []byte{0}) {
+		meta, path, ok := strings.Cut(string(entry), "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+		paths = append(paths, path)
+		blobSHAs[path] = fields[2]
+	}
+
+	docPaths := discovery.SelectDocPaths(paths, subPath)
+	if len(docPaths) == 0 {
+		return nil, github.ErrNoDocs
+	}
+
+	log.Printf("Reading %d documentation files from %s at %s", len(docPaths), dir, commitSHA)
+	return discovery.FetchDocuments(ctx, docPaths, concurrencyLimit, func(ctx context.Context, p string) (*models.Documentation, error) {
+		content, err := git(ctx, dir, "cat-file", "blob", blobSHAs[p])
+		if err != nil {
+			return nil, err
+		}
+
+		return &models.Documentation{
+			RepoName:    repoName,
+			Path:        p,
+			Content:     string(content),
+			ContentType: "file",
+			Size:        len(content),
+			SHA:         blobSHAs[p],
+			URL:         "file://" + dir + "@" + commitSHA + "/" + p,
+			Ref:         ref,
+			CommitSHA:   commitSHA,
+		}, nil
+	})
+}
+
+// readDirectory reads the documentation files of a plain directory
+func (s *Source) readDirectory(ctx context.Context, dir, repoName, subPath string, concurrencyLimit int) ([]models.Documentation, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && skippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+
+	docPaths := discovery.SelectDocPaths(paths, subPath)
+	if len(docPaths) == 0 {
+		return nil, github.ErrNoDocs
+	}
+
+	log.Printf("Reading %d documentation files from %s", len(docPaths), dir)
+	return discovery.FetchDocuments(ctx, docPaths, concurrencyLimit, func(ctx context.Context, p string) (*models.Documentation, error) {
+		path := filepath.Join(dir, filepath.FromSlash(p))
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		return &models.Documentation{
+			RepoName:    repoName,
+			Path:        p,
+			Content:     string(content),
+			ContentType: "file",
+			Size:        len(content),
+			URL:         "file://" + path,
+		}, nil
+	})
+}
+
+// resolve returns the directory of owner/repo, making sure it is inside the root
+func (s *Source) resolve(owner, repo string) (string, error) {
+	path := filepath.Join(owner, repo)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.root, path)
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", github.ErrNotFound
+	}
+
+	rel, err := filepath.Rel(s.root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrOutsideRoot, path)
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil || !info.IsDir() {
+		return "", github.ErrNotFound
+	}
+
+	return resolved, nil
+}
+
+// isGitRepository reports whether dir is a bare repository or the root of a work tree.
+// Directories inside a work tree are not, as git never looks above dir.
+func isGitRepository(ctx context.Context, dir string) bool {
+	_, err := git(ctx, dir, "rev-parse", "--git-dir")
+	return err == nil
+}
+
+// git runs a git command in dir and returns its standard output
+func git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	// Repositories under the root are trusted even when owned by another user
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir, "-c", "safe.directory=" + dir}, args...)...)
+	// Never look for a repository above dir
+	cmd.Env = append(os.Environ(), "GIT_CEILING_DIRECTORIES="+filepath.Dir(dir))
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s failed: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,100 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile writes a file, creating its parent directories
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+// runGit runs a git command in dir with a fixed identity
+func runGit(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+// TestSource_Directory tests reading a plain directory
+func TestSource_Directory(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "sdk", "README.md"), "# SDK\n")
+	writeFile(t, filepath.Join(root, "sdk", "docs", "intro.md"), "# Intro\n")
+	writeFile(t, filepath.Join(root, "sdk", "node_modules", "dep", "docs", "x.md"), "# Dep\n")
+
+	source, err := NewSource(root, 5*time.Second)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	repo, err := source.GetRepository(ctx, root, "sdk")
+	require.NoError(t, err)
+	assert.Equal(t, "local/sdk", repo.FullName)
+	assert.Empty(t, repo.DefaultBranch)
+
+	docs, err := source.GetRepositoryDocumentationInPath(ctx, root, "sdk", "", "", "", 2)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "docs/intro.md", docs[0].Path)
+	assert.Equal(t, "local/sdk", docs[0].RepoName)
+
+	_, err = source.GetRepositoryDocumentationInPath(ctx, root, "sdk", "", "v1", "", 2)
+	assert.Error(t, err)
+
+	_, err = source.GetRepository(ctx, root, "..")
+	assert.True(t, errors.Is(err, ErrOutsideRoot))
+}
+
+// TestSource_BareRepository tests reading a bare git repository at a tag
+func TestSource_BareRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := t.TempDir()
+	work := filepath.Join(t.TempDir(), "work")
+	writeFile(t, filepath.Join(work, "docs", "guide.md"), "# Guide v2.1\n")
+	runGit(t, root, "init", "-q", "-b", "main", work)
+	runGit(t, work, "add", ".")
+	runGit(t, work, "commit", "-q", "-m", "docs")
+	runGit(t, work, "tag", "v2.1")
+	writeFile(t, filepath.Join(work, "docs", "guide.md"), "# Guide v3\n")
+	runGit(t, work, "commit", "-q", "-am", "docs v3")
+	runGit(t, root, "clone", "-q", "--bare", work, filepath.Join(root, "internal-sdk.git"))
+
+	source, err := NewSource(root, 5*time.Second)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	repo, err := source.GetRepository(ctx, root, "internal-sdk.git")
+	require.NoError(t, err)
+	assert.Equal(t, "main", repo.DefaultBranch)
+
+	tags, err := source.ListTags(ctx, root, "internal-sdk.git")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v2.1"}, tags)
+
+	docs, err := source.GetRepositoryDocumentationInPath(ctx, root, "internal-sdk.git", "main", "v2.1", "", 2)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "# Guide v2.1\n", docs[0].Content)
+	assert.Equal(t, "v2.1", docs[0].Ref)
+	assert.Len(t, docs[0].CommitSHA, 40)
+
+	docs, err = source.GetRepositoryDocumentationInPath(ctx, root, "internal-sdk.git", "main", "", "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "# Guide v3\n", docs[0].Content)
+
+	_, err = source.GetRepositoryDocumentationInPath(ctx, root, "internal-sdk.git", "main", "v9", "", 2)
+	assert.Error(t, err)
+}
@@ -0,0 +1,189 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/dtomacheski/extract-data-go/internal/database"
+	"github.com/dtomacheski/extract-data-go/internal/processor"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Resource URI layout of stored documentation
+const (
+	docsURIScheme      = "mcpdocs://"
+	docsURIFile        = "snippets.txt"
+	docsLatestRef      = "latest" // Most recently stored documentation of any ref
+	docsResourcesLimit = 50
+)
+
+// DocumentStore is the subset of the document repository used to serve resources
+type DocumentStore interface {
+	ListProcessedDocumentation(ctx context.Context, offset, limit int) ([]database.DocStorage, error)
+	GetProcessedDocumentation(ctx context.Context, owner, repo, filename string) (*database.DocStorage, error)
+	GetCurrentDocumentation(ctx context.Context, owner, repo, ref string) (*database.DocStorage, error)
+	GetDocumentationVersion(ctx context.Context, owner, repo, commitSHA string) (*database.DocVersion, error)
+}
+
+// DocsResources exposes the processed TXT documentation stored by the
+// document repository as MCP resources
+type DocsResources struct {
+	store     DocumentStore
+	formatter *processor.TextFormatter
+}
+
+// NewDocsResources creates the documentation resource provider
+func NewDocsResources(store DocumentStore) *DocsResources {
+	return &DocsResources{
+		store:     store,
+		formatter: processor.NewTextFormatter(),
+	}
+}
+
+// DocsResourceURI returns the resource URI of a repository's stored documentation at a ref;
+// an empty ref addresses the most recently stored documentation ("latest")
+func DocsResourceURI(owner, repo, ref string) string {
+	if ref == "" {
+		ref = docsLatestRef
+	}
+	return fmt.Sprintf("%s%s/%s/%s/%s", docsURIScheme, url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(ref), docsURIFile)
+}
+
+// ListResources implements ResourceProvider
+func (d *DocsResources) ListResources(ctx context.Context, cursor string) ([]Resource, string, error) {
+	offset := 0
+	if cursor != "" {
+		var err error
+		offset, err = strconv.Atoi(cursor)
+		if err != nil || offset < 0 {
+			return nil, "", &Error{Code: CodeInvalidParams, Message: "invalid cursor"}
+		}
+	}
+
+	// Fetch one extra document to know whether another page exists
+	docs, err := d.store.ListProcessedDocumentation(ctx, offset, docsResourcesLimit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(docs) > docsResourcesLimit {
+		docs = docs[:docsResourcesLimit]
+		next = strconv.Itoa(offset + docsResourcesLimit)
+	}
+
+	resources := make([]Resource, 0, len(docs))
+	for _, doc := range docs {
+		owner, repo, ok := strings.Cut(doc.RepoName, "/")
+		if !ok {
+			continue
+		}
+		name := doc.RepoName
+		if doc.Ref != "" {
+			name += "@" + doc.Ref
+		}
+		resources = append(resources, Resource{
+			URI:         DocsResourceURI(owner, repo, doc.Ref),
+			Name:        name,
+			Description: fmt.Sprintf("%d code snippets extracted from the %s documentation", doc.SnippetsCount, doc.RepoName),
+			MimeType:    "text/plain",
+			Size:        doc.Size,
+		})
+	}
+	return resources, next, nil
+}
+
+// ListResourceTemplates implements ResourceProvider
+func (d *DocsResources) ListResourceTemplates() []ResourceTemplate {
+	return []ResourceTemplate{{
+		URITemplate: docsURIScheme + "{owner}/{repo}/{ref}/" + docsURIFile,
+		Name:        "Repository documentation snippets",
+		Description: "Code snippets extracted from a repository's documentation. Use a branch or tag as ref for its current version, a commit SHA for a stored version, or 'latest' for the most recent refresh.",
+		MimeType:    "text/plain",
+	}}
+}
+
+// ReadResource implements ResourceProvider. The ref is resolved against the stored
+// documentation: "latest", then the current version of a branch or tag, then a commit.
+func (d *DocsResources) ReadResource(ctx context.Context, uri string) ([]ResourceContents, error) {
+	owner, repo, ref, err := parseDocsResourceURI(uri)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrResourceNotFound, err)
+	}
+
+	content, err := d.readDocumentation(ctx, owner, repo, ref)
+	if errors.Is(err, database.ErrDocVersionNotFound) || errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("%w: no stored documentation for %s/%s at '%s'", ErrResourceNotFound, owner, repo, ref)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return []ResourceContents{{URI: uri, MimeType: "text/plain", Text: content}}, nil
+}
+
+// readDocumentation returns the stored documentation of a repository at a resource ref
+func (d *DocsResources) readDocumentation(ctx context.Context, owner, repo, ref string) (string, error) {
+	if ref == docsLatestRef {
+		doc, err := d.store.GetProcessedDocumentation(ctx, owner, repo, d.formatter.GenerateFilename(owner, repo))
+		if err == nil && doc == nil {
+			return "", database.ErrDocVersionNotFound
+		}
+		if err != nil {
+			return "", err
+		}
+		return doc.Content, nil
+	}
+
+	doc, err := d.store.GetCurrentDocumentation(ctx, owner, repo, ref)
+	if err == nil {
+		return doc.Content, nil
+	}
+	if !errors.Is(err, database.ErrDocVersionNotFound) {
+		return "", err
+	}
+
+	version, err := d.store.GetDocumentationVersion(ctx, owner, repo, ref)
+	if err != nil {
+		return "", err
+	}
+	return version.Content, nil
+}
+
+// UpdateListener returns a document repository listener that notifies the
+// clients subscribed to a refreshed document, at its ref or as the latest one
+func (d *DocsResources) UpdateListener(s *Server) func(owner, repo, ref, filename string) {
+	return func(owner, repo, ref, filename string) {
+		s.NotifyResourceUpdated(DocsResourceURI(owner, repo, ""))
+		if ref != "" {
+			s.NotifyResourceUpdated(DocsResourceURI(owner, repo, ref))
+		}
+	}
+}
+
+// parseDocsResourceURI splits mcpdocs://{owner}/{repo}/{ref}/snippets.txt
+func parseDocsResourceURI(uri string) (string, string, string, error) {
+	rest, ok := strings.CutPrefix(uri, docsURIScheme)
+	if !ok {
+		return "", "", "", fmt.Errorf("unsupported resource uri '%s'", uri)
+	}
+
+	parts := strings.Split(rest, "/")
+	if len(parts) != 4 || parts[3] != docsURIFile {
+		return "", "", "", fmt.Errorf("invalid resource uri '%s': expected %s{owner}/{repo}/{ref}/%s", uri, docsURIScheme, docsURIFile)
+	}
+
+	decoded := make([]string, 3)
+	for i, part := range parts[:3] {
+		value, err := url.PathUnescape(part)
+		if err != nil || value == "" {
+			return "", "", "", fmt.Errorf("invalid resource uri '%s'", uri)
+		}
+		decoded[i] = value
+	}
+	return decoded[0], decoded[1], decoded[2], nil
+}
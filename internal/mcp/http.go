@@ -0,0 +1,476 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTP transport constants
+const (
+	// SessionHeader carries the session ID on Streamable HTTP requests
+	SessionHeader = "Mcp-Session-Id"
+
+	maxMessageSize     = 4 << 20 // 4MB per POST body
+	outboundBufferSize = 64
+	heartbeatInterval  = 25 * time.Second
+	defaultIdleTimeout = 30 * time.Minute
+)
+
+// httpSession is a session reachable over HTTP. Server-initiated messages
+// are queued on outbound until a GET (or legacy SSE) stream drains them.
+type httpSession struct {
+	*Session
+	outbound chan []byte
+	subject  string // Authenticated subject that opened the session
+}
+
+// subjectContextKey carries the authenticated subject of an HTTP request
+type subjectContextKey struct{}
+
+// WithSubject records the authenticated subject of a request. A session is only
+// reachable by requests of the subject that opened it.
+func WithSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, subjectContextKey{}, subject)
+}
+
+// requestSubject returns the authenticated subject of a request, if any
+func requestSubject(r *http.Request) string {
+	subject, _ := r.Context().Value(subjectContextKey{}).(string)
+	return subject
+}
+
+// HTTPTransport serves MCP over the Streamable HTTP transport, with the older
+// HTTP+SSE transport as a fallback for clients that do not support it yet
+type HTTPTransport struct {
+	server           *Server
+	logger           *log.Logger
+	messagesEndpoint string
+	idleTimeout      time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+}
+
+// NewHTTPTransport creates an HTTP transport for the server. messagesEndpoint
+// is the absolute path where ServeMessages is mounted; it is announced to
+// legacy SSE clients.
+func NewHTTPTransport(server *Server, messagesEndpoint string, logger *log.Logger) *HTTPTransport {
+	return &HTTPTransport{
+		server:           server,
+		logger:           logger,
+		messagesEndpoint: messagesEndpoint,
+		idleTimeout:      defaultIdleTimeout,
+		sessions:         make(map[string]*httpSession),
+	}
+}
+
+// ServeHTTP implements the Streamable HTTP transport on a single endpoint:
+// POST sends messages, GET opens a stream for server-initiated messages and
+// DELETE terminates the session
+func (t *HTTPTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		t.handlePost(w, r)
+	case http.MethodGet:
+		t.handleGet(w, r)
+	case http.MethodDelete:
+		t.handleDelete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		writeHTTPError(w, http.StatusMethodNotAllowed, CodeInvalidRequest, "method not allowed")
+	}
+}
+
+// handlePost processes client messages sent over Streamable HTTP
+func (t *HTTPTransport) handlePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize+1))
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, CodeParseError, "failed to read request body")
+		return
+	}
+	if len(body) > maxMessageSize {
+		writeHTTPError(w, http.StatusRequestEntityTooLarge, CodeInvalidRequest, "message too large")
+		return
+	}
+
+	hasRequests, isInitialize, err := inspectMessages(body)
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, CodeParseError, "parse error: "+err.Error())
+		return
+	}
+
+	var sess *httpSession
+	if isInitialize {
+		sess = t.newSession(requestSubject(r))
+		w.Header().Set(SessionHeader, sess.ID())
+	} else {
+		var status int
+		sess, status = t.lookupSession(r, r.Header.Get(SessionHeader))
+		if sess == nil {
+			writeHTTPError(w, status, CodeInvalidRequest, http.StatusText(status)+": missing or unknown session")
+			return
+		}
+	}
+
+	// Notifications and responses only: acknowledge without a body
+	if !hasRequests {
+		t.server.handleMessage(r.Context(), sess.Session, body, sess.send)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	// Stream request-scoped notifications (e.g. progress) before the response
+	// when the client accepts SSE; otherwise reply with plain JSON
+	if !isInitialize && acceptsEventStream(r) {
+		t.streamResponse(w, r, sess, body)
+		return
+	}
+
+	resp := t.server.handleMessage(r.Context(), sess.Session, body, sess.send)
+	if resp == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(resp)
+}
+
+// streamResponse answers a POST with an SSE stream carrying request-scoped
+// notifications followed by the response
+func (t *HTTPTransport) streamResponse(w http.ResponseWriter, r *http.Request, sess *httpSession, body []byte) {
+	stream, ok := newSSEStream(w)
+	if !ok {
+		writeHTTPError(w, http.StatusInternalServerError, CodeInternalError, "streaming not supported")
+		return
+	}
+
+	resp := t.server.handleMessage(r.Context(), sess.Session, body, func(msg []byte) error {
+		return stream.send("message", msg)
+	})
+	if resp != nil {
+		if err := stream.send("message", resp); err != nil && t.logger != nil {
+			t.logger.Printf("Failed to write MCP response to stream: %v", err)
+		}
+	}
+}
+
+// handleGet opens a long-lived stream for server-initiated messages
+func (t *HTTPTransport) handleGet(w http.ResponseWriter, r *http.Request) {
+	if !acceptsEventStream(r) {
+		writeHTTPError(w, http.StatusMethodNotAllowed, CodeInvalidRequest, "GET requires Accept: text/event-stream")
+		return
+	}
+
+	sess, status := t.lookupSession(r, r.Header.Get(SessionHeader))
+	if sess == nil {
+		writeHTTPError(w, status, CodeInvalidRequest, http.StatusText(status)+": missing or unknown session")
+		return
+	}
+
+	stream, ok := newSSEStream(w)
+	if !ok {
+		writeHTTPError(w, http.StatusInternalServerError, CodeInternalError, "streaming not supported")
+		return
+	}
+
+	t.pump(r.Context(), sess, stream)
+}
+
+// handleDelete terminates a session at the client's request
+func (t *HTTPTransport) handleDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get(SessionHeader)
+	sess, status := t.lookupSession(r, id)
+	if sess == nil {
+		writeHTTPError(w, status, CodeInvalidRequest, http.StatusText(status)+": missing or unknown session")
+		return
+	}
+
+	t.closeSession(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ServeSSE implements the legacy HTTP+SSE transport stream. The first event
+// announces the endpoint where the client must POST its messages; responses
+// and notifications are then delivered on this stream.
+func (t *HTTPTransport) ServeSSE(w http.ResponseWriter, r *http.Request) {
+	stream, ok := newSSEStream(w)
+	if !ok {
+		writeHTTPError(w, http.StatusInternalServerError, CodeInternalError, "streaming not supported")
+		return
+	}
+
+	sess := t.newSession(requestSubject(r))
+	defer t.closeSession(sess.ID())
+
+	endpoint := fmt.Sprintf("%s?sessionId=%s", t.messagesEndpoint, sess.ID())
+	if err := stream.send("endpoint", []byte(endpoint)); err != nil {
+		return
+	}
+
+	t.pump(r.Context(), sess, stream)
+}
+
+// ServeMessages receives client messages for the legacy HTTP+SSE transport.
+// Responses are delivered asynchronously on the session's SSE stream.
+func (t *HTTPTransport) ServeMessages(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		writeHTTPError(w, http.StatusMethodNotAllowed, CodeInvalidRequest, "method not allowed")
+		return
+	}
+
+	sess, status := t.lookupSession(r, r.URL.Query().Get("sessionId"))
+	if sess == nil {
+		writeHTTPError(w, status, CodeInvalidRequest, http.StatusText(status)+": missing or unknown session")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize+1))
+	if err != nil || len(body) > maxMessageSize {
+		writeHTTPError(w, http.StatusBadRequest, CodeParseError, "failed to read request body")
+		return
+	}
+
+	// The request is handled outside the HTTP request lifecycle, bounded by
+	// the session: closing the stream cancels it
+	go func() {
+		resp := t.server.HandleMessage(context.Background(), sess.Session, body)
+		if resp != nil {
+			if err := sess.send(resp); err != nil && t.logger != nil {
+				t.logger.Printf("Failed to queue MCP response for session %s: %v", sess.ID(), err)
+			}
+		}
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// pump forwards queued server-initiated messages to a stream until the
+// client disconnects or the session is closed
+func (t *HTTPTransport) pump(ctx context.Context, sess *httpSession, stream *sseStream) {
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sess.Done():
+			return
+		case msg, ok := <-sess.outbound:
+			if !ok {
+				return
+			}
+			if err := stream.send("message", msg); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			// SSE comments keep proxies from closing idle connections
+			if err := stream.comment("ping"); err != nil {
+				return
+			}
+			sess.touch()
+		}
+	}
+}
+
+// newSession creates and registers a new HTTP session owned by subject
+func (t *HTTPTransport) newSession(subject string) *httpSession {
+	t.reapIdleSessions()
+
+	outbound := make(chan []byte, outboundBufferSize)
+	sess := &httpSession{outbound: outbound, subject: subject}
+	id := newSessionID()
+	sess.Session = NewSession(id, func(msg []byte) error {
+		select {
+		case outbound <- msg:
+			return nil
+		default:
+			return fmt.Errorf("outbound queue full for session %s", id)
+		}
+	})
+
+	t.mu.Lock()
+	t.sessions[id] = sess
+	t.mu.Unlock()
+
+	if t.logger != nil {
+		t.logger.Printf("MCP session %s opened", id)
+	}
+	return sess
+}
+
+// lookupSession finds a session of the request's subject by ID, returning the HTTP
+// status to use when it is missing (400) or unknown/expired (404). Sessions of
+// another subject are reported as unknown.
+func (t *HTTPTransport) lookupSession(r *http.Request, id string) (*httpSession, int) {
+	if id == "" {
+		return nil, http.StatusBadRequest
+	}
+
+	t.mu.Lock()
+	sess, ok := t.sessions[id]
+	t.mu.Unlock()
+	if !ok || sess.subject != requestSubject(r) {
+		return nil, http.StatusNotFound
+	}
+	return sess, http.StatusOK
+}
+
+// closeSession terminates and unregisters a session
+func (t *HTTPTransport) closeSession(id string) {
+	t.mu.Lock()
+	sess, ok := t.sessions[id]
+	delete(t.sessions, id)
+	t.mu.Unlock()
+
+	if ok {
+		sess.Close()
+		if t.logger != nil {
+			t.logger.Printf("MCP session %s closed", id)
+		}
+	}
+}
+
+// reapIdleSessions removes sessions without activity for longer than the idle timeout
+func (t *HTTPTransport) reapIdleSessions() {
+	t.mu.Lock()
+	var expired []string
+	for id, sess := range t.sessions {
+		if sess.idleSince() > t.idleTimeout {
+			expired = append(expired, id)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, id := range expired {
+		t.closeSession(id)
+	}
+}
+
+// Shutdown closes every open session, cancelling their in-flight requests
+func (t *HTTPTransport) Shutdown() {
+	t.mu.Lock()
+	ids := make([]string, 0, len(t.sessions))
+	for id := range t.sessions {
+		ids = append(ids, id)
+	}
+	t.mu.Unlock()
+
+	for _, id := range ids {
+		t.closeSession(id)
+	}
+}
+
+// inspectMessages reports whether a POST body contains requests (messages
+// expecting a response) and whether it is an initialize request
+func inspectMessages(body []byte) (hasRequests bool, isInitialize bool, err error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return false, false, fmt.Errorf("empty body")
+	}
+
+	var messages []Request
+	if trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &messages); err != nil {
+			return false, false, err
+		}
+	} else {
+		var msg Request
+		if err := json.Unmarshal(trimmed, &msg); err != nil {
+			return false, false, err
+		}
+		messages = []Request{msg}
+	}
+
+	for _, msg := range messages {
+		if msg.Method != "" && !msg.IsNotification() {
+			hasRequests = true
+		}
+		if msg.Method == "initialize" {
+			isInitialize = true
+		}
+	}
+	return hasRequests, isInitialize, nil
+}
+
+// acceptsEventStream reports whether the client accepts SSE responses
+func acceptsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// writeHTTPError writes a JSON-RPC error with the given HTTP status
+func writeHTTPError(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(encodeResponse(errorResponse(nil, code, message)))
+}
+
+// sseStream writes Server-Sent Events, serializing concurrent writers
+type sseStream struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// newSSEStream prepares the response for streaming. Long-lived streams must
+// not be cut by the server's WriteTimeout, so the write deadline is cleared.
+func newSSEStream(w http.ResponseWriter) (*sseStream, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, false
+	}
+
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return &sseStream{w: w, flusher: flusher}, true
+}
+
+// send writes one event; multi-line data is split into several data fields
+func (s *sseStream) send(event string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var buf bytes.Buffer
+	buf.WriteString("event: " + event + "\n")
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(line)
+		buf.WriteString("\n")
+	}
+	buf.WriteString("\n")
+
+	if _, err := s.w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// comment writes an SSE comment line
+func (s *sseStream) comment(text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.w.Write([]byte(": " + text + "\n\n")); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
@@ -0,0 +1,191 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// postMessage sends a JSON-RPC message to the Streamable HTTP endpoint
+func postMessage(t *testing.T, url, sessionID, accept, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	if sessionID != "" {
+		req.Header.Set(SessionHeader, sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+// TestHTTPTransport_StreamableHTTP tests the session lifecycle over Streamable HTTP
+func TestHTTPTransport_StreamableHTTP(t *testing.T) {
+	transport := NewHTTPTransport(newTestServer(), "/messages", nil)
+	srv := httptest.NewServer(transport)
+	defer srv.Close()
+
+	// Initialize creates a session
+	resp := postMessage(t, srv.URL, "", "application/json, text/event-stream",
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	sessionID := resp.Header.Get(SessionHeader)
+	require.NotEmpty(t, sessionID)
+	resp.Body.Close()
+
+	// Requests without a session are rejected
+	resp = postMessage(t, srv.URL, "", "application/json", `{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = postMessage(t, srv.URL, "unknown", "application/json", `{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+
+	// Notifications are acknowledged with 202
+	resp = postMessage(t, srv.URL, sessionID, "application/json", `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	resp.Body.Close()
+
+	// Plain JSON response
+	resp = postMessage(t, srv.URL, sessionID, "application/json",
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"message":"json"}}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	var decoded map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+	resp.Body.Close()
+	assert.Equal(t, float64(3), decoded["id"])
+
+	// SSE response when the client accepts event streams
+	resp = postMessage(t, srv.URL, sessionID, "application/json, text/event-stream",
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"echo","arguments":{"message":"sse"}}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, string(body), "event: message")
+	assert.Contains(t, string(body), `"text":"sse"`)
+
+	// DELETE terminates the session
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL, nil)
+	req.Header.Set(SessionHeader, sessionID)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = postMessage(t, srv.URL, sessionID, "application/json", `{"jsonrpc":"2.0","id":5,"method":"ping"}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+}
+
+// TestHTTPTransport_SessionSubject tests that a session is only reachable by the subject that opened it
+func TestHTTPTransport_SessionSubject(t *testing.T) {
+	transport := NewHTTPTransport(newTestServer(), "/messages", nil)
+	mux := http.NewServeMux()
+	mux.Handle("/", transport)
+	mux.HandleFunc("/messages", transport.ServeMessages)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r.WithContext(WithSubject(r.Context(), r.Header.Get("X-Subject"))))
+	}))
+	defer srv.Close()
+
+	send := func(method, url, subject, sessionID, body string) int {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		req.Header.Set("X-Subject", subject)
+		if sessionID != "" {
+			req.Header.Set(SessionHeader, sessionID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`))
+	req.Header.Set("X-Subject", "alice")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	sessionID := resp.Header.Get(SessionHeader)
+	require.NotEmpty(t, sessionID)
+
+	// Another subject cannot post to, stream, delete or message the session
+	ping := `{"jsonrpc":"2.0","id":2,"method":"ping"}`
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPost, srv.URL, "bob", sessionID, ping))
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, srv.URL, "bob", sessionID, ""))
+	assert.Equal(t, http.StatusNotFound, send(http.MethodDelete, srv.URL, "bob", sessionID, ""))
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPost, srv.URL+"/messages?sessionId="+sessionID, "bob", "", ping))
+
+	// The session is still usable by its subject
+	assert.Equal(t, http.StatusOK, send(http.MethodPost, srv.URL, "alice", sessionID, ping))
+	assert.Equal(t, http.StatusNoContent, send(http.MethodDelete, srv.URL, "alice", sessionID, ""))
+}
+
+// TestHTTPTransport_LegacySSE tests the HTTP+SSE fallback transport
+func TestHTTPTransport_LegacySSE(t *testing.T) {
+	transport := NewHTTPTransport(newTestServer(), "/messages", nil)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sse", transport.ServeSSE)
+	mux.HandleFunc("/messages", transport.ServeMessages)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/sse", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	event, data := readEvent(t, reader)
+	require.Equal(t, "endpoint", event)
+	require.True(t, strings.HasPrefix(data, "/messages?sessionId="))
+
+	post, err := http.Post(srv.URL+data, "application/json",
+		strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, post.StatusCode)
+	post.Body.Close()
+
+	// The response arrives on the SSE stream
+	event, data = readEvent(t, reader)
+	assert.Equal(t, "message", event)
+	assert.Contains(t, data, `"id":1`)
+}
+
+// readEvent reads a single SSE event, skipping comments
+func readEvent(t *testing.T, reader *bufio.Reader) (string, string) {
+	t.Helper()
+	var event string
+	var data []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "" && event != "":
+			return event, strings.Join(data, "\n")
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = append(data, strings.TrimPrefix(line, "data: "))
+		}
+	}
+}
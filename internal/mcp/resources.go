@@ -0,0 +1,222 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// CodeResourceNotFound is the MCP error code for unknown resource URIs
+const CodeResourceNotFound = -32002
+
+// ErrResourceNotFound is returned by resource providers for unknown URIs
+var ErrResourceNotFound = errors.New("resource not found")
+
+// Resource describes a concrete resource exposed to clients
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+	Size        int    `json:"size,omitempty"`
+}
+
+// ResourceTemplate describes a parameterized family of resources (RFC 6570)
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceContents is the content of a resource returned by resources/read
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text"`
+}
+
+// ListResourcesResult is the result of resources/list
+type ListResourcesResult struct {
+	Resources  []Resource `json:"resources"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+// ListResourceTemplatesResult is the result of resources/templates/list
+type ListResourceTemplatesResult struct {
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+}
+
+// ReadResourceResult is the result of resources/read
+type ReadResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// ResourcesCapability describes resource related features
+type ResourcesCapability struct {
+	Subscribe   bool `json:"subscribe"`
+	ListChanged bool `json:"listChanged"`
+}
+
+// ResourceProvider serves the resources behind the resources/* methods
+type ResourceProvider interface {
+	// ListResources returns a page of resources and the cursor of the next page
+	ListResources(ctx context.Context, cursor string) ([]Resource, string, error)
+
+	// ListResourceTemplates returns the URI templates understood by ReadResource
+	ListResourceTemplates() []ResourceTemplate
+
+	// ReadResource returns the contents of a resource, or ErrResourceNotFound
+	ReadResource(ctx context.Context, uri string) ([]ResourceContents, error)
+}
+
+// SetResourceProvider enables the resources capability backed by provider
+func (s *Server) SetResourceProvider(provider ResourceProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resources = provider
+}
+
+// resourceProvider returns the configured provider, if any
+func (s *Server) resourceProvider() ResourceProvider {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.resources
+}
+
+// NotifyResourceUpdated sends notifications/resources/updated to every
+// session subscribed to uri. Closed sessions are dropped.
+func (s *Server) NotifyResourceUpdated(uri string) {
+	s.subsMu.Lock()
+	subscribers := make([]*Session, 0, len(s.subscriptions[uri]))
+	for sess := range s.subscriptions[uri] {
+		subscribers = append(subscribers, sess)
+	}
+	s.subsMu.Unlock()
+
+	for _, sess := range subscribers {
+		err := sess.Notify("notifications/resources/updated", map[string]string{"uri": uri})
+		if errors.Is(err, ErrSessionClosed) {
+			s.unsubscribe(sess, uri)
+		} else if err != nil && s.logger != nil {
+			s.logger.Printf("Failed to notify MCP session %s about %s: %v", sess.ID(), uri, err)
+		}
+	}
+}
+
+// subscribe registers a session for updates of uri
+func (s *Server) subscribe(sess *Session, uri string) {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+
+	if s.subscriptions[uri] == nil {
+		s.subscriptions[uri] = make(map[*Session]struct{})
+	}
+	s.subscriptions[uri][sess] = struct{}{}
+}
+
+// unsubscribe removes a session's subscription to uri
+func (s *Server) unsubscribe(sess *Session, uri string) {
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+
+	delete(s.subscriptions[uri], sess)
+	if len(s.subscriptions[uri]) == 0 {
+		delete(s.subscriptions, uri)
+	}
+}
+
+// handleListResources implements resources/list
+func (s *Server) handleListResources(ctx context.Context, params json.RawMessage) (interface{}, *Error) {
+	provider := s.resourceProvider()
+	if provider == nil {
+		return nil, &Error{Code: CodeMethodNotFound, Message: "resources are not supported"}
+	}
+
+	var p struct {
+		Cursor string `json:"cursor"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &Error{Code: CodeInvalidParams, Message: "invalid resources/list params: " + err.Error()}
+		}
+	}
+
+	resources, next, err := provider.ListResources(ctx, p.Cursor)
+	if err != nil {
+		if rpcErr, ok := err.(*Error); ok {
+			return nil, rpcErr
+		}
+		return nil, &Error{Code: CodeInternalError, Message: err.Error()}
+	}
+	if resources == nil {
+		resources = []Resource{}
+	}
+	return ListResourcesResult{Resources: resources, NextCursor: next}, nil
+}
+
+// handleListResourceTemplates implements resources/templates/list
+func (s *Server) handleListResourceTemplates() (interface{}, *Error) {
+	provider := s.resourceProvider()
+	if provider == nil {
+		return nil, &Error{Code: CodeMethodNotFound, Message: "resources are not supported"}
+	}
+
+	templates := provider.ListResourceTemplates()
+	if templates == nil {
+		templates = []ResourceTemplate{}
+	}
+	return ListResourceTemplatesResult{ResourceTemplates: templates}, nil
+}
+
+// handleReadResource implements resources/read
+func (s *Server) handleReadResource(ctx context.Context, params json.RawMessage) (interface{}, *Error) {
+	provider := s.resourceProvider()
+	if provider == nil {
+		return nil, &Error{Code: CodeMethodNotFound, Message: "resources are not supported"}
+	}
+
+	uri, rpcErr := resourceURIFromParams(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	contents, err := provider.ReadResource(ctx, uri)
+	if err != nil {
+		if errors.Is(err, ErrResourceNotFound) {
+			return nil, &Error{Code: CodeResourceNotFound, Message: err.Error(), Data: map[string]string{"uri": uri}}
+		}
+		return nil, &Error{Code: CodeInternalError, Message: err.Error()}
+	}
+	return ReadResourceResult{Contents: contents}, nil
+}
+
+// handleSubscribe implements resources/subscribe and resources/unsubscribe
+func (s *Server) handleSubscribe(sess *Session, params json.RawMessage, subscribe bool) (interface{}, *Error) {
+	if s.resourceProvider() == nil {
+		return nil, &Error{Code: CodeMethodNotFound, Message: "resources are not supported"}
+	}
+
+	uri, rpcErr := resourceURIFromParams(params)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	if subscribe {
+		s.subscribe(sess, uri)
+	} else {
+		s.unsubscribe(sess, uri)
+	}
+	return struct{}{}, nil
+}
+
+// resourceURIFromParams extracts the uri parameter shared by resource methods
+func resourceURIFromParams(params json.RawMessage) (string, *Error) {
+	var p struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil || p.URI == "" {
+		return "", &Error{Code: CodeInvalidParams, Message: "missing resource uri"}
+	}
+	return p.URI, nil
+}
@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/dtomacheski/extract-data-go/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDocumentStore serves processed documents from memory
+type fakeDocumentStore struct {
+	docs []database.DocStorage
+}
+
+func (f *fakeDocumentStore) ListProcessedDocumentation(ctx context.Context, offset, limit int) ([]database.DocStorage, error) {
+	if offset >= len(f.docs) {
+		return nil, nil
+	}
+	end := offset + limit
+	if end > len(f.docs) {
+		end = len(f.docs)
+	}
+	return f.docs[offset:end], nil
+}
+
+func (f *fakeDocumentStore) GetProcessedDocumentation(ctx context.Context, owner, repo, filename string) (*database.DocStorage, error) {
+	path := "/" + owner + "/" + repo + "/" + filename
+	for i := range f.docs {
+		if f.docs[i].ProcessedPath == path {
+			return &f.docs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeDocumentStore) GetCurrentDocumentation(ctx context.Context, owner, repo, ref string) (*database.DocStorage, error) {
+	for i := range f.docs {
+		if f.docs[i].RepoName == owner+"/"+repo && f.docs[i].Ref == ref {
+			return &f.docs[i], nil
+		}
+	}
+	return nil, database.ErrDocVersionNotFound
+}
+
+func (f *fakeDocumentStore) GetDocumentationVersion(ctx context.Context, owner, repo, commitSHA string) (*database.DocVersion, error) {
+	for _, doc := range f.docs {
+		if doc.RepoName == owner+"/"+repo && doc.CommitSHA == commitSHA {
+			return &database.DocVersion{RepoName: doc.RepoName, Ref: doc.Ref, CommitSHA: doc.CommitSHA, Content: "TITLE: Stored at " + commitSHA}, nil
+		}
+	}
+	return nil, database.ErrDocVersionNotFound
+}
+
+// newResourceTestServer creates a server backed by a single stored document
+func newResourceTestServer() (*Server, *DocsResources) {
+	s := newTestServer()
+	resources := NewDocsResources(&fakeDocumentStore{docs: []database.DocStorage{{
+		RepoName:      "vercel/next.js",
+		Ref:           "canary",
+		CommitSHA:     "abc123",
+		Filename:      "next-js-docs.txt",
+		ProcessedPath: "/vercel/next.js/next-js-docs.txt",
+		Size:          11,
+		SnippetsCount: 1,
+		Content:       "TITLE: Demo",
+	}}})
+	s.SetResourceProvider(resources)
+	return s, resources
+}
+
+// TestServer_Resources tests resources/list, resources/templates/list and resources/read
+func TestServer_Resources(t *testing.T) {
+	s, _ := newResourceTestServer()
+	ctx := context.Background()
+	sess := testSession()
+
+	resp := decodeResponse(t, s.HandleMessage(ctx, sess, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`)))
+	capabilities := resp["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	assert.Equal(t, true, capabilities["resources"].(map[string]interface{})["subscribe"])
+
+	resp = decodeResponse(t, s.HandleMessage(ctx, sess, []byte(`{"jsonrpc":"2.0","id":2,"method":"resources/list"}`)))
+	resources := resp["result"].(map[string]interface{})["resources"].([]interface{})
+	require.Len(t, resources, 1)
+	assert.Equal(t, "mcpdocs://vercel/next.js/canary/snippets.txt", resources[0].(map[string]interface{})["uri"])
+
+	resp = decodeResponse(t, s.HandleMessage(ctx, sess, []byte(`{"jsonrpc":"2.0","id":3,"method":"resources/templates/list"}`)))
+	templates := resp["result"].(map[string]interface{})["resourceTemplates"].([]interface{})
+	require.Len(t, templates, 1)
+	assert.Equal(t, "mcpdocs://{owner}/{repo}/{ref}/snippets.txt", templates[0].(map[string]interface{})["uriTemplate"])
+
+	// The ref resolves to the latest document, the current document of a branch or tag, or a stored commit
+	for uri, text := range map[string]string{
+		"mcpdocs://vercel/next.js/latest/snippets.txt": "TITLE: Demo",
+		"mcpdocs://vercel/next.js/canary/snippets.txt": "TITLE: Demo",
+		"mcpdocs://vercel/next.js/abc123/snippets.txt": "TITLE: Stored at abc123",
+	} {
+		resp = decodeResponse(t, s.HandleMessage(ctx, sess, []byte(`{"jsonrpc":"2.0","id":4,"method":"resources/read","params":{"uri":"`+uri+`"}}`)))
+		contents := resp["result"].(map[string]interface{})["contents"].([]interface{})
+		require.Len(t, contents, 1, uri)
+		assert.Equal(t, uri, contents[0].(map[string]interface{})["uri"])
+		assert.Equal(t, text, contents[0].(map[string]interface{})["text"], uri)
+	}
+
+	// Unknown documents and refs are reported as resource not found
+	for _, uri := range []string{
+		"mcpdocs://vercel/swr/latest/snippets.txt",
+		"mcpdocs://vercel/next.js/v1.0.0/snippets.txt",
+		"mcpdocs://vercel/next.js",
+	} {
+		resp = decodeResponse(t, s.HandleMessage(ctx, sess, []byte(`{"jsonrpc":"2.0","id":5,"method":"resources/read","params":{"uri":"`+uri+`"}}`)))
+		assert.Equal(t, float64(CodeResourceNotFound), resp["error"].(map[string]interface{})["code"], uri)
+	}
+}
+
+// TestServer_ResourceSubscriptions tests notifications/resources/updated delivery
+func TestServer_ResourceSubscriptions(t *testing.T) {
+	s, resources := newResourceTestServer()
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var sent [][]byte
+	sess := NewSession("test", func(msg []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, msg)
+		return nil
+	})
+
+	resp := decodeResponse(t, s.HandleMessage(ctx, sess, []byte(`{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"mcpdocs://vercel/next.js/latest/snippets.txt"}}`)))
+	assert.Nil(t, resp["error"])
+
+	listener := resources.UpdateListener(s)
+	listener("vercel", "swr", "main", "swr-docs.txt")
+	listener("vercel", "next.js", "canary", "next-js-docs.txt")
+
+	require.Len(t, sent, 1)
+	notification := decodeResponse(t, sent[0])
+	assert.Equal(t, "notifications/resources/updated", notification["method"])
+	assert.Equal(t, "mcpdocs://vercel/next.js/latest/snippets.txt", notification["params"].(map[string]interface{})["uri"])
+
+	// Subscribers of the ref are notified too
+	s.HandleMessage(ctx, sess, []byte(`{"jsonrpc":"2.0","id":4,"method":"resources/subscribe","params":{"uri":"mcpdocs://vercel/next.js/canary/snippets.txt"}}`))
+	listener("vercel", "next.js", "canary", "next-js-docs.txt")
+	require.Len(t, sent, 3)
+	assert.Equal(t, "mcpdocs://vercel/next.js/canary/snippets.txt", decodeResponse(t, sent[2])["params"].(map[string]interface{})["uri"])
+	s.HandleMessage(ctx, sess, []byte(`{"jsonrpc":"2.0","id":5,"method":"resources/unsubscribe","params":{"uri":"mcpdocs://vercel/next.js/canary/snippets.txt"}}`))
+	sent = sent[:1]
+
+	// Unsubscribed and closed sessions are no longer notified
+	s.HandleMessage(ctx, sess, []byte(`{"jsonrpc":"2.0","id":2,"method":"resources/unsubscribe","params":{"uri":"mcpdocs://vercel/next.js/latest/snippets.txt"}}`))
+	listener("vercel", "next.js", "canary", "next-js-docs.txt")
+	assert.Len(t, sent, 1)
+
+	s.HandleMessage(ctx, sess, []byte(`{"jsonrpc":"2.0","id":3,"method":"resources/subscribe","params":{"uri":"mcpdocs://vercel/next.js/latest/snippets.txt"}}`))
+	sess.Close()
+	listener("vercel", "next.js", "canary", "next-js-docs.txt")
+	assert.Len(t, sent, 1)
+	assert.Empty(t, s.subscriptions)
+}
@@ -0,0 +1,323 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// ToolHandler executes a tool call with the raw JSON arguments sent by the client
+type ToolHandler func(ctx context.Context, arguments json.RawMessage) (*CallToolResult, error)
+
+// registeredTool pairs a tool definition with its handler
+type registeredTool struct {
+	tool    Tool
+	handler ToolHandler
+}
+
+// Server implements the Model Context Protocol on top of JSON-RPC 2.0.
+// It is transport agnostic: transports feed raw messages to HandleMessage.
+type Server struct {
+	info         Implementation
+	instructions string
+	logger       *log.Logger
+
+	mu        sync.RWMutex
+	tools     map[string]registeredTool
+	toolOrder []string
+	resources ResourceProvider
+
+	subsMu        sync.Mutex
+	subscriptions map[string]map[*Session]struct{}
+}
+
+// NewServer creates a new MCP server
+func NewServer(name, version string, logger *log.Logger) *Server {
+	return &Server{
+		info:   Implementation{Name: name, Version: version},
+		logger: logger,
+		tools:  make(map[string]registeredTool),
+
+		subscriptions: make(map[string]map[*Session]struct{}),
+	}
+}
+
+// SetInstructions sets the usage hints returned to clients on initialize
+func (s *Server) SetInstructions(instructions string) {
+	s.instructions = instructions
+}
+
+// RegisterTool adds a tool to the server, replacing any tool with the same name
+func (s *Server) RegisterTool(tool Tool, handler ToolHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.tools[tool.Name]; !exists {
+		s.toolOrder = append(s.toolOrder, tool.Name)
+	}
+	s.tools[tool.Name] = registeredTool{tool: tool, handler: handler}
+}
+
+// HandleMessage processes a single raw JSON-RPC message (or batch) received on
+// the given session and returns the encoded response. A nil result means no
+// response must be sent. Request-scoped notifications such as progress are
+// delivered through the session.
+func (s *Server) HandleMessage(ctx context.Context, sess *Session, data []byte) []byte {
+	return s.handleMessage(ctx, sess, data, sess.send)
+}
+
+// handleMessage is HandleMessage with an explicit destination for
+// request-scoped notifications, used by transports that stream them
+// alongside the response
+func (s *Server) handleMessage(ctx context.Context, sess *Session, data []byte, notify func(msg []byte) error) []byte {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil
+	}
+	sess.touch()
+
+	// Batch request
+	if trimmed[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(trimmed, &batch); err != nil || len(batch) == 0 {
+			return encodeResponse(errorResponse(nil, CodeParseError, "invalid batch"))
+		}
+
+		var responses []*Response
+		for _, item := range batch {
+			if resp := s.handleRaw(ctx, sess, item, notify); resp != nil {
+				responses = append(responses, resp)
+			}
+		}
+		if len(responses) == 0 {
+			return nil
+		}
+		out, err := json.Marshal(responses)
+		if err != nil {
+			return encodeResponse(errorResponse(nil, CodeInternalError, "failed to encode batch response"))
+		}
+		return out
+	}
+
+	resp := s.handleRaw(ctx, sess, trimmed, notify)
+	if resp == nil {
+		return nil
+	}
+	return encodeResponse(resp)
+}
+
+// handleRaw decodes and dispatches one JSON-RPC message
+func (s *Server) handleRaw(ctx context.Context, sess *Session, data []byte, notify func(msg []byte) error) *Response {
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return errorResponse(nil, CodeParseError, "parse error: "+err.Error())
+	}
+
+	// Responses from the client (to server-initiated requests) are accepted and ignored
+	if req.Method == "" && len(req.ID) > 0 {
+		return nil
+	}
+
+	if req.JSONRPC != JSONRPCVersion || req.Method == "" {
+		if req.IsNotification() {
+			return nil
+		}
+		return errorResponse(req.ID, CodeInvalidRequest, "invalid JSON-RPC request")
+	}
+
+	// Notifications never get a response, even when they fail
+	if req.IsNotification() {
+		if _, rpcErr := s.dispatch(ctx, sess, &req); rpcErr != nil && s.logger != nil {
+			s.logger.Printf("MCP notification %s failed: %s", req.Method, rpcErr.Message)
+		}
+		return nil
+	}
+
+	// Track the request so that notifications/cancelled can abort it
+	parentCtx := ctx
+	ctx, release := sess.track(ctx, req.ID)
+	defer release()
+
+	ctx = context.WithValue(ctx, requestContextKey{}, &requestState{
+		session:       sess,
+		progressToken: progressTokenFromParams(req.Params),
+		notify:        notify,
+	})
+
+	result, rpcErr := s.dispatch(ctx, sess, &req)
+
+	// Requests cancelled by the client must not receive a response
+	if ctx.Err() != nil && parentCtx.Err() == nil {
+		return nil
+	}
+
+	if rpcErr != nil {
+		return &Response{JSONRPC: JSONRPCVersion, ID: req.ID, Error: rpcErr}
+	}
+	return &Response{JSONRPC: JSONRPCVersion, ID: req.ID, Result: result}
+}
+
+// dispatch routes a request to its method implementation
+func (s *Server) dispatch(ctx context.Context, sess *Session, req *Request) (interface{}, *Error) {
+	switch req.Method {
+	case "initialize":
+		return s.handleInitialize(req.Params)
+	case "notifications/initialized":
+		return nil, nil
+	case "notifications/cancelled":
+		return s.handleCancelled(sess, req.Params)
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return s.handleListTools()
+	case "tools/call":
+		return s.handleCallTool(ctx, req.Params)
+	case "resources/list":
+		return s.handleListResources(ctx, req.Params)
+	case "resources/templates/list":
+		return s.handleListResourceTemplates()
+	case "resources/read":
+		return s.handleReadResource(ctx, req.Params)
+	case "resources/subscribe":
+		return s.handleSubscribe(sess, req.Params, true)
+	case "resources/unsubscribe":
+		return s.handleSubscribe(sess, req.Params, false)
+	default:
+		return nil, &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+}
+
+// handleCancelled aborts an in-flight request on behalf of the client
+func (s *Server) handleCancelled(sess *Session, params json.RawMessage) (interface{}, *Error) {
+	var p struct {
+		RequestID json.RawMessage `json:"requestId"`
+		Reason    string          `json:"reason"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil || len(p.RequestID) == 0 {
+		return nil, &Error{Code: CodeInvalidParams, Message: "invalid notifications/cancelled params"}
+	}
+
+	// Unknown or already finished requests are silently ignored, as required by the spec
+	if sess.cancelRequest(p.RequestID) && s.logger != nil {
+		s.logger.Printf("MCP request %s cancelled by client: %s", string(p.RequestID), p.Reason)
+	}
+	return nil, nil
+}
+
+// handleInitialize negotiates the protocol version and advertises capabilities
+func (s *Server) handleInitialize(params json.RawMessage) (interface{}, *Error) {
+	var p InitializeParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &Error{Code: CodeInvalidParams, Message: "invalid initialize params: " + err.Error()}
+		}
+	}
+
+	// Echo the client's version when we support it, otherwise offer our latest
+	version := LatestProtocolVersion
+	for _, v := range supportedProtocolVersions {
+		if v == p.ProtocolVersion {
+			version = v
+			break
+		}
+	}
+
+	if s.logger != nil {
+		s.logger.Printf("MCP client connected: %s %s (protocol %s)", p.ClientInfo.Name, p.ClientInfo.Version, version)
+	}
+
+	capabilities := ServerCapabilities{
+		Tools: &ToolsCapability{ListChanged: false},
+	}
+	if s.resourceProvider() != nil {
+		capabilities.Resources = &ResourcesCapability{Subscribe: true, ListChanged: false}
+	}
+
+	return InitializeResult{
+		ProtocolVersion: version,
+		Capabilities:    capabilities,
+		ServerInfo:      s.info,
+		Instructions:    s.instructions,
+	}, nil
+}
+
+// handleListTools returns all registered tools in registration order
+func (s *Server) handleListTools() (interface{}, *Error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tools := make([]Tool, 0, len(s.toolOrder))
+	for _, name := range s.toolOrder {
+		tools = append(tools, s.tools[name].tool)
+	}
+	return ListToolsResult{Tools: tools}, nil
+}
+
+// handleCallTool executes a registered tool
+func (s *Server) handleCallTool(ctx context.Context, params json.RawMessage) (interface{}, *Error) {
+	var p CallToolParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: "invalid tools/call params: " + err.Error()}
+	}
+
+	s.mu.RLock()
+	registered, ok := s.tools[p.Name]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("unknown tool: %s", p.Name)}
+	}
+
+	result, err := registered.handler(ctx, p.Arguments)
+	if err != nil {
+		// Protocol level errors are reported as JSON-RPC errors; anything else
+		// is surfaced to the model as a failed tool result.
+		if rpcErr, ok := err.(*Error); ok {
+			return nil, rpcErr
+		}
+		if s.logger != nil {
+			s.logger.Printf("MCP tool %s failed: %v", p.Name, err)
+		}
+		return ErrorResult(err.Error()), nil
+	}
+	return result, nil
+}
+
+// errorResponse builds a JSON-RPC error response
+func errorResponse(id json.RawMessage, code int, message string) *Response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &Response{
+		JSONRPC: JSONRPCVersion,
+		ID:      id,
+		Error:   &Error{Code: code, Message: message},
+	}
+}
+
+// encodeResponse marshals a response, falling back to a static internal error
+func encodeResponse(resp *Response) []byte {
+	out, err := json.Marshal(resp)
+	if err != nil {
+		return []byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32603,"message":"failed to encode response"}}`)
+	}
+	return out
+}
+
+// progressTokenFromParams extracts params._meta.progressToken, if present
+func progressTokenFromParams(params json.RawMessage) json.RawMessage {
+	if len(params) == 0 {
+		return nil
+	}
+	var p struct {
+		Meta struct {
+			ProgressToken json.RawMessage `json:"progressToken"`
+		} `json:"_meta"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil
+	}
+	return p.Meta.ProgressToken
+}
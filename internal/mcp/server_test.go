@@ -0,0 +1,195 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer creates a server with a simple echo tool
+func newTestServer() *Server {
+	s := NewServer("test-server", "0.0.1", nil)
+	s.RegisterTool(Tool{
+		Name:        "echo",
+		Description: "Echoes the message argument",
+		InputSchema: json.RawMessage(`{"type":"object"}`),
+	}, func(ctx context.Context, arguments json.RawMessage) (*CallToolResult, error) {
+		var args struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			return nil, err
+		}
+		if args.Message == "" {
+			return nil, errors.New("message is required")
+		}
+		return TextResult(args.Message), nil
+	})
+	return s
+}
+
+// testSession creates a session that discards server-initiated messages
+func testSession() *Session {
+	return NewSession("test", func(msg []byte) error { return nil })
+}
+
+// decodeResponse decodes a raw response into a generic map
+func decodeResponse(t *testing.T, raw []byte) map[string]interface{} {
+	t.Helper()
+	require.NotNil(t, raw)
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &resp))
+	return resp
+}
+
+// TestServer_Initialize tests protocol version negotiation
+func TestServer_Initialize(t *testing.T) {
+	s := newTestServer()
+
+	raw := s.HandleMessage(context.Background(), testSession(), []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","clientInfo":{"name":"test","version":"1"}}}`))
+	resp := decodeResponse(t, raw)
+
+	result := resp["result"].(map[string]interface{})
+	assert.Equal(t, "2024-11-05", result["protocolVersion"])
+	assert.Equal(t, "test-server", result["serverInfo"].(map[string]interface{})["name"])
+	assert.Contains(t, result["capabilities"], "tools")
+
+	// Unknown versions fall back to the latest supported one
+	raw = s.HandleMessage(context.Background(), testSession(), []byte(`{"jsonrpc":"2.0","id":2,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`))
+	resp = decodeResponse(t, raw)
+	assert.Equal(t, LatestProtocolVersion, resp["result"].(map[string]interface{})["protocolVersion"])
+}
+
+// TestServer_Tools tests tools/list and tools/call
+func TestServer_Tools(t *testing.T) {
+	s := newTestServer()
+	ctx := context.Background()
+	sess := testSession()
+
+	resp := decodeResponse(t, s.HandleMessage(ctx, sess, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)))
+	tools := resp["result"].(map[string]interface{})["tools"].([]interface{})
+	require.Len(t, tools, 1)
+	assert.Equal(t, "echo", tools[0].(map[string]interface{})["name"])
+
+	resp = decodeResponse(t, s.HandleMessage(ctx, sess, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"message":"hello"}}}`)))
+	content := resp["result"].(map[string]interface{})["content"].([]interface{})
+	assert.Equal(t, "hello", content[0].(map[string]interface{})["text"])
+
+	// Tool failures are reported as error results, not protocol errors
+	resp = decodeResponse(t, s.HandleMessage(ctx, sess, []byte(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{}}}`)))
+	assert.Equal(t, true, resp["result"].(map[string]interface{})["isError"])
+
+	// Unknown tools are invalid params
+	resp = decodeResponse(t, s.HandleMessage(ctx, sess, []byte(`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"missing"}}`)))
+	assert.Equal(t, float64(CodeInvalidParams), resp["error"].(map[string]interface{})["code"])
+}
+
+// TestServer_ProtocolErrors tests JSON-RPC error handling
+func TestServer_ProtocolErrors(t *testing.T) {
+	s := newTestServer()
+	ctx := context.Background()
+	sess := testSession()
+
+	resp := decodeResponse(t, s.HandleMessage(ctx, sess, []byte(`{not json`)))
+	assert.Equal(t, float64(CodeParseError), resp["error"].(map[string]interface{})["code"])
+
+	resp = decodeResponse(t, s.HandleMessage(ctx, sess, []byte(`{"jsonrpc":"2.0","id":"a","method":"does/not/exist"}`)))
+	assert.Equal(t, "a", resp["id"])
+	assert.Equal(t, float64(CodeMethodNotFound), resp["error"].(map[string]interface{})["code"])
+
+	// Notifications never get a response
+	assert.Nil(t, s.HandleMessage(ctx, sess, []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)))
+	assert.Nil(t, s.HandleMessage(ctx, sess, []byte(`{"jsonrpc":"2.0","method":"does/not/exist"}`)))
+}
+
+// TestServer_Cancellation tests that notifications/cancelled aborts a request
+func TestServer_Cancellation(t *testing.T) {
+	s := newTestServer()
+	started := make(chan struct{})
+	s.RegisterTool(Tool{Name: "wait", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		func(ctx context.Context, arguments json.RawMessage) (*CallToolResult, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+	sess := testSession()
+	done := make(chan []byte)
+	go func() {
+		done <- s.HandleMessage(context.Background(), sess, []byte(`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"wait"}}`))
+	}()
+
+	<-started
+	assert.Nil(t, s.HandleMessage(context.Background(), sess, []byte(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7,"reason":"test"}}`)))
+
+	// Cancelled requests get no response
+	assert.Nil(t, <-done)
+}
+
+// TestNotifyProgress tests that progress is only sent when a token was provided
+func TestNotifyProgress(t *testing.T) {
+	s := newTestServer()
+	s.RegisterTool(Tool{Name: "progress", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		func(ctx context.Context, arguments json.RawMessage) (*CallToolResult, error) {
+			NotifyProgress(ctx, 1, 2, "halfway")
+			return TextResult("done"), nil
+		})
+
+	var sent [][]byte
+	sess := NewSession("test", func(msg []byte) error {
+		sent = append(sent, msg)
+		return nil
+	})
+
+	s.HandleMessage(context.Background(), sess, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"progress"}}`))
+	assert.Empty(t, sent)
+
+	s.HandleMessage(context.Background(), sess, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"progress","_meta":{"progressToken":"tok"}}}`))
+	require.Len(t, sent, 1)
+	notification := decodeResponse(t, sent[0])
+	assert.Equal(t, "notifications/progress", notification["method"])
+	assert.Equal(t, "tok", notification["params"].(map[string]interface{})["progressToken"])
+}
+
+// TestServer_ServeStdio tests the newline-delimited stdio transport
+func TestServer_ServeStdio(t *testing.T) {
+	s := newTestServer()
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"message":"hi"}}}`,
+	}, "\n") + "\n"
+
+	var out strings.Builder
+	err := s.ServeStdio(context.Background(), strings.NewReader(input), &out)
+	require.NoError(t, err)
+
+	// Responses may arrive in any order since requests are handled concurrently
+	ids := map[float64]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		resp := decodeResponse(t, scanner.Bytes())
+		assert.Nil(t, resp["error"])
+		ids[resp["id"].(float64)] = true
+	}
+	assert.Equal(t, map[float64]bool{1: true, 2: true, 3: true}, ids)
+}
+
+// TestParseLibraryID tests library ID parsing
+func TestParseLibraryID(t *testing.T) {
+	owner, repo, err := parseLibraryID("/vercel/next.js")
+	require.NoError(t, err)
+	assert.Equal(t, "vercel", owner)
+	assert.Equal(t, "next.js", repo)
+
+	_, _, err = parseLibraryID("next.js")
+	assert.Error(t, err)
+}
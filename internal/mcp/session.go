@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrSessionClosed is returned when sending to a terminated session
+var ErrSessionClosed = errors.New("mcp session closed")
+
+// Session holds the per-client state of an MCP connection: in-flight
+// requests (for cancellation) and the channel used for server-initiated
+// messages. Stdio uses a single session; HTTP transports create one per client.
+type Session struct {
+	id   string
+	send func(msg []byte) error
+
+	mu         sync.Mutex
+	inflight   map[string]context.CancelFunc
+	lastActive time.Time
+	closed     bool
+	done       chan struct{}
+}
+
+// NewSession creates a session whose server-initiated messages are delivered
+// through send. The send function must be safe for concurrent use.
+func NewSession(id string, send func(msg []byte) error) *Session {
+	return &Session{
+		id:         id,
+		send:       send,
+		inflight:   make(map[string]context.CancelFunc),
+		lastActive: time.Now(),
+		done:       make(chan struct{}),
+	}
+}
+
+// ID returns the session identifier
+func (s *Session) ID() string {
+	return s.id
+}
+
+// Notify sends a server-initiated notification to the client
+func (s *Session) Notify(method string, params interface{}) error {
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if closed {
+		return ErrSessionClosed
+	}
+
+	msg, err := json.Marshal(Notification{JSONRPC: JSONRPCVersion, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	return s.send(msg)
+}
+
+// Done returns a channel that is closed when the session terminates
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close cancels all in-flight requests and rejects further notifications
+func (s *Session) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.done)
+	for id, cancel := range s.inflight {
+		cancel()
+		delete(s.inflight, id)
+	}
+}
+
+// touch records activity on the session
+func (s *Session) touch() {
+	s.mu.Lock()
+	s.lastActive = time.Now()
+	s.mu.Unlock()
+}
+
+// idleSince returns how long the session has been inactive
+func (s *Session) idleSince() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Since(s.lastActive)
+}
+
+// track registers a cancellable in-flight request and returns its context
+// together with a release function that must be called when it completes
+func (s *Session) track(ctx context.Context, requestID json.RawMessage) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	key := string(requestID)
+
+	s.mu.Lock()
+	s.inflight[key] = cancel
+	s.mu.Unlock()
+
+	return ctx, func() {
+		s.mu.Lock()
+		delete(s.inflight, key)
+		s.mu.Unlock()
+		cancel()
+	}
+}
+
+// cancelRequest cancels an in-flight request, reporting whether it was found
+func (s *Session) cancelRequest(requestID json.RawMessage) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cancel, ok := s.inflight[string(requestID)]
+	if ok {
+		cancel()
+		delete(s.inflight, string(requestID))
+	}
+	return ok
+}
+
+// newSessionID generates a cryptographically random session identifier
+func newSessionID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand never fails on supported platforms; fall back to time
+		return hex.EncodeToString([]byte(time.Now().Format(time.RFC3339Nano)))
+	}
+	return hex.EncodeToString(b)
+}
+
+// requestContextKey is the context key for request-scoped MCP state
+type requestContextKey struct{}
+
+// requestState is attached to the context of every request handler
+type requestState struct {
+	session       *Session
+	progressToken json.RawMessage
+	notify        func(msg []byte) error
+}
+
+// SessionFromContext returns the session that issued the current request
+func SessionFromContext(ctx context.Context) *Session {
+	if state, ok := ctx.Value(requestContextKey{}).(*requestState); ok {
+		return state.session
+	}
+	return nil
+}
+
+// NotifyProgress sends a notifications/progress message for the current
+// request if the client asked for progress updates. It is a no-op otherwise.
+func NotifyProgress(ctx context.Context, progress, total float64, message string) {
+	state, ok := ctx.Value(requestContextKey{}).(*requestState)
+	if !ok || len(state.progressToken) == 0 {
+		return
+	}
+
+	params := map[string]interface{}{
+		"progressToken": state.progressToken,
+		"progress":      progress,
+	}
+	if total > 0 {
+		params["total"] = total
+	}
+	if message != "" {
+		params["message"] = message
+	}
+
+	msg, err := json.Marshal(Notification{JSONRPC: JSONRPCVersion, Method: "notifications/progress", Params: params})
+	if err != nil {
+		return
+	}
+	// Progress is best effort; a disconnected client must not fail the request
+	_ = state.notify(msg)
+}
@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ServeStdio serves MCP over newline-delimited JSON-RPC messages, as used by
+// editors and agents that launch the server as a subprocess. It returns when
+// the input is closed or the context is cancelled.
+func (s *Server) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		writeMu sync.Mutex
+		wg      sync.WaitGroup
+	)
+
+	write := func(msg []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+
+		if _, err := out.Write(append(msg, '\n')); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	// Stdio carries a single session for the lifetime of the process
+	sess := NewSession("stdio", write)
+	defer sess.Close()
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+
+	// Read messages in a separate goroutine so that cancellation is not
+	// blocked by a pending read on stdin
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+
+		case err := <-readErr:
+			// Let in-flight requests finish before returning
+			wg.Wait()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+
+		case line := <-lines:
+			// Requests are handled concurrently so a slow tool call does not
+			// block pings or other requests
+			wg.Add(1)
+			go func(msg []byte) {
+				defer wg.Done()
+
+				resp := s.HandleMessage(ctx, sess, msg)
+				if resp == nil {
+					return
+				}
+				if err := write(resp); err != nil && s.logger != nil {
+					s.logger.Printf("Failed to write MCP response: %v", err)
+				}
+			}(line)
+		}
+	}
+}
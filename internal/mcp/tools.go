@@ -0,0 +1,247 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/dtomacheski/extract-data-go/internal/github"
+	"github.com/dtomacheski/extract-data-go/internal/processor"
+	"github.com/dtomacheski/extract-data-go/internal/registry"
+)
+
+// Default and maximum number of snippets returned by get-library-docs
+const (
+	defaultSnippetLimit = 20
+	maxSnippetLimit     = 100
+)
+
+// resolveLibraryIDSchema is the input schema of the resolve-library-id tool
+var resolveLibraryIDSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"libraryName": {
+			"type": "string",
+			"description": "Library or project name to search for, e.g. 'next.js' or 'tailwindcss'"
+		}
+	},
+	"required": ["libraryName"]
+}`)
+
+// getLibraryDocsSchema is the input schema of the get-library-docs tool
+var getLibraryDocsSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"libraryId": {
+			"type": "string",
+			"description": "Library ID returned by resolve-library-id, in the form 'owner/repo'"
+		},
+		"ref": {
+			"type": "string",
+			"description": "Optional branch or tag to read documentation from. Defaults to the repository's default branch"
+		},
+		"version": {
+			"type": "string",
+			"description": "Optional version constraint resolved against the repository's tags, e.g. '^3', '~18.2', 'react@^18' or 'latest-stable'. Cannot be combined with ref"
+		},
+		"limit": {
+			"type": "integer",
+			"description": "Maximum number of code snippets to return (default 20, max 100). Ignored when tokens is set"
+		},
+		"tokens": {
+			"type": "integer",
+			"description": "Maximum number of tokens of documentation to return. Snippets are never cut in half"
+		},
+		"topic": {
+			"type": "string",
+			"description": "Optional topic to focus the documentation on, e.g. 'routing' or 'hooks'"
+		}
+	},
+	"required": ["libraryId"]
+}`)
+
+// DocsTools exposes repository documentation as MCP tools
+type DocsTools struct {
+	GitHubClient   *github.Client
+	Registry       *registry.Registry
+	WorkerPoolSize int
+	Logger         *log.Logger
+}
+
+// NewDocsTools creates the documentation toolset
+func NewDocsTools(client *github.Client, workerPoolSize int, logger *log.Logger) *DocsTools {
+	return &DocsTools{
+		GitHubClient:   client,
+		Registry:       registry.NewRegistry(client),
+		WorkerPoolSize: workerPoolSize,
+		Logger:         logger,
+	}
+}
+
+// Register adds the documentation tools to the server
+func (t *DocsTools) Register(s *Server) {
+	s.RegisterTool(Tool{
+		Name: "resolve-library-id",
+		Description: "Searches GitHub for a library and returns matching library IDs ('owner/repo'). " +
+			"Call this first to obtain the ID required by get-library-docs.",
+		InputSchema: resolveLibraryIDSchema,
+	}, t.resolveLibraryID)
+
+	s.RegisterTool(Tool{
+		Name: "get-library-docs",
+		Description: "Fetches up-to-date documentation for a library and returns the code snippets " +
+			"extracted from it, formatted for LLM consumption.",
+		InputSchema: getLibraryDocsSchema,
+	}, t.getLibraryDocs)
+}
+
+// resolveLibraryID handles the resolve-library-id tool
+func (t *DocsTools) resolveLibraryID(ctx context.Context, arguments json.RawMessage) (*CallToolResult, error) {
+	var args struct {
+		LibraryName string `json:"libraryName"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: "invalid arguments: " + err.Error()}
+	}
+	args.LibraryName = strings.TrimSpace(args.LibraryName)
+	if args.LibraryName == "" {
+		return nil, &Error{Code: CodeInvalidParams, Message: "libraryName is required"}
+	}
+
+	repositories, _, err := t.GitHubClient.SearchRepositories(ctx, args.LibraryName+" in:name,description", 1, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(repositories) == 0 {
+		return TextResult(fmt.Sprintf("No libraries found matching '%s'.", args.LibraryName)), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Libraries matching '%s':\n\n", args.LibraryName))
+	for _, repo := range repositories {
+		sb.WriteString(fmt.Sprintf("- Library ID: %s\n", repo.FullName))
+		if repo.Description != "" {
+			sb.WriteString(fmt.Sprintf("  Description: %s\n", repo.Description))
+		}
+		sb.WriteString(fmt.Sprintf("  Stars: %d\n", repo.Stars))
+		if repo.DefaultBranch != "" {
+			sb.WriteString(fmt.Sprintf("  Default branch: %s\n", repo.DefaultBranch))
+		}
+		sb.WriteString("\n")
+	}
+
+	return TextResult(sb.String()), nil
+}
+
+// getLibraryDocs handles the get-library-docs tool
+func (t *DocsTools) getLibraryDocs(ctx context.Context, arguments json.RawMessage) (*CallToolResult, error) {
+	var args struct {
+		LibraryID string `json:"libraryId"`
+		Ref       string `json:"ref"`
+		Version   string `json:"version"`
+		Limit     int    `json:"limit"`
+		Tokens    int    `json:"tokens"`
+		Topic     string `json:"topic"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: "invalid arguments: " + err.Error()}
+	}
+
+	owner, repo, err := parseLibraryID(args.LibraryID)
+	if err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+	}
+
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultSnippetLimit
+	}
+	if limit > maxSnippetLimit {
+		limit = maxSnippetLimit
+	}
+	if args.Tokens < 0 {
+		return nil, &Error{Code: CodeInvalidParams, Message: "tokens must be a positive integer"}
+	}
+	if args.Ref != "" && args.Version != "" {
+		return nil, &Error{Code: CodeInvalidParams, Message: "ref and version cannot be combined"}
+	}
+
+	NotifyProgress(ctx, 0, 3, fmt.Sprintf("Resolving %s/%s", owner, repo))
+	repoInfo, err := t.GitHubClient.GetRepository(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := args.Ref
+	if version := strings.TrimSpace(args.Version); version != "" {
+		ref, err = t.Registry.Resolve(ctx, owner, repo, version)
+		if errors.Is(err, registry.ErrInvalidConstraint) {
+			return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+		}
+		if errors.Is(err, registry.ErrNoMatchingVersion) {
+			return TextResult(fmt.Sprintf("No release of %s matches version '%s'.", repoInfo.FullName, version)), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	NotifyProgress(ctx, 1, 3, "Fetching documentation files")
+	documentation, err := t.GitHubClient.GetRepositoryDocumentation(ctx, owner, repo, repoInfo.DefaultBranch, ref, t.WorkerPoolSize)
+	if err != nil {
+		return nil, err
+	}
+
+	NotifyProgress(ctx, 2, 3, fmt.Sprintf("Extracting snippets from %d files", len(documentation)))
+	docProcessor := processor.NewDocumentProcessor()
+	processed := docProcessor.ExtractSnippets(documentation, repoInfo.FullName, repoInfo.HTMLURL)
+	if len(processed.Snippets) == 0 {
+		return TextResult(fmt.Sprintf("No code snippets found in the documentation of %s.", repoInfo.FullName)), nil
+	}
+
+	topic := strings.TrimSpace(args.Topic)
+	if topic != "" {
+		processor.NewSnippetRanker().ApplyTopic(&processed, topic)
+		if len(processed.Snippets) == 0 {
+			return TextResult(fmt.Sprintf("No code snippets about '%s' found in the documentation of %s.", topic, repoInfo.FullName)), nil
+		}
+	}
+
+	formatter := processor.NewTextFormatter()
+	var header string
+	if args.Tokens > 0 {
+		formatter.ApplyTokenBudget(&processed, args.Tokens)
+		header = fmt.Sprintf("Documentation for %s (%d snippets, %d of %d tokens, truncated: %t)\n\n",
+			repoInfo.FullName, len(processed.Snippets), processed.TokensUsed, processed.TokenBudget, processed.Truncated)
+	} else {
+		available := len(processed.Snippets)
+		if available > limit {
+			processed.Snippets = processed.Snippets[:limit]
+		}
+		header = fmt.Sprintf("Documentation for %s (%d of %d snippets from %d files)\n\n",
+			repoInfo.FullName, len(processed.Snippets), available, processed.TotalFiles)
+	}
+
+	if !processed.ListingComplete {
+		header = "Note: the repository tree was too large to list entirely, some documentation may be missing\n" + header
+	}
+	if processed.CommitSHA != "" {
+		header = fmt.Sprintf("Revision: %s (commit %s)\n", processed.Ref, processed.CommitSHA) + header
+	}
+
+	return TextResult(header + formatter.FormatSnippetsToText(processed.Snippets)), nil
+}
+
+// parseLibraryID splits an 'owner/repo' library ID, tolerating a leading slash
+func parseLibraryID(libraryID string) (string, string, error) {
+	id := strings.Trim(strings.TrimSpace(libraryID), "/")
+	parts := strings.Split(id, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid libraryId '%s': expected 'owner/repo'", libraryID)
+	}
+	return parts[0], parts[1], nil
+}
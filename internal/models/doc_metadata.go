@@ -18,6 +18,7 @@ type RepositoryDocumentationIndex struct {
 	RepositoryOwner string             `json:"repository_owner"`
 	RepositoryName  string             `json:"repository_name"`
 	RepositoryRef   string             `json:"repository_ref"`
+	CommitSHA       string             `json:"commit_sha,omitempty"`
 	DocumentCount   int                `json:"document_count"`
 	CreatedAt       time.Time          `json:"created_at"`
 	Documents       []DocumentMetadata `json:"documents"`
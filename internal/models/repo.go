@@ -30,6 +30,31 @@ type Documentation struct {
 	Size        int    `json:"size"`
 	SHA         string `json:"sha"`
 	URL         string `json:"url"`
+	Ref         string `json:"ref,omitempty"`        // Branch or tag the document was fetched from
+	CommitSHA   string `json:"commit_sha,omitempty"` // Commit the ref resolved to
+
+	// PartialListing is set when the repository file listing was incomplete, so documents may be missing
+	PartialListing bool `json:"partial_listing,omitempty"`
+}
+
+// RevisionOf returns the ref and commit SHA a set of documents was fetched at
+func RevisionOf(docs []Documentation) (string, string) {
+	for _, doc := range docs {
+		if doc.Ref != "" || doc.CommitSHA != "" {
+			return doc.Ref, doc.CommitSHA
+		}
+	}
+	return "", ""
+}
+
+// ListingComplete reports whether a set of documents was fetched from a complete repository listing
+func ListingComplete(docs []Documentation) bool {
+	for _, doc := range docs {
+		if doc.PartialListing {
+			return false
+		}
+	}
+	return true
 }
 
 // ErrorResponse represents an error response
@@ -53,6 +78,8 @@ type RepositoryDocsResponse struct {
 	RepositoryOwner    string          `json:"repository_owner"`
 	RepositoryName     string          `json:"repository_name"`
 	RepositoryRef      string          `json:"repository_ref,omitempty"`
+	CommitSHA          string          `json:"commit_sha,omitempty"`
+	ListingComplete    bool            `json:"listing_complete"`
 	ProcessedFilesCount int             `json:"processed_files_count"`
 	DocumentationItems []Documentation `json:"documentation_items"`
 }
@@ -7,13 +7,31 @@ type CodeSnippet struct {
 	Source      string `json:"source"`
 	Language    string `json:"language"`
 	Code        string `json:"code"`
+
+	// Location of the snippet in its document
+	Breadcrumb string `json:"breadcrumb,omitempty"` // Heading path, e.g. "Routing > Dynamic Routes"
+	StartLine  int    `json:"start_line,omitempty"`
+	EndLine    int    `json:"end_line,omitempty"`
+	URL        string `json:"url,omitempty"` // Deep link to the snippet lines
+
+	// Fence metadata, e.g. ```tsx title="app.tsx" {1,3-5}
+	Filename       string `json:"filename,omitempty"`
+	HighlightLines string `json:"highlight_lines,omitempty"`
 }
 
 // DocumentationResponse represents the full response with extracted snippets
 type DocumentationResponse struct {
-	RepositoryName string        `json:"repository_name"`
-	RepositoryURL  string        `json:"repository_url"`
-	TotalSnippets  int           `json:"total_snippets"`
-	TotalFiles     int           `json:"total_files"`
-	Snippets       []CodeSnippet `json:"snippets"`
+	RepositoryName  string        `json:"repository_name"`
+	RepositoryURL   string        `json:"repository_url"`
+	Ref             string        `json:"ref,omitempty"`
+	CommitSHA       string        `json:"commit_sha,omitempty"`
+	ListingComplete bool          `json:"listing_complete"` // False when documents may be missing from a truncated repository listing
+	TotalSnippets   int           `json:"total_snippets"`
+	TotalFiles      int           `json:"total_files"`
+	Snippets        []CodeSnippet `json:"snippets"`
+
+	// Token budget information, set when the snippets were fitted into a budget
+	TokenBudget int  `json:"token_budget,omitempty"`
+	TokensUsed  int  `json:"tokens_used,omitempty"`
+	Truncated   bool `json:"truncated"`
 }
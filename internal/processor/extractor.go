@@ -1,13 +1,18 @@
 package processor
 
 import (
+	"context"
 	"fmt"
-	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/dtomacheski/extract-data-go/internal/models"
+	"github.com/dtomacheski/extract-data-go/internal/progress"
 )
 
+// BreadcrumbSeparator joins the headings of a snippet breadcrumb
+const BreadcrumbSeparator = " > "
+
 // DocumentProcessor processes documentation content to extract code snippets
 type DocumentProcessor struct {
 	// Configuration options could go here in the future
@@ -21,10 +26,24 @@ func NewDocumentProcessor() *DocumentProcessor {
 
 // ExtractSnippets extracts code snippets from documentation content
 func (p *DocumentProcessor) ExtractSnippets(docs []models.Documentation, repoName, repoURL string) models.DocumentationResponse {
+	return p.ExtractSnippetsContext(context.Background(), docs, repoName, repoURL)
+}
+
+// ExtractSnippetsContext extracts code snippets like ExtractSnippets, reporting the
+// snippets of each document to the progress reporter of ctx
+func (p *DocumentProcessor) ExtractSnippetsContext(ctx context.Context, docs []models.Documentation, repoName, repoURL string) models.DocumentationResponse {
 	var allSnippets []models.CodeSnippet
 	processedFiles := 0
 
-	for _, doc := range docs {
+	// Documents are fetched concurrently and arrive in random order;
+	// process them by path so the snippet order is stable
+	sortedDocs := make([]models.Documentation, len(docs))
+	copy(sortedDocs, docs)
+	sort.SliceStable(sortedDocs, func(i, j int) bool {
+		return sortedDocs[i].Path < sortedDocs[j].Path
+	})
+
+	for _, doc := range sortedDocs {
 		// Skip empty content
 		if doc.Content == "" {
 			continue
@@ -33,19 +52,25 @@ func (p *DocumentProcessor) ExtractSnippets(docs []models.Documentation, repoNam
 		// Process the document to extract snippets
 		fileSnippets := p.processDocument(doc, repoName, repoURL)
 		allSnippets = append(allSnippets, fileSnippets...)
+		progress.Report(ctx, progress.Event{Type: progress.DocumentProcessed, Path: doc.Path, Count: len(fileSnippets)})
 
 		if len(fileSnippets) > 0 {
 			processedFiles++
 		}
 	}
 
+	ref, commitSHA := models.RevisionOf(docs)
+
 	// Create the response
 	return models.DocumentationResponse{
-		RepositoryName: repoName,
-		RepositoryURL:  repoURL,
-		TotalSnippets:  len(allSnippets),
-		TotalFiles:     processedFiles,
-		Snippets:       allSnippets,
+		RepositoryName:  repoName,
+		RepositoryURL:   repoURL,
+		Ref:             ref,
+		CommitSHA:       commitSHA,
+		ListingComplete: models.ListingComplete(docs),
+		TotalSnippets:   len(allSnippets),
+		TotalFiles:      processedFiles,
+		Snippets:        allSnippets,
 	}
 }
 
@@ -63,50 +88,52 @@ func (p *DocumentProcessor) processDocument(doc models.Documentation, repoName,
 	return snippets
 }
 
-// extractMarkdownSnippets extracts code snippets from markdown content
+// extractMarkdownSnippets extracts code snippets from markdown content by walking its AST
 func (p *DocumentProcessor) extractMarkdownSnippets(doc models.Documentation, repoName, repoURL string) []models.CodeSnippet {
 	var snippets []models.CodeSnippet
 
-	// Regex to find code blocks with language
-	codeBlockRegex := regexp.MustCompile("```([a-zA-Z0-9]*)\n([\\s\\S]*?)```")
-	matches := codeBlockRegex.FindAllStringSubmatch(doc.Content, -1)
+	parsed := parseMarkdown(doc.Content)
 
 	// Extract document title
-	title := extractTitle(doc.Content)
-	
-	// Calculate source URL
-	sourceURL := fmt.Sprintf("%s/blob/master/%s", repoURL, doc.Path)
-	if repoURL == "https://github.com/vercel/next.js" {
-		sourceURL = fmt.Sprintf("%s/blob/canary/%s", repoURL, doc.Path)
+	title := parsed.Title
+	if title == "" {
+		title = firstLineTitle(doc.Content)
 	}
 
+	// Calculate source URL
+	sourceURL := sourceFileURL(doc, repoURL)
+
 	// Process each code block
-	for i, match := range matches {
-		if len(match) < 3 {
-			continue
+	for _, block := range parsed.Blocks {
+		description := block.Description
+		if description == "" {
+			description = "Code snippet from documentation"
 		}
 
-		language := match[1]
-		code := strings.TrimSpace(match[2])
-
-		// Skip empty code blocks
-		if code == "" {
-			continue
+		// Limit length
+		if len(description) > 120 {
+			description = description[:117] + "..."
 		}
 
-		// We'll use the file name and position to create more meaningful titles
-		snippetNum := i+1
-
-		// Try to find a better description from the content around the code block
-		description := extractDescriptionForCodeBlock(doc.Content, match[0])
+		// Title the snippet after its closest heading
+		snippetTitle := title
+		if len(block.Headings) > 0 {
+			snippetTitle = block.Headings[len(block.Headings)-1]
+		}
 
 		// Create the snippet
 		snippet := models.CodeSnippet{
-			Title:       fmt.Sprintf("%s (%s) - Snippet %d", title, repoName, snippetNum),
-			Description: description,
-			Source:      sourceURL,
-			Language:    language,
-			Code:        code,
+			Title:          snippetTitle,
+			Description:    description,
+			Source:         sourceURL,
+			Language:       block.Language,
+			Code:           block.Code,
+			Breadcrumb:     strings.Join(block.Headings, BreadcrumbSeparator),
+			StartLine:      block.StartLine,
+			EndLine:        block.EndLine,
+			URL:            lineAnchorURL(sourceURL, block.StartLine, block.EndLine),
+			Filename:       block.Filename,
+			HighlightLines: block.HighlightLines,
 		}
 
 		snippets = append(snippets, snippet)
@@ -115,23 +142,67 @@ func (p *DocumentProcessor) extractMarkdownSnippets(doc models.Documentation, re
 	return snippets
 }
 
-// extractTitle extracts a title from markdown content
-func extractTitle(content string) string {
-	// Look for h1 headers
-	h1Regex := regexp.MustCompile(`(?m)^#\s+(.+)$`)
-	h1Matches := h1Regex.FindStringSubmatch(content)
-	if len(h1Matches) > 1 {
-		return strings.TrimSpace(h1Matches[1])
+// sourceFileURL builds a permalink to the document at the commit it was fetched
+// from, falling back to its ref and then to the default branch (HEAD). Sources
+// with their own URL layout (GitLab, Gitea) provide the permalink themselves.
+func sourceFileURL(doc models.Documentation, repoURL string) string {
+	if doc.URL != "" && doc.CommitSHA != "" && strings.Contains(doc.URL, doc.CommitSHA) {
+		return doc.URL
+	}
+
+	revision := doc.CommitSHA
+	if revision == "" {
+		revision = doc.Ref
+	}
+	if revision == "" {
+		revision = "HEAD"
+	}
+	return fmt.Sprintf("%s/blob/%s/%s", repoURL, revision, doc.Path)
+}
+
+// lineAnchorURL links to a line range of a file, in the anchor format of its host.
+// GitHub renders markdown files, so plain=1 is required for its line anchors to work;
+// GitLab permalinks (/-/blob/) use #L10-20 and Gitea permalinks (/src/commit/, /src/branch/,
+// /src/tag/) #L10-L20.
+func lineAnchorURL(fileURL string, startLine, endLine int) string {
+	if startLine <= 0 {
+		return fileURL
 	}
 
-	// If no h1, look for h2
-	h2Regex := regexp.MustCompile(`(?m)^##\s+(.+)$`)
-	h2Matches := h2Regex.FindStringSubmatch(content)
-	if len(h2Matches) > 1 {
-		return strings.TrimSpace(h2Matches[1])
+	single, lineRange := "%s?plain=1#L%d", "%s?plain=1#L%d-L%d"
+	switch fileURLLayout(fileURL) {
+	case "/-/blob/":
+		single, lineRange = "%s#L%d", "%s#L%d-%d"
+	case "/src/commit/", "/src/branch/", "/src/tag/":
+		single, lineRange = "%s#L%d", "%s#L%d-L%d"
 	}
 
-	// If no headers, use the first non-empty line
+	if endLine <= startLine {
+		return fmt.Sprintf(single, fileURL, startLine)
+	}
+	return fmt.Sprintf(lineRange, fileURL, startLine, endLine)
+}
+
+// fileURLLayouts are the segments introducing the revision in the file URLs of the
+// supported hosts. Gitea segments name the revision kind, so that an owner or
+// repository named src is not taken for one.
+var fileURLLayouts = []string{"/-/blob/", "/blob/", "/src/commit/", "/src/branch/", "/src/tag/"}
+
+// fileURLLayout returns the segment introducing the revision in a file URL, "/blob/"
+// (GitHub) by default. The earliest segment wins, since the file path itself may
+// contain one of them.
+func fileURLLayout(fileURL string) string {
+	layout, first := "/blob/", -1
+	for _, segment := range fileURLLayouts {
+		if i := strings.Index(fileURL, segment); i >= 0 && (first < 0 || i < first) {
+			layout, first = segment, i
+		}
+	}
+	return layout
+}
+
+// firstLineTitle uses the first non-empty line as title of documents without headings
+func firstLineTitle(content string) string {
 	lines := strings.Split(content, "\n")
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
@@ -146,57 +217,3 @@ func extractTitle(content string) string {
 
 	return "Untitled Document"
 }
-
-// extractDescriptionForCodeBlock tries to extract a description for a code block
-func extractDescriptionForCodeBlock(content, codeBlock string) string {
-	// Find the position of the code block
-	blockPos := strings.Index(content, codeBlock)
-	if blockPos == -1 {
-		return "Code snippet from documentation"
-	}
-
-	// Get content before the code block (up to 500 chars)
-	startPos := blockPos - 500
-	if startPos < 0 {
-		startPos = 0
-	}
-	beforeBlock := content[startPos:blockPos]
-
-	// Look for paragraphs before the code block
-	paragraphs := strings.Split(beforeBlock, "\n\n")
-	if len(paragraphs) > 0 {
-		// Get the last paragraph
-		lastPara := strings.TrimSpace(paragraphs[len(paragraphs)-1])
-		
-		// Remove any markdown formatting
-		lastPara = cleanMarkdownFormatting(lastPara)
-		
-		// Limit length
-		if len(lastPara) > 120 {
-			return lastPara[:117] + "..."
-		}
-		
-		if lastPara != "" {
-			return lastPara
-		}
-	}
-
-	return "Code snippet from documentation"
-}
-
-// cleanMarkdownFormatting removes common markdown formatting
-func cleanMarkdownFormatting(text string) string {
-	// Remove headers
-	text = regexp.MustCompile(`(?m)^#+\s+`).ReplaceAllString(text, "")
-	
-	// Remove bold/italic
-	text = regexp.MustCompile(`\*\*(.+?)\*\*`).ReplaceAllString(text, "$1")
-	text = regexp.MustCompile(`\*(.+?)\*`).ReplaceAllString(text, "$1")
-	text = regexp.MustCompile(`__(.+?)__`).ReplaceAllString(text, "$1")
-	text = regexp.MustCompile(`_(.+?)_`).ReplaceAllString(text, "$1")
-	
-	// Remove links but keep text
-	text = regexp.MustCompile(`\[(.+?)\]\(.+?\)`).ReplaceAllString(text, "$1")
-	
-	return text
-}
@@ -0,0 +1,73 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/dtomacheski/extract-data-go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExtractSnippets_Locations tests breadcrumbs, line ranges and deep links
+func TestExtractSnippets_Locations(t *testing.T) {
+	docs := []models.Documentation{{Path: "docs/routing.md", Content: markdownFixture}}
+
+	response := NewDocumentProcessor().ExtractSnippets(docs, "vercel/next.js", "https://github.com/vercel/next.js")
+	require.Len(t, response.Snippets, 6)
+
+	snippet := response.Snippets[1]
+	assert.Equal(t, "Catch-all", snippet.Title)
+	assert.Equal(t, "Routing > Dynamic Routes > Catch-all", snippet.Breadcrumb)
+	assert.Equal(t, 16, snippet.StartLine)
+	assert.Equal(t, 16, snippet.EndLine)
+	assert.Contains(t, snippet.URL, "/docs/routing.md?plain=1#L16")
+
+	snippet = response.Snippets[0]
+	assert.Equal(t, "Dynamic Routes", snippet.Title)
+	assert.Contains(t, snippet.URL, "/docs/routing.md?plain=1#L8-L10")
+
+	text := NewTextFormatter().FormatSnippet(snippet)
+	assert.Contains(t, text, "BREADCRUMB: Routing > Dynamic Routes\n")
+	assert.Contains(t, text, "LINES: 8-10\n")
+	assert.Contains(t, text, "FILENAME: app/[slug]/page.tsx\n")
+}
+
+// TestExtractSnippets_Permalinks tests that sources link to the fetched commit
+func TestExtractSnippets_Permalinks(t *testing.T) {
+	docs := []models.Documentation{
+		{Path: "docs/a.md", Content: "# A\n\n```go\na()\n```\n", Ref: "main", CommitSHA: "abc123"},
+	}
+
+	response := NewDocumentProcessor().ExtractSnippets(docs, "owner/repo", "https://github.com/owner/repo")
+	require.Len(t, response.Snippets, 1)
+	assert.Equal(t, "main", response.Ref)
+	assert.Equal(t, "abc123", response.CommitSHA)
+	assert.Equal(t, "https://github.com/owner/repo/blob/abc123/docs/a.md", response.Snippets[0].Source)
+	assert.Equal(t, "https://github.com/owner/repo/blob/abc123/docs/a.md?plain=1#L4", response.Snippets[0].URL)
+
+	// Permalinks provided by the source are kept as is
+	docs[0].URL = "https://gitlab.com/owner/repo/-/blob/abc123/docs/a.md"
+	response = NewDocumentProcessor().ExtractSnippets(docs, "owner/repo", "https://gitlab.com/owner/repo")
+	assert.Equal(t, docs[0].URL, response.Snippets[0].Source)
+	assert.Equal(t, docs[0].URL+"#L4", response.Snippets[0].URL)
+
+	// Without revision information the default branch is used
+	docs[0].Ref, docs[0].CommitSHA = "", ""
+	response = NewDocumentProcessor().ExtractSnippets(docs, "owner/repo", "https://github.com/owner/repo")
+	assert.Equal(t, "https://github.com/owner/repo/blob/HEAD/docs/a.md", response.Snippets[0].Source)
+}
+
+// TestLineAnchorURL tests the line anchors of each host
+func TestLineAnchorURL(t *testing.T) {
+	assert.Equal(t, "https://github.com/o/r/blob/abc/a.md?plain=1#L3-L5", lineAnchorURL("https://github.com/o/r/blob/abc/a.md", 3, 5))
+	assert.Equal(t, "https://gitlab.com/o/r/-/blob/abc/a.md#L3-5", lineAnchorURL("https://gitlab.com/o/r/-/blob/abc/a.md", 3, 5))
+	assert.Equal(t, "https://codeberg.org/o/r/src/commit/abc/a.md#L3-L5", lineAnchorURL("https://codeberg.org/o/r/src/commit/abc/a.md", 3, 5))
+	assert.Equal(t, "https://gitlab.com/o/r/-/blob/abc/a.md#L3", lineAnchorURL("https://gitlab.com/o/r/-/blob/abc/a.md", 3, 3))
+	assert.Equal(t, "https://github.com/o/r/blob/abc/src/a.md?plain=1#L3", lineAnchorURL("https://github.com/o/r/blob/abc/src/a.md", 3, 3))
+	assert.Equal(t, "https://github.com/o/r/blob/abc/a.md", lineAnchorURL("https://github.com/o/r/blob/abc/a.md", 0, 0))
+
+	// GitHub repositories and owners named src keep the GitHub layout
+	assert.Equal(t, "https://github.com/acme/src/blob/abc/README.md?plain=1#L10-L20", lineAnchorURL("https://github.com/acme/src/blob/abc/README.md", 10, 20))
+	assert.Equal(t, "https://github.com/src/r/blob/abc/README.md?plain=1#L10", lineAnchorURL("https://github.com/src/r/blob/abc/README.md", 10, 10))
+	assert.Equal(t, "https://codeberg.org/o/src/src/branch/main/a.md#L3-L5", lineAnchorURL("https://codeberg.org/o/src/src/branch/main/a.md", 3, 5))
+}
@@ -1,6 +1,7 @@
 package processor
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -17,37 +18,69 @@ func NewTextFormatter() *TextFormatter {
 	return &TextFormatter{}
 }
 
+// snippetSeparator separa snippets consecutivos no formato TXT
+const snippetSeparator = "----------------------------------------\n\n"
+
 // FormatSnippetsToText formata uma lista de snippets de código para o formato TXT desejado
 func (f *TextFormatter) FormatSnippetsToText(snippets []models.CodeSnippet) string {
 	var sb strings.Builder
 	
 	for i, snippet := range snippets {
-		// Adiciona o título
-		sb.WriteString(fmt.Sprintf("TITLE: %s\n", snippet.Title))
-		
-		// Adiciona a descrição
-		sb.WriteString(fmt.Sprintf("DESCRIPTION: %s\n", snippet.Description))
-		
-		// Adiciona a fonte
-		sb.WriteString(fmt.Sprintf("SOURCE: %s\n", snippet.Source))
-		sb.WriteString("\n")
-		
-		// Adiciona o código com a linguagem
-		sb.WriteString(fmt.Sprintf("LANGUAGE: %s\n", snippet.Language))
-		sb.WriteString("CODE:\n```\n")
-		sb.WriteString(snippet.Code)
-		sb.WriteString("\n```\n")
-		sb.WriteString("\n")
+		sb.WriteString(f.FormatSnippet(snippet))
 		
 		// Adiciona separador entre snippets, exceto para o último
 		if i < len(snippets)-1 {
-			sb.WriteString("----------------------------------------\n\n")
+			sb.WriteString(snippetSeparator)
 		}
 	}
 	
 	return sb.String()
 }
 
+// FormatSnippet formata um único snippet, sem separador
+func (f *TextFormatter) FormatSnippet(snippet models.CodeSnippet) string {
+	var sb strings.Builder
+
+	// Adiciona o título e o caminho de headings
+	sb.WriteString(fmt.Sprintf("TITLE: %s\n", snippet.Title))
+	if snippet.Breadcrumb != "" {
+		sb.WriteString(fmt.Sprintf("BREADCRUMB: %s\n", snippet.Breadcrumb))
+	}
+	
+	// Adiciona a descrição
+	sb.WriteString(fmt.Sprintf("DESCRIPTION: %s\n", snippet.Description))
+	
+	// Adiciona a fonte, com as linhas e o link direto quando conhecidos
+	sb.WriteString(fmt.Sprintf("SOURCE: %s\n", snippet.Source))
+	if snippet.StartLine > 0 {
+		sb.WriteString(fmt.Sprintf("LINES: %s\n", FormatLineRange(snippet.StartLine, snippet.EndLine)))
+	}
+	if snippet.URL != "" && snippet.URL != snippet.Source {
+		sb.WriteString(fmt.Sprintf("URL: %s\n", snippet.URL))
+	}
+	sb.WriteString("\n")
+	
+	// Adiciona o código com a linguagem
+	sb.WriteString(fmt.Sprintf("LANGUAGE: %s\n", snippet.Language))
+	if snippet.Filename != "" {
+		sb.WriteString(fmt.Sprintf("FILENAME: %s\n", snippet.Filename))
+	}
+	sb.WriteString("CODE:\n```\n")
+	sb.WriteString(snippet.Code)
+	sb.WriteString("\n```\n")
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// FormatLineRange formata um intervalo de linhas como "10-25", ou "10" para uma única linha
+func FormatLineRange(startLine, endLine int) string {
+	if endLine <= startLine {
+		return fmt.Sprintf("%d", startLine)
+	}
+	return fmt.Sprintf("%d-%d", startLine, endLine)
+}
+
 // GenerateFilename gera um nome de arquivo para o documento TXT baseado no repositório
 func (f *TextFormatter) GenerateFilename(repoOwner, repoName string) string {
 	// Simplifica o nome do repositório para uso em nome de arquivo
@@ -60,6 +93,12 @@ func (f *TextFormatter) GenerateFilename(repoOwner, repoName string) string {
 
 // ProcessAndFormatDocumentation processa a documentação e formata como TXT
 func (f *TextFormatter) ProcessAndFormatDocumentation(docs []models.Documentation, repoOwner, repoName string) (string, string, int) {
+	return f.ProcessAndFormatDocumentationContext(context.Background(), docs, repoOwner, repoName)
+}
+
+// ProcessAndFormatDocumentationContext processa a documentação como ProcessAndFormatDocumentation,
+// reportando o progresso da extração de cada documento ao contexto
+func (f *TextFormatter) ProcessAndFormatDocumentationContext(ctx context.Context, docs []models.Documentation, repoOwner, repoName string) (string, string, int) {
 	// Customizar o processador de documentos para usar URLs simplificados
 	docProcessor := NewDocumentProcessor()
 	
@@ -81,7 +120,7 @@ func (f *TextFormatter) ProcessAndFormatDocumentation(docs []models.Documentatio
 	// Extrai snippets da documentação
 	baseRepoName := fmt.Sprintf("%s/%s", repoOwner, repoName)
 	repoURL := fmt.Sprintf("https://github.com/%s", baseRepoName)
-	docsResponse := docProcessor.ExtractSnippets(docsToProcess, baseRepoName, repoURL)
+	docsResponse := docProcessor.ExtractSnippetsContext(ctx, docsToProcess, baseRepoName, repoURL)
 	
 	// Simplificar os URLs de SOURCE
 	for i := range docsResponse.Snippets {
@@ -0,0 +1,300 @@
+package processor
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// maxHeadingLevel is the deepest heading level in CommonMark
+const maxHeadingLevel = 6
+
+// markdownParser parses CommonMark with the GitHub Flavored Markdown extensions
+var markdownParser = goldmark.New(goldmark.WithExtensions(extension.GFM)).Parser()
+
+// markdownDocument is the result of walking a markdown document
+type markdownDocument struct {
+	// Title is the first H1 of the document, or the first H2 when there is none
+	Title string
+
+	// Blocks are the code blocks in document order
+	Blocks []codeBlock
+}
+
+// codeBlock is a fenced or indented code block found in a markdown document
+type codeBlock struct {
+	Language       string
+	Filename       string
+	HighlightLines string
+	Code           string
+
+	// Headings is the heading hierarchy enclosing the block, outermost first
+	Headings []string
+
+	// Description is the text of the paragraph preceding the block, if any
+	Description string
+
+	// StartLine and EndLine are the 1-based source lines of the code
+	StartLine int
+	EndLine   int
+}
+
+// parseMarkdown walks the markdown AST and collects its code blocks together
+// with their heading hierarchy, fence metadata and line range
+func parseMarkdown(content string) markdownDocument {
+	source := []byte(content)
+	root := markdownParser.Parse(text.NewReader(source))
+	lines := newLineIndex(source)
+
+	var doc markdownDocument
+	var headings [maxHeadingLevel]string
+	var firstH2 string
+
+	_ = ast.Walk(root, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch n := node.(type) {
+		case *ast.Heading:
+			title := strings.TrimSpace(nodeText(n, source))
+			if n.Level >= 1 && n.Level <= maxHeadingLevel {
+				headings[n.Level-1] = title
+				for i := n.Level; i < maxHeadingLevel; i++ {
+					headings[i] = ""
+				}
+			}
+			if n.Level == 1 && doc.Title == "" {
+				doc.Title = title
+			}
+			if n.Level == 2 && firstH2 == "" {
+				firstH2 = title
+			}
+			return ast.WalkSkipChildren, nil
+
+		case *ast.FencedCodeBlock:
+			block := newCodeBlock(n, source, lines, headings[:])
+			if n.Info != nil {
+				parseFenceInfo(string(n.Info.Segment.Value(source)), &block)
+			}
+			if block.Code != "" {
+				doc.Blocks = append(doc.Blocks, block)
+			}
+			return ast.WalkSkipChildren, nil
+
+		case *ast.CodeBlock:
+			block := newCodeBlock(n, source, lines, headings[:])
+			if block.Code != "" {
+				doc.Blocks = append(doc.Blocks, block)
+			}
+			return ast.WalkSkipChildren, nil
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	if doc.Title == "" {
+		doc.Title = firstH2
+	}
+	return doc
+}
+
+// newCodeBlock builds a code block from the content lines of a code node.
+// Leading and trailing blank lines are dropped from both code and line range.
+func newCodeBlock(node ast.Node, source []byte, lines lineIndex, headings []string) codeBlock {
+	segments := node.Lines()
+	lineValue := func(i int) []byte {
+		segment := segments.At(i)
+		return segment.Value(source)
+	}
+
+	first, last := 0, segments.Len()-1
+	for first <= last && isBlank(lineValue(first)) {
+		first++
+	}
+	for last >= first && isBlank(lineValue(last)) {
+		last--
+	}
+
+	var sb strings.Builder
+	for i := first; i <= last; i++ {
+		sb.Write(lineValue(i))
+	}
+
+	block := codeBlock{
+		Code:        strings.TrimRightFunc(sb.String(), unicode.IsSpace),
+		Description: precedingParagraph(node, source),
+	}
+
+	for _, heading := range headings {
+		if heading != "" {
+			block.Headings = append(block.Headings, heading)
+		}
+	}
+
+	if first <= last {
+		block.StartLine = lines.lineOf(segments.At(first).Start)
+		block.EndLine = lines.lineOf(segments.At(last).Start)
+	}
+
+	return block
+}
+
+// isBlank reports whether a line only contains whitespace
+func isBlank(line []byte) bool {
+	return len(strings.TrimSpace(string(line))) == 0
+}
+
+// parseFenceInfo extracts the language and metadata from a fence info string
+// such as `tsx title="app.tsx" {1,3-5}` or `go filename=main.go highlight=2`
+func parseFenceInfo(info string, block *codeBlock) {
+	tokens := splitFenceInfo(info)
+	if len(tokens) == 0 {
+		return
+	}
+
+	// The first word is the language unless it is already metadata
+	if !strings.HasPrefix(tokens[0], "{") && !strings.Contains(tokens[0], "=") {
+		language := strings.TrimPrefix(tokens[0], "language-")
+		// Some sites use `lang:filename`
+		if lang, filename, ok := strings.Cut(language, ":"); ok && lang != "" && filename != "" {
+			language = lang
+			block.Filename = filename
+		}
+		block.Language = language
+		tokens = tokens[1:]
+	}
+
+	for _, token := range tokens {
+		if strings.HasPrefix(token, "{") && strings.HasSuffix(token, "}") {
+			block.HighlightLines = normalizeLineRanges(token)
+			continue
+		}
+
+		key, value, ok := strings.Cut(token, "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+
+		switch strings.ToLower(key) {
+		case "title", "filename", "file", "name":
+			block.Filename = value
+		case "highlight", "hl_lines", "lines", "mark":
+			block.HighlightLines = normalizeLineRanges(value)
+		}
+	}
+}
+
+// splitFenceInfo splits a fence info string on whitespace, keeping quoted
+// values and brace groups together
+func splitFenceInfo(info string) []string {
+	var tokens []string
+	var current strings.Builder
+	var quote rune
+	braces := 0
+
+	for _, r := range info {
+		switch {
+		case quote != 0:
+			current.WriteRune(r)
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			current.WriteRune(r)
+		case r == '{':
+			braces++
+			current.WriteRune(r)
+		case r == '}':
+			braces--
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && braces <= 0:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens
+}
+
+// normalizeLineRanges turns `{1,3-5}`, `"1 3"` or `1,3..5` into `1,3-5`
+func normalizeLineRanges(spec string) string {
+	spec = strings.Trim(spec, `{}"' `)
+	spec = strings.ReplaceAll(spec, "..", "-")
+	fields := strings.FieldsFunc(spec, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	return strings.Join(fields, ",")
+}
+
+// precedingParagraph returns the text of the closest paragraph before node in
+// the same container, stopping at headings and other code blocks
+func precedingParagraph(node ast.Node, source []byte) string {
+	for prev := node.PreviousSibling(); prev != nil; prev = prev.PreviousSibling() {
+		switch p := prev.(type) {
+		case *ast.Paragraph, *ast.TextBlock:
+			return strings.TrimSpace(nodeText(p, source))
+		case *ast.Heading, *ast.FencedCodeBlock, *ast.CodeBlock, *ast.ThematicBreak:
+			return ""
+		}
+	}
+	return ""
+}
+
+// nodeText returns the plain text of an inline container, without markdown formatting
+func nodeText(node ast.Node, source []byte) string {
+	var sb strings.Builder
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := n.(type) {
+		case *ast.Text:
+			sb.Write(t.Segment.Value(source))
+			if t.SoftLineBreak() || t.HardLineBreak() {
+				sb.WriteByte(' ')
+			}
+		case *ast.String:
+			sb.Write(t.Value)
+		case *ast.AutoLink:
+			sb.Write(t.URL(source))
+		case *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return sb.String()
+}
+
+// lineIndex maps byte offsets to 1-based line numbers
+type lineIndex []int
+
+// newLineIndex records the offset at which every line starts
+func newLineIndex(source []byte) lineIndex {
+	starts := []int{0}
+	for i, b := range source {
+		if b == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// lineOf returns the line containing offset
+func (l lineIndex) lineOf(offset int) int {
+	return sort.Search(len(l), func(i int) bool { return l[i] > offset })
+}
@@ -0,0 +1,118 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const markdownFixture = "# Routing\n" + // 1
+	"\n" + // 2
+	"## Dynamic Routes\n" + // 3
+	"\n" + // 4
+	"Create a **dynamic** segment:\n" + // 5
+	"\n" + // 6
+	"```tsx title=\"app/[slug]/page.tsx\" {1,3-4}\n" + // 7
+	"export default function Page() {\n" + // 8
+	"  return null\n" + // 9
+	"}\n" + // 10
+	"```\n" + // 11
+	"\n" + // 12
+	"### Catch-all\n" + // 13
+	"\n" + // 14
+	"~~~c++\n" + // 15
+	"int main() {}\n" + // 16
+	"~~~\n" + // 17
+	"\n" + // 18
+	"````md\n" + // 19
+	"```js\n" + // 20
+	"nested()\n" + // 21
+	"```\n" + // 22
+	"````\n" + // 23
+	"\n" + // 24
+	"## Installation\n" + // 25
+	"\n" + // 26
+	"- Install the package:\n" + // 27
+	"\n" + // 28
+	"  ```bash\n" + // 29
+	"  npm install next\n" + // 30
+	"  ```\n" + // 31
+	"\n" + // 32
+	"> Quoted example:\n" + // 33
+	">\n" + // 34
+	"> ```go\n" + // 35
+	"> fmt.Println(\"hi\")\n" + // 36
+	"> ```\n" + // 37
+	"\n" + // 38
+	"Indented code:\n" + // 39
+	"\n" + // 40
+	"    make build\n" // 41
+
+// TestParseMarkdown tests code block extraction from the markdown AST
+func TestParseMarkdown(t *testing.T) {
+	doc := parseMarkdown(markdownFixture)
+	assert.Equal(t, "Routing", doc.Title)
+	require.Len(t, doc.Blocks, 6)
+
+	tsx := doc.Blocks[0]
+	assert.Equal(t, "tsx", tsx.Language)
+	assert.Equal(t, "app/[slug]/page.tsx", tsx.Filename)
+	assert.Equal(t, "1,3-4", tsx.HighlightLines)
+	assert.Equal(t, []string{"Routing", "Dynamic Routes"}, tsx.Headings)
+	assert.Equal(t, "Create a dynamic segment:", tsx.Description)
+	assert.Equal(t, 8, tsx.StartLine)
+	assert.Equal(t, 10, tsx.EndLine)
+
+	tilde := doc.Blocks[1]
+	assert.Equal(t, "c++", tilde.Language)
+	assert.Equal(t, "int main() {}", tilde.Code)
+	assert.Equal(t, []string{"Routing", "Dynamic Routes", "Catch-all"}, tilde.Headings)
+
+	// Four-backtick fences keep nested fences as content
+	nested := doc.Blocks[2]
+	assert.Equal(t, "md", nested.Language)
+	assert.Equal(t, "```js\nnested()\n```", nested.Code)
+	assert.Equal(t, 20, nested.StartLine)
+	assert.Equal(t, 22, nested.EndLine)
+
+	list := doc.Blocks[3]
+	assert.Equal(t, "bash", list.Language)
+	assert.Equal(t, "npm install next", list.Code)
+	assert.Equal(t, []string{"Routing", "Installation"}, list.Headings)
+	assert.Equal(t, "Install the package:", list.Description)
+	assert.Equal(t, 30, list.StartLine)
+
+	quote := doc.Blocks[4]
+	assert.Equal(t, "go", quote.Language)
+	assert.Equal(t, `fmt.Println("hi")`, quote.Code)
+	assert.Equal(t, 36, quote.StartLine)
+
+	indented := doc.Blocks[5]
+	assert.Equal(t, "", indented.Language)
+	assert.Equal(t, "make build", indented.Code)
+	assert.Equal(t, 41, indented.StartLine)
+}
+
+// TestParseFenceInfo tests fence info string parsing
+func TestParseFenceInfo(t *testing.T) {
+	tests := []struct {
+		info      string
+		language  string
+		filename  string
+		highlight string
+	}{
+		{info: "go", language: "go"},
+		{info: "js filename=\"index.js\" highlight={2}", language: "js", filename: "index.js", highlight: "2"},
+		{info: "python:app.py hl_lines=\"1 3\"", language: "python", filename: "app.py", highlight: "1,3"},
+		{info: "{1..3}", highlight: "1-3"},
+	}
+
+	for _, tt := range tests {
+		var block codeBlock
+		parseFenceInfo(tt.info, &block)
+		assert.Equal(t, tt.language, block.Language, tt.info)
+		assert.Equal(t, tt.filename, block.Filename, tt.info)
+		assert.Equal(t, tt.highlight, block.HighlightLines, tt.info)
+	}
+}
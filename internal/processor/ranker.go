@@ -0,0 +1,227 @@
+package processor
+
+import (
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dtomacheski/extract-data-go/internal/models"
+)
+
+// BM25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Field weights used to combine the snippet fields into a single document (BM25F)
+const (
+	titleWeight       = 3.0
+	breadcrumbWeight  = titleWeight
+	descriptionWeight = 2.0
+	sourceWeight      = 1.5
+	codeWeight        = 1.0
+)
+
+// revisionSegments precede the revision in the file URLs of the supported hosts:
+// /blob/<rev>/ on GitHub and GitLab, /src/commit|branch|tag/<rev>/ on Gitea
+var revisionSegments = []string{"/blob/", "/src/commit/", "/src/branch/", "/src/tag/"}
+
+// SnippetRanker scores code snippets against a free text query using BM25
+// over their title, heading path, description, source file path and code
+type SnippetRanker struct {
+	k1 float64
+	b  float64
+}
+
+// NewSnippetRanker creates a ranker with the standard BM25 parameters
+func NewSnippetRanker() *SnippetRanker {
+	return &SnippetRanker{k1: bm25K1, b: bm25B}
+}
+
+// rankedDocument holds the weighted term frequencies of one snippet
+type rankedDocument struct {
+	terms  map[string]float64
+	length float64
+}
+
+// Rank returns the snippets that match query, most relevant first.
+// Snippets with the same score keep their original order.
+func (r *SnippetRanker) Rank(snippets []models.CodeSnippet, query string) []models.CodeSnippet {
+	queryTerms := uniqueTerms(tokenize(query))
+	if len(queryTerms) == 0 || len(snippets) == 0 {
+		return snippets
+	}
+
+	documents := make([]rankedDocument, len(snippets))
+	documentFrequency := make(map[string]int)
+	totalLength := 0.0
+
+	for i, snippet := range snippets {
+		documents[i] = buildRankedDocument(snippet)
+		totalLength += documents[i].length
+		for _, term := range queryTerms {
+			if documents[i].terms[term] > 0 {
+				documentFrequency[term]++
+			}
+		}
+	}
+
+	avgLength := totalLength / float64(len(snippets))
+	if avgLength == 0 {
+		avgLength = 1
+	}
+
+	type scored struct {
+		index int
+		score float64
+	}
+	var results []scored
+
+	n := float64(len(snippets))
+	for i, doc := range documents {
+		score := 0.0
+		for _, term := range queryTerms {
+			tf := doc.terms[term]
+			if tf == 0 {
+				continue
+			}
+			df := float64(documentFrequency[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			score += idf * tf * (r.k1 + 1) / (tf + r.k1*(1-r.b+r.b*doc.length/avgLength))
+		}
+		if score > 0 {
+			results = append(results, scored{index: i, score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+
+	ranked := make([]models.CodeSnippet, 0, len(results))
+	for _, result := range results {
+		ranked = append(ranked, snippets[result.index])
+	}
+	return ranked
+}
+
+// ApplyTopic keeps the snippets of response that match topic, most relevant first,
+// and updates its snippet count accordingly
+func (r *SnippetRanker) ApplyTopic(response *models.DocumentationResponse, topic string) {
+	response.Snippets = r.Rank(response.Snippets, topic)
+	response.TotalSnippets = len(response.Snippets)
+}
+
+// buildRankedDocument combines the weighted snippet fields
+func buildRankedDocument(snippet models.CodeSnippet) rankedDocument {
+	doc := rankedDocument{terms: make(map[string]float64)}
+
+	fields := []struct {
+		text   string
+		weight float64
+	}{
+		{snippet.Title, titleWeight},
+		{snippet.Breadcrumb, breadcrumbWeight},
+		{snippet.Description, descriptionWeight},
+		{sourcePath(snippet.Source), sourceWeight},
+		{snippet.Code, codeWeight},
+	}
+
+	for _, field := range fields {
+		for _, term := range tokenize(field.text) {
+			doc.terms[term] += field.weight
+			doc.length += field.weight
+		}
+	}
+
+	return doc
+}
+
+// sourcePath returns the file path of a snippet source, so that the host, owner and
+// revision of its URL do not count as terms
+func sourcePath(source string) string {
+	for _, segment := range revisionSegments {
+		if _, rest, ok := strings.Cut(source, segment); ok {
+			if _, path, ok := strings.Cut(rest, "/"); ok {
+				return path
+			}
+		}
+	}
+	if u, err := url.Parse(source); err == nil && u.Scheme != "" {
+		return strings.TrimPrefix(u.Path, "/")
+	}
+	return source
+}
+
+// tokenize splits text into lowercase, stemmed terms. Identifiers are split
+// on punctuation and camelCase boundaries.
+func tokenize(text string) []string {
+	var terms []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			terms = append(terms, normalizeTerm(string(current)))
+			current = current[:0]
+		}
+	}
+
+	var prev rune
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			// Split camelCase identifiers such as useRouter into use + router
+			if unicode.IsUpper(r) && unicode.IsLower(prev) {
+				flush()
+			}
+			current = append(current, unicode.ToLower(r))
+		default:
+			flush()
+		}
+		prev = r
+	}
+	flush()
+
+	return terms
+}
+
+// stemSuffixes are the inflection suffixes removed by normalizeTerm
+var stemSuffixes = []string{"ing", "ed", "es", "s"}
+
+// normalizeTerm applies a light stemming so that 'routing', 'routes' and
+// 'route' share the same term
+func normalizeTerm(term string) string {
+	if len(term) > 4 && strings.HasSuffix(term, "ies") {
+		return term[:len(term)-3] + "y"
+	}
+
+	if !strings.HasSuffix(term, "ss") {
+		for _, suffix := range stemSuffixes {
+			if strings.HasSuffix(term, suffix) && len(term)-len(suffix) >= 3 {
+				term = term[:len(term)-len(suffix)]
+				break
+			}
+		}
+	}
+
+	if len(term) > 3 && strings.HasSuffix(term, "e") {
+		term = term[:len(term)-1]
+	}
+	return term
+}
+
+// uniqueTerms removes duplicate terms keeping the first occurrence
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	var unique []string
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,88 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/dtomacheski/extract-data-go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSnippetRanker_Rank tests BM25 relevance ordering
+func TestSnippetRanker_Rank(t *testing.T) {
+	snippets := []models.CodeSnippet{
+		{Title: "Installation", Description: "Install the package", Source: "docs/install.md", Code: "npm install next"},
+		{Title: "Fetching data", Description: "Fetch data on the server", Source: "docs/data.md", Code: "const res = await fetch(url)"},
+		{Title: "Dynamic Routes", Description: "Create routes from dynamic segments", Source: "docs/routing/dynamic.md", Code: "export default function Page({ params }) {}"},
+		{Title: "Navigation", Description: "Navigate between pages", Source: "docs/routing/linking.md", Code: "const router = useRouter()"},
+	}
+
+	ranker := NewSnippetRanker()
+
+	ranked := ranker.Rank(snippets, "routing")
+	require.Len(t, ranked, 2)
+	assert.Equal(t, "Dynamic Routes", ranked[0].Title)
+	assert.Equal(t, "Navigation", ranked[1].Title)
+
+	// Plurals and camelCase identifiers match
+	ranked = ranker.Rank(snippets, "route")
+	require.NotEmpty(t, ranked)
+	assert.Equal(t, "Dynamic Routes", ranked[0].Title)
+
+	ranked = ranker.Rank(snippets, "router")
+	require.Len(t, ranked, 1)
+	assert.Equal(t, "Navigation", ranked[0].Title)
+
+	// Unrelated snippets are dropped and empty queries keep the original order
+	assert.Empty(t, ranker.Rank(snippets, "graphql"))
+	assert.Equal(t, snippets, ranker.Rank(snippets, "  "))
+}
+
+// TestSnippetRanker_Breadcrumb tests that heading paths count and source URLs only count by file path
+func TestSnippetRanker_Breadcrumb(t *testing.T) {
+	snippets := []models.CodeSnippet{
+		{Title: "Example", Source: "https://github.com/acme/middleware/blob/main/docs/setup.md", Code: "app.listen(3000)"},
+		{Title: "Example", Breadcrumb: "Guides > Middleware > Example", Source: "https://github.com/acme/server/blob/main/docs/guide.md", Code: "app.use(log)"},
+	}
+
+	ranked := NewSnippetRanker().Rank(snippets, "middleware")
+	require.Len(t, ranked, 1)
+	assert.Equal(t, "app.use(log)", ranked[0].Code)
+
+	ranked = NewSnippetRanker().Rank(snippets, "setup")
+	require.Len(t, ranked, 1)
+	assert.Equal(t, "app.listen(3000)", ranked[0].Code)
+
+	assert.Equal(t, "docs/setup.md", sourcePath(snippets[0].Source))
+	assert.Equal(t, "docs/a.md", sourcePath("https://gitlab.com/group/sub/repo/-/blob/abc123/docs/a.md"))
+	assert.Equal(t, "docs/a.md", sourcePath("https://codeberg.org/owner/repo/src/commit/abc123/docs/a.md"))
+	assert.Equal(t, "docs/a.md", sourcePath("docs/a.md"))
+}
+
+// TestSnippetRanker_ApplyTopic tests that the snippet count follows the topic filter
+func TestSnippetRanker_ApplyTopic(t *testing.T) {
+	response := models.DocumentationResponse{
+		Snippets: []models.CodeSnippet{
+			{Title: "Installation", Code: "npm install next"},
+			{Title: "Dynamic Routes", Code: "export default function Page() {}"},
+		},
+		TotalSnippets: 2,
+	}
+
+	NewSnippetRanker().ApplyTopic(&response, "routes")
+	require.Len(t, response.Snippets, 1)
+	assert.Equal(t, 1, response.TotalSnippets)
+}
+
+// TestExtractSnippets_StableOrder tests that snippets follow the document paths
+func TestExtractSnippets_StableOrder(t *testing.T) {
+	docs := []models.Documentation{
+		{Path: "docs/b.md", Content: "# B\n\n```go\nb()\n```\n"},
+		{Path: "docs/a.md", Content: "# A\n\n```go\na()\n```\n"},
+	}
+
+	response := NewDocumentProcessor().ExtractSnippets(docs, "owner/repo", "https://github.com/owner/repo")
+	require.Len(t, response.Snippets, 2)
+	assert.Equal(t, "a()", response.Snippets[0].Code)
+	assert.Equal(t, "b()", response.Snippets[1].Code)
+}
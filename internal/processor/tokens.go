@@ -0,0 +1,90 @@
+package processor
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dtomacheski/extract-data-go/internal/models"
+)
+
+// charsPerToken is the average number of characters of a word-like token
+// in BPE tokenizers such as cl100k
+const charsPerToken = 4
+
+// EstimateTokens approximates the number of tokens an LLM tokenizer produces for text.
+// Runs of letters and digits cost one token per four characters, every other
+// non-space character costs one token and line breaks cost one token each.
+func EstimateTokens(text string) int {
+	tokens := 0
+	wordLen := 0
+
+	flushWord := func() {
+		if wordLen > 0 {
+			tokens += (wordLen + charsPerToken - 1) / charsPerToken
+			wordLen = 0
+		}
+	}
+
+	for len(text) > 0 {
+		r, size := utf8.DecodeRuneInString(text)
+		text = text[size:]
+
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			wordLen++
+		case r == '\n':
+			flushWord()
+			tokens++
+		case unicode.IsSpace(r):
+			flushWord()
+		default:
+			flushWord()
+			tokens++
+		}
+	}
+	flushWord()
+
+	return tokens
+}
+
+// TokenBudgetResult is the outcome of fitting snippets into a token budget
+type TokenBudgetResult struct {
+	Snippets   []models.CodeSnippet
+	TokensUsed int
+	Truncated  bool
+}
+
+// SelectWithinTokenBudget keeps snippets in order while their formatted text
+// fits in budget tokens. Snippets are never split: selection stops at the
+// first snippet that does not fit.
+func (f *TextFormatter) SelectWithinTokenBudget(snippets []models.CodeSnippet, budget int) TokenBudgetResult {
+	separatorTokens := EstimateTokens(snippetSeparator)
+	result := TokenBudgetResult{Snippets: []models.CodeSnippet{}}
+
+	for i, snippet := range snippets {
+		cost := EstimateTokens(f.FormatSnippet(snippet))
+		if i > 0 {
+			cost += separatorTokens
+		}
+
+		if result.TokensUsed+cost > budget {
+			result.Truncated = true
+			break
+		}
+
+		result.Snippets = append(result.Snippets, snippet)
+		result.TokensUsed += cost
+	}
+
+	return result
+}
+
+// ApplyTokenBudget trims the snippets of response to fit in budget tokens and
+// records the budget usage on the response
+func (f *TextFormatter) ApplyTokenBudget(response *models.DocumentationResponse, budget int) {
+	result := f.SelectWithinTokenBudget(response.Snippets, budget)
+	response.Snippets = result.Snippets
+	response.TokenBudget = budget
+	response.TokensUsed = result.TokensUsed
+	response.Truncated = result.Truncated
+}
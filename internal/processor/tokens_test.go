@@ -0,0 +1,45 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dtomacheski/extract-data-go/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEstimateTokens tests the token estimation heuristic
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, EstimateTokens(""))
+	assert.Equal(t, 1, EstimateTokens("word"))
+	assert.Equal(t, 2, EstimateTokens("words"))
+	assert.Equal(t, 8, EstimateTokens("fmt.Println(x)\n"))
+}
+
+// TestSelectWithinTokenBudget tests that snippets are kept whole and in order
+func TestSelectWithinTokenBudget(t *testing.T) {
+	formatter := NewTextFormatter()
+	snippets := []models.CodeSnippet{
+		{Title: "First", Language: "go", Code: "a := 1"},
+		{Title: "Second", Language: "go", Code: strings.Repeat("b := 2\n", 50)},
+		{Title: "Third", Language: "go", Code: "c := 3"},
+	}
+
+	firstCost := EstimateTokens(formatter.FormatSnippet(snippets[0]))
+
+	result := formatter.SelectWithinTokenBudget(snippets, firstCost+10)
+	assert.Len(t, result.Snippets, 1)
+	assert.Equal(t, firstCost, result.TokensUsed)
+	assert.True(t, result.Truncated)
+
+	// The selected text never exceeds the budget
+	all := formatter.SelectWithinTokenBudget(snippets, 10000)
+	assert.Len(t, all.Snippets, 3)
+	assert.False(t, all.Truncated)
+	assert.LessOrEqual(t, EstimateTokens(formatter.FormatSnippetsToText(all.Snippets)), all.TokensUsed)
+
+	// A budget smaller than the first snippet returns nothing rather than a partial snippet
+	none := formatter.SelectWithinTokenBudget(snippets, 1)
+	assert.Empty(t, none.Snippets)
+	assert.True(t, none.Truncated)
+}
@@ -0,0 +1,84 @@
+// Package progress carries progress events of documentation indexing from the code
+// doing the work (fetch workers, processors) to whoever started it, through the context.
+package progress
+
+import "context"
+
+// EventType identifies a kind of progress event
+type EventType string
+
+// Progress events of an indexing run
+const (
+	// StrategyChosen reports how a stage goes about its work (Stage, Strategy): the
+	// discovery of documentation paths or the fetch of their contents
+	StrategyChosen EventType = "strategy_chosen"
+
+	// StageStarted reports the start of a stage of the run (Stage)
+	StageStarted EventType = "stage_started"
+
+	// PathsFound reports the number of documentation files about to be fetched (Count)
+	PathsFound EventType = "paths_found"
+
+	// FileFetched reports a documentation file read successfully (Path)
+	FileFetched EventType = "file_fetched"
+
+	// FileFailed reports a documentation file that could not be read (Path, Error)
+	FileFailed EventType = "file_failed"
+
+	// DocumentProcessed reports the code snippets extracted from one file (Path, Count)
+	DocumentProcessed EventType = "document_processed"
+
+	// SnippetsExtracted reports the number of code snippets extracted (Count)
+	SnippetsExtracted EventType = "snippets_extracted"
+
+	// Stored reports that the processed documentation was stored (Path is its filename)
+	Stored EventType = "stored"
+)
+
+// Stages reported with StrategyChosen
+const (
+	StageDiscovery = "discovery"
+	StageFetch     = "fetch"
+)
+
+// Event is a single progress event
+type Event struct {
+	Type     EventType `json:"type"`
+	Stage    string    `json:"stage,omitempty"`
+	Strategy string    `json:"strategy,omitempty"`
+	Path     string    `json:"path,omitempty"`
+	Count    int       `json:"count,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Reporter receives progress events. It is called from concurrent goroutines.
+type Reporter func(Event)
+
+// reporterKey carries the Reporter of a context
+type reporterKey struct{}
+
+// WithReporter returns a context whose progress events are passed to reporter
+func WithReporter(ctx context.Context, reporter Reporter) context.Context {
+	return context.WithValue(ctx, reporterKey{}, reporter)
+}
+
+// Report passes an event to the reporter of the context, if any
+func Report(ctx context.Context, event Event) {
+	if reporter, ok := ctx.Value(reporterKey{}).(Reporter); ok && reporter != nil {
+		reporter(event)
+	}
+}
+
+// ReportStrategy reports the strategy chosen for a stage
+func ReportStrategy(ctx context.Context, stage, strategy string) {
+	Report(ctx, Event{Type: StrategyChosen, Stage: stage, Strategy: strategy})
+}
+
+// ReportFile reports the outcome of reading one documentation file
+func ReportFile(ctx context.Context, path string, err error) {
+	if err != nil {
+		Report(ctx, Event{Type: FileFailed, Path: path, Error: err.Error()})
+		return
+	}
+	Report(ctx, Event{Type: FileFetched, Path: path})
+}
@@ -0,0 +1,204 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidConstraint is returned when a version constraint cannot be parsed
+var ErrInvalidConstraint = errors.New("invalid version constraint")
+
+// Keywords accepted in place of a version range
+const (
+	Latest       = "latest"        // Highest version, prereleases included
+	LatestStable = "latest-stable" // Highest version that is not a prerelease
+)
+
+// Constraint is a version requirement such as ^3, ~18.2, >=1.2 <2 or latest-stable
+type Constraint struct {
+	// Package restricts matching to tags of one package, e.g. "react" in react@^18
+	Package string
+
+	// Latest matches every version, including prereleases
+	Latest bool
+
+	comparators []comparator
+	prerelease  bool
+	raw         string
+}
+
+// comparator is a single bound of a version range
+type comparator struct {
+	op      string
+	version Version
+}
+
+// ParseConstraint parses a version constraint. Supported forms are exact versions
+// (1.2.3), partial versions and wildcards (3, 3.x, 3.1.*), caret (^3.1) and tilde
+// (~18.2) ranges, comparisons (>=1.2 <2, separated by spaces or commas) and the
+// keywords latest and latest-stable. A pkg@ prefix restricts matching to that
+// package's tags.
+func ParseConstraint(spec string) (Constraint, error) {
+	c := Constraint{raw: spec}
+	spec = strings.TrimSpace(spec)
+
+	if i := strings.LastIndex(spec, "@"); i > 0 {
+		c.Package, spec = spec[:i], strings.TrimSpace(spec[i+1:])
+	}
+
+	switch strings.ToLower(spec) {
+	case "", Latest:
+		c.Latest = true
+		return c, nil
+	case LatestStable, "stable", "*", "x":
+		return c, nil
+	}
+
+	terms := strings.FieldsFunc(spec, func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+	for _, term := range terms {
+		comparators, err := parseTerm(term)
+		if err != nil {
+			return Constraint{}, fmt.Errorf("%w %q: %v", ErrInvalidConstraint, c.raw, err)
+		}
+		for _, cmp := range comparators {
+			if !cmp.version.IsStable() {
+				c.prerelease = true
+			}
+		}
+		c.comparators = append(c.comparators, comparators...)
+	}
+
+	return c, nil
+}
+
+// parseTerm turns one term of a constraint into comparators
+func parseTerm(term string) ([]comparator, error) {
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(term, op) {
+			v, _, err := parsePartial(strings.TrimPrefix(term, op))
+			if err != nil {
+				return nil, err
+			}
+			return []comparator{{op: op, version: v}}, nil
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(term, "^"):
+		v, parts, err := parsePartial(term[1:])
+		if err != nil {
+			return nil, err
+		}
+		var upper Version
+		switch {
+		case v.Major > 0 || parts == 1:
+			upper = Version{Major: v.Major + 1}
+		case v.Minor > 0 || parts == 2:
+			upper = Version{Minor: v.Minor + 1}
+		default:
+			upper = Version{Patch: v.Patch + 1}
+		}
+		return rangeOf(v, upper), nil
+
+	case strings.HasPrefix(term, "~"):
+		v, parts, err := parsePartial(term[1:])
+		if err != nil {
+			return nil, err
+		}
+		upper := Version{Major: v.Major, Minor: v.Minor + 1}
+		if parts == 1 {
+			upper = Version{Major: v.Major + 1}
+		}
+		return rangeOf(v, upper), nil
+	}
+
+	v, parts, err := parsePartial(term)
+	if err != nil {
+		return nil, err
+	}
+	switch parts {
+	case 1:
+		return rangeOf(v, Version{Major: v.Major + 1}), nil
+	case 2:
+		return rangeOf(v, Version{Major: v.Major, Minor: v.Minor + 1}), nil
+	}
+	return []comparator{{op: "=", version: v}}, nil
+}
+
+// rangeOf returns the comparators of the half-open range [lower, upper)
+func rangeOf(lower, upper Version) []comparator {
+	// The upper bound excludes the prereleases of upper itself, e.g. ^1 excludes 2.0.0-rc.1
+	upper.Prerelease = "0"
+	return []comparator{{op: ">=", version: lower}, {op: "<", version: upper}}
+}
+
+// parsePartial parses a possibly partial version such as 3, 3.1, 3.x or v3.1.2-rc.1
+// and returns how many of its numbers were given
+func parsePartial(s string) (Version, int, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(s), "v"), "V")
+	s, _, _ = strings.Cut(s, "+")
+	core, prerelease, _ := strings.Cut(s, "-")
+
+	var numbers [3]int
+	parts := 0
+	for _, part := range strings.Split(core, ".") {
+		if part == "x" || part == "X" || part == "*" {
+			break
+		}
+		if parts == 3 {
+			return Version{}, 0, fmt.Errorf("too many version numbers in %q", s)
+		}
+		n, ok := parseNumber(part)
+		if !ok {
+			return Version{}, 0, fmt.Errorf("invalid version %q", s)
+		}
+		numbers[parts] = n
+		parts++
+	}
+	if parts == 0 {
+		return Version{}, 0, fmt.Errorf("missing version number in %q", s)
+	}
+	if prerelease != "" && parts < 3 {
+		return Version{}, 0, fmt.Errorf("prerelease requires a full version in %q", s)
+	}
+
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2], Prerelease: prerelease}, parts, nil
+}
+
+// Matches reports whether v satisfies the constraint. Prereleases only match
+// the latest keyword or constraints that mention a prerelease themselves.
+func (c Constraint) Matches(v Version) bool {
+	if !v.IsStable() && !c.Latest && !c.prerelease {
+		return false
+	}
+
+	for _, cmp := range c.comparators {
+		order := v.Compare(cmp.version)
+		var ok bool
+		switch cmp.op {
+		case ">=":
+			ok = order >= 0
+		case ">":
+			ok = order > 0
+		case "<=":
+			ok = order <= 0
+		case "<":
+			ok = order < 0
+		default:
+			ok = order == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String returns the constraint as it was given
+func (c Constraint) String() string {
+	return c.raw
+}
@@ -0,0 +1,117 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrNoMatchingVersion is returned when no tag satisfies a version constraint
+var ErrNoMatchingVersion = errors.New("no matching version found")
+
+// TagLister lists the tag names of a repository
+type TagLister interface {
+	ListTags(ctx context.Context, owner, repo string) ([]string, error)
+}
+
+// Registry resolves library versions to the git tags of their repositories
+type Registry struct {
+	tags TagLister
+}
+
+// NewRegistry creates a new registry listing tags through the given lister
+func NewRegistry(tags TagLister) *Registry {
+	return &Registry{
+		tags: tags,
+	}
+}
+
+// Versions returns the tags of a repository that parse as semantic versions,
+// highest first. Tags of different packages with the same version keep the
+// order in which they were listed.
+func (r *Registry) Versions(ctx context.Context, owner, repo string) ([]Version, error) {
+	tags, err := r.tags.ListTags(ctx, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags of %s/%s: %w", owner, repo, err)
+	}
+
+	versions := make([]Version, 0, len(tags))
+	for _, tag := range tags {
+		if v, ok := ParseVersion(tag); ok {
+			versions = append(versions, v)
+		}
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Compare(versions[j]) > 0
+	})
+
+	return versions, nil
+}
+
+// Resolve returns the tag of the highest version of a repository satisfying
+// the constraint, e.g. "^3" or "react@~18.2". Without a package in the
+// constraint, tags without a package prefix are preferred when there are any.
+func (r *Registry) Resolve(ctx context.Context, owner, repo, spec string) (string, error) {
+	constraint, err := ParseConstraint(spec)
+	if err != nil {
+		return "", err
+	}
+
+	versions, err := r.Versions(ctx, owner, repo)
+	if err != nil {
+		return "", err
+	}
+
+	v, ok := Select(versions, constraint)
+	if !ok {
+		return "", fmt.Errorf("%w for %q in %s/%s", ErrNoMatchingVersion, spec, owner, repo)
+	}
+
+	return v.Tag, nil
+}
+
+// Select returns the highest of the versions, sorted highest first, that satisfies the constraint
+func Select(versions []Version, constraint Constraint) (Version, bool) {
+	pkg := constraint.Package
+	if pkg == "" && !hasUnscoped(versions) && len(versions) > 0 {
+		// Monorepos that only publish pkg@ tags: fall back to any package
+		pkg = "*"
+	}
+
+	for _, v := range versions {
+		if pkg != "*" && !strings.EqualFold(v.Package, pkg) {
+			continue
+		}
+		if constraint.Matches(v) {
+			return v, true
+		}
+	}
+
+	return Version{}, false
+}
+
+// hasUnscoped reports whether any version has no package prefix
+func hasUnscoped(versions []Version) bool {
+	for _, v := range versions {
+		if v.Package == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Tags returns the tag names of versions, keeping at most limit of them (all when limit <= 0)
+func Tags(versions []Version, limit int) []string {
+	if limit > 0 && len(versions) > limit {
+		versions = versions[:limit]
+	}
+
+	tags := make([]string, 0, len(versions))
+	for _, v := range versions {
+		tags = append(tags, v.Tag)
+	}
+	return tags
+}
@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTagLister returns a fixed list of tags
+type fakeTagLister []string
+
+func (f fakeTagLister) ListTags(ctx context.Context, owner, repo string) ([]string, error) {
+	return f, nil
+}
+
+// TestParseVersion tests parsing of tag names
+func TestParseVersion(t *testing.T) {
+	v, ok := ParseVersion("v3.4.1")
+	require.True(t, ok)
+	assert.Equal(t, "3.4.1", v.String())
+	assert.Equal(t, "v3.4.1", v.Tag)
+
+	v, ok = ParseVersion("@tailwindcss/vite@4.0.0-beta.2+build.5")
+	require.True(t, ok)
+	assert.Equal(t, "@tailwindcss/vite", v.Package)
+	assert.Equal(t, "beta.2", v.Prerelease)
+
+	v, ok = ParseVersion("tools/v0.21")
+	require.True(t, ok)
+	assert.Equal(t, "tools", v.Package)
+	assert.Equal(t, "0.21.0", v.String())
+
+	for _, tag := range []string{"canary", "release-1.0", "v1.2.3.4", "v"} {
+		_, ok := ParseVersion(tag)
+		assert.False(t, ok, tag)
+	}
+}
+
+// TestCompare tests semver precedence
+func TestCompare(t *testing.T) {
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.10.0"}
+	for i := 1; i < len(ordered); i++ {
+		lower, _ := ParseVersion(ordered[i-1])
+		higher, _ := ParseVersion(ordered[i])
+		assert.Equal(t, -1, lower.Compare(higher), "%s < %s", ordered[i-1], ordered[i])
+		assert.Equal(t, 1, higher.Compare(lower), "%s > %s", ordered[i], ordered[i-1])
+	}
+}
+
+// TestResolve tests constraint resolution against a repository's tags
+func TestResolve(t *testing.T) {
+	registry := NewRegistry(fakeTagLister{
+		"v4.0.0-beta.1", "v3.4.1", "v3.4.0", "v3.3.5", "v2.2.19", "v1.0.0", "canary",
+		"v18.2.0", "v18.3.1", "v0.3.2", "v0.2.9",
+	})
+
+	tests := []struct {
+		spec string
+		tag  string
+	}{
+		{spec: "latest-stable", tag: "v18.3.1"},
+		{spec: "latest", tag: "v18.3.1"},
+		{spec: "^3", tag: "v3.4.1"},
+		{spec: "3.3", tag: "v3.3.5"},
+		{spec: "~18.2", tag: "v18.2.0"},
+		{spec: "^0.2", tag: "v0.2.9"},
+		{spec: ">=2 <3.4", tag: "v3.3.5"},
+		{spec: "v1.0.0", tag: "v1.0.0"},
+		{spec: "^4.0.0-beta.0", tag: "v4.0.0-beta.1"},
+	}
+
+	for _, tt := range tests {
+		tag, err := registry.Resolve(context.Background(), "tailwindlabs", "tailwindcss", tt.spec)
+		require.NoError(t, err, tt.spec)
+		assert.Equal(t, tt.tag, tag, tt.spec)
+	}
+
+	_, err := registry.Resolve(context.Background(), "tailwindlabs", "tailwindcss", "^4")
+	assert.True(t, errors.Is(err, ErrNoMatchingVersion))
+
+	_, err = registry.Resolve(context.Background(), "tailwindlabs", "tailwindcss", "^three")
+	assert.True(t, errors.Is(err, ErrInvalidConstraint))
+}
+
+// TestResolve_Packages tests monorepo tags with package prefixes
+func TestResolve_Packages(t *testing.T) {
+	registry := NewRegistry(fakeTagLister{"react@18.2.0", "react-dom@18.3.0", "react@17.0.2"})
+
+	tag, err := registry.Resolve(context.Background(), "facebook", "react", "react@^17")
+	require.NoError(t, err)
+	assert.Equal(t, "react@17.0.2", tag)
+
+	// Without a package, any package's tags are used when none are unscoped
+	tag, err = registry.Resolve(context.Background(), "facebook", "react", "^18")
+	require.NoError(t, err)
+	assert.Equal(t, "react-dom@18.3.0", tag)
+}
@@ -0,0 +1,151 @@
+package registry
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Version is a release tag parsed as a semantic version
+type Version struct {
+	// Package is the package name of monorepo tags such as react@18.2.0 or tools/v0.1.0
+	Package string
+
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+
+	// Tag is the original tag name, used as the git ref
+	Tag string
+}
+
+// ParseVersion parses a tag such as 1.2.3, v1.2.3-beta.1, pkg@1.2.3 or pkg/v1.2.3.
+// Missing minor and patch numbers default to zero; build metadata is ignored.
+func ParseVersion(tag string) (Version, bool) {
+	v := Version{Tag: tag}
+	rest := strings.TrimSpace(tag)
+
+	// Package prefixes: "pkg@1.2.3", "@scope/pkg@1.2.3" and Go style "pkg/v1.2.3"
+	if i := strings.LastIndex(rest, "@"); i > 0 {
+		v.Package, rest = rest[:i], rest[i+1:]
+	} else if i := strings.LastIndex(rest, "/"); i > 0 {
+		v.Package, rest = rest[:i], rest[i+1:]
+	}
+
+	rest = strings.TrimPrefix(strings.TrimPrefix(rest, "v"), "V")
+	rest, _, _ = strings.Cut(rest, "+")
+	core, prerelease, _ := strings.Cut(rest, "-")
+	v.Prerelease = prerelease
+
+	numbers, ok := parseNumbers(core)
+	if !ok {
+		return Version{}, false
+	}
+	v.Major, v.Minor, v.Patch = numbers[0], numbers[1], numbers[2]
+
+	return v, true
+}
+
+// parseNumbers parses one to three dot-separated numbers, padding missing ones with zero
+func parseNumbers(core string) ([3]int, bool) {
+	var numbers [3]int
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return numbers, false
+	}
+
+	for i, part := range parts {
+		n, ok := parseNumber(part)
+		if !ok {
+			return numbers, false
+		}
+		numbers[i] = n
+	}
+
+	return numbers, true
+}
+
+// parseNumber parses a non-negative decimal number made only of digits
+func parseNumber(s string) (int, bool) {
+	if s == "" || strings.TrimLeft(s, "0123456789") != "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s)
+	return n, err == nil
+}
+
+// IsStable reports whether the version is not a prerelease
+func (v Version) IsStable() bool {
+	return v.Prerelease == ""
+}
+
+// String formats the version without its package or "v" prefix
+func (v Version) String() string {
+	s := strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor) + "." + strconv.Itoa(v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 depending on whether v is lower, equal or higher
+// than other, following semver precedence rules
+func (v Version) Compare(other Version) int {
+	if c := compareInts(v.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := compareInts(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+	if c := compareInts(v.Patch, other.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(v.Prerelease, other.Prerelease)
+}
+
+// compareInts compares two integers
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// comparePrerelease compares prerelease identifiers. A release ranks above
+// any of its prereleases; numeric identifiers rank below alphanumeric ones.
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aIsNum := parseNumber(aParts[i])
+		bNum, bIsNum := parseNumber(bParts[i])
+
+		var c int
+		switch {
+		case aIsNum && bIsNum:
+			c = compareInts(aNum, bNum)
+		case aIsNum:
+			c = -1
+		case bIsNum:
+			c = 1
+		default:
+			c = strings.Compare(aParts[i], bParts[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+
+	return compareInts(len(aParts), len(bParts))
+}
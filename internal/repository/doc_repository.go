@@ -5,19 +5,27 @@ import (
 	"errors"
 	"log"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dtomacheski/extract-data-go/internal/database"
 	"github.com/dtomacheski/extract-data-go/internal/models"
 	"github.com/dtomacheski/extract-data-go/internal/processor"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
+// DocumentUpdateListener is notified after a processed document of a ref was stored
+type DocumentUpdateListener func(owner, repo, ref, filename string)
+
 // DocumentRepository handles document storage and retrieval operations
 type DocumentRepository struct {
 	mongoClient   *database.Client
 	logger        *log.Logger
 	enabled       bool
 	textFormatter *processor.TextFormatter
+
+	listenersMu sync.RWMutex
+	listeners   []DocumentUpdateListener
 }
 
 // NewDocumentRepository creates a new document repository
@@ -48,15 +56,15 @@ func (r *DocumentRepository) StoreDocumentation(ctx context.Context, docs []mode
 		return nil
 	}
 
-	// Obter owner/repo do RepoName (formato: owner/repo)
-	repoParts := strings.Split(docs[0].RepoName, "/")
-	if len(repoParts) != 2 {
+	// Obter owner/repo do RepoName (formato: owner/repo, ou group/subgroup/repo no GitLab)
+	sep := strings.LastIndex(docs[0].RepoName, "/")
+	if sep <= 0 || sep == len(docs[0].RepoName)-1 {
 		r.logger.Printf("Invalid repository name format: %s", docs[0].RepoName)
 		return nil
 	}
 
-	repoOwner := repoParts[0]
-	repoName := repoParts[1]
+	repoOwner := docs[0].RepoName[:sep]
+	repoName := docs[0].RepoName[sep+1:]
 
 	// Processar e formatar a documentação
 	filename, formattedText, snippetsCount := r.textFormatter.ProcessAndFormatDocumentation(docs, repoOwner, repoName)
@@ -66,10 +74,52 @@ func (r *DocumentRepository) StoreDocumentation(ctx context.Context, docs []mode
 		return nil
 	}
 
+	ref, commitSHA := models.RevisionOf(docs)
+	return r.StoreProcessedDocumentation(ctx, repoOwner, repoName, ref, commitSHA, filename, formattedText, snippetsCount)
+}
+
+// StoreProcessedDocumentation armazena documentação já processada e formatada como TXT,
+// as the current version of the ref and in its history under the commit SHA
+func (r *DocumentRepository) StoreProcessedDocumentation(ctx context.Context, repoOwner, repoName, ref, commitSHA, filename, formattedText string, snippetsCount int) error {
+	if !r.enabled {
+		r.logger.Println("MongoDB storage is disabled, skipping document storage")
+		return nil
+	}
+
 	r.logger.Printf("Storing processed documentation with %d snippets in MongoDB as %s", snippetsCount, filename)
 
 	// Armazenar no MongoDB usando a nova função
-	return r.mongoClient.StoreProcessedDocumentation(ctx, repoOwner, repoName, filename, formattedText, snippetsCount)
+	if err := r.mongoClient.StoreProcessedDocumentation(ctx, repoOwner, repoName, ref, commitSHA, filename, formattedText, snippetsCount); err != nil {
+		return err
+	}
+
+	r.notifyDocumentStored(repoOwner, repoName, ref, filename)
+	return nil
+}
+
+// OnDocumentStored registers a listener called whenever a processed document is stored
+func (r *DocumentRepository) OnDocumentStored(listener DocumentUpdateListener) {
+	r.listenersMu.Lock()
+	defer r.listenersMu.Unlock()
+	r.listeners = append(r.listeners, listener)
+}
+
+// notifyDocumentStored calls the registered update listeners
+func (r *DocumentRepository) notifyDocumentStored(owner, repo, ref, filename string) {
+	r.listenersMu.RLock()
+	defer r.listenersMu.RUnlock()
+	for _, listener := range r.listeners {
+		listener(owner, repo, ref, filename)
+	}
+}
+
+// ListProcessedDocumentation lists stored processed documents without their content
+func (r *DocumentRepository) ListProcessedDocumentation(ctx context.Context, offset, limit int) ([]database.DocStorage, error) {
+	if !r.enabled {
+		return nil, nil
+	}
+
+	return r.mongoClient.ListProcessedDocumentation(ctx, int64(offset), int64(limit))
 }
 
 // GetProcessedDocumentation recupera documentação processada pelo caminho
@@ -94,6 +144,44 @@ func (r *DocumentRepository) GetDocumentationByRepoID(ctx context.Context, repoI
 	return r.mongoClient.GetDocumentationByRepoID(ctx, repoID)
 }
 
+// GetCurrentDocumentation retrieves the current processed documentation of a repository at
+// a ref. An empty ref returns the most recently stored documentation of any ref.
+func (r *DocumentRepository) GetCurrentDocumentation(ctx context.Context, owner, repo, ref string) (*database.DocStorage, error) {
+	if !r.enabled {
+		return nil, database.ErrDocVersionNotFound
+	}
+
+	filename := r.textFormatter.GenerateFilename(owner, repo)
+	if ref == "" {
+		doc, err := r.GetProcessedDocumentation(ctx, owner, repo, filename)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, database.ErrDocVersionNotFound
+		}
+		return doc, err
+	}
+	return r.mongoClient.GetCurrentDocumentation(ctx, owner+"/"+repo, ref, filename)
+}
+
+// ListDocumentationVersions lists the stored versions of a repository's documentation,
+// most recent first. An empty ref lists the versions of every ref.
+func (r *DocumentRepository) ListDocumentationVersions(ctx context.Context, owner, repo, ref string) ([]database.DocVersion, error) {
+	if !r.enabled {
+		return nil, nil
+	}
+
+	return r.mongoClient.ListDocumentationVersions(ctx, owner+"/"+repo, ref)
+}
+
+// GetDocumentationVersion retrieves the documentation of a repository stored for a commit
+func (r *DocumentRepository) GetDocumentationVersion(ctx context.Context, owner, repo, commitSHA string) (*database.DocVersion, error) {
+	if !r.enabled {
+		return nil, database.ErrDocVersionNotFound
+	}
+
+	filename := r.textFormatter.GenerateFilename(owner, repo)
+	return r.mongoClient.GetDocumentationVersion(ctx, owner+"/"+repo, filename, commitSHA)
+}
+
 // IsEnabled returns whether MongoDB storage is enabled
 func (r *DocumentRepository) IsEnabled() bool {
 	return r.enabled
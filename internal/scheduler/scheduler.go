@@ -0,0 +1,345 @@
+// Package scheduler refreshes the documentation of tracked repositories in the
+// background, re-indexing each one when its refresh interval is due and its ref
+// points to a new commit.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dtomacheski/extract-data-go/internal/database"
+	"github.com/dtomacheski/extract-data-go/internal/jobs"
+)
+
+const (
+	// DefaultInterval is the refresh interval of repositories tracked without one
+	DefaultInterval = 24 * time.Hour
+
+	// MinInterval is the shortest refresh interval accepted
+	MinInterval = 5 * time.Minute
+
+	// jitterFraction spreads the runs of repositories tracked together: each next run
+	// is moved by up to this fraction of the interval, earlier or later
+	jitterFraction = 0.1
+
+	// jobPollInterval is how often the state of a refresh job is read while waiting for it
+	jobPollInterval = 2 * time.Second
+)
+
+// ErrInvalidInterval is returned for refresh intervals shorter than MinInterval
+var ErrInvalidInterval = fmt.Errorf("refresh interval must be at least %s", MinInterval)
+
+// ErrInvalidRepository is returned when the owner or name of a repository is missing
+var ErrInvalidRepository = errors.New("owner and repo are required")
+
+// Store keeps the tracked repositories
+type Store interface {
+	CreateTrackedRepo(ctx context.Context, repo *database.TrackedRepo) error
+	ListTrackedRepos(ctx context.Context) ([]database.TrackedRepo, error)
+	GetTrackedRepo(ctx context.Context, id string) (*database.TrackedRepo, error)
+	UpdateTrackedRepo(ctx context.Context, repo *database.TrackedRepo) error
+	DeleteTrackedRepo(ctx context.Context, id string) error
+	DueTrackedRepos(ctx context.Context, now time.Time, limit int) ([]database.TrackedRepo, error)
+	ClaimTrackedRepo(ctx context.Context, repo *database.TrackedRepo, nextRunAt time.Time) (bool, error)
+	SaveTrackedRepoStatus(ctx context.Context, repo *database.TrackedRepo) error
+}
+
+// Jobs runs the indexing jobs refreshing repositories
+type Jobs interface {
+	Submit(ctx context.Context, owner, repo, ref string) (*jobs.Job, error)
+	Get(ctx context.Context, id string) (*jobs.Job, error)
+}
+
+// CommitResolver resolves a ref to the commit it points to
+type CommitResolver interface {
+	ResolveCommit(ctx context.Context, owner, repo, ref string) (string, error)
+}
+
+// Scheduler re-indexes tracked repositories when due, a limited number at a time
+type Scheduler struct {
+	store        Store
+	jobs         Jobs
+	commits      CommitResolver
+	pollInterval time.Duration
+	jobPoll      time.Duration
+	logger       *log.Logger
+
+	slots  chan struct{} // One per refresh in progress
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewScheduler creates a scheduler looking for due repositories every pollInterval
+// and refreshing up to concurrency of them at a time
+func NewScheduler(store Store, jobManager Jobs, commits CommitResolver, concurrency int, pollInterval time.Duration, logger *log.Logger) *Scheduler {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	if pollInterval <= 0 {
+		pollInterval = time.Minute
+	}
+	return &Scheduler{
+		store:        store,
+		jobs:         jobManager,
+		commits:      commits,
+		pollInterval: pollInterval,
+		jobPoll:      jobPollInterval,
+		logger:       logger,
+		slots:        make(chan struct{}, concurrency),
+	}
+}
+
+// Track starts tracking a repository at a ref (the default branch when empty). Its
+// first refresh is due right away; interval 0 selects DefaultInterval.
+func (s *Scheduler) Track(ctx context.Context, owner, repo, ref string, interval time.Duration) (*database.TrackedRepo, error) {
+	owner, repo, ref = strings.TrimSpace(owner), strings.TrimSpace(repo), strings.TrimSpace(ref)
+	if owner == "" || repo == "" {
+		return nil, ErrInvalidRepository
+	}
+	interval, err := checkInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	tracked := &database.TrackedRepo{
+		Owner:           owner,
+		Repo:            repo,
+		Ref:             ref,
+		IntervalMinutes: int(interval / time.Minute),
+		NextRunAt:       time.Now(),
+	}
+	if err := s.store.CreateTrackedRepo(ctx, tracked); err != nil {
+		return nil, err
+	}
+	s.logger.Printf("Tracking %s/%s (ref: %s) every %s", owner, repo, ref, interval)
+	return tracked, nil
+}
+
+// List lists the tracked repositories
+func (s *Scheduler) List(ctx context.Context) ([]database.TrackedRepo, error) {
+	return s.store.ListTrackedRepos(ctx)
+}
+
+// Get returns a tracked repository by ID
+func (s *Scheduler) Get(ctx context.Context, id string) (*database.TrackedRepo, error) {
+	return s.store.GetTrackedRepo(ctx, id)
+}
+
+// Update changes the ref and refresh interval of a tracked repository. A new ref is
+// refreshed right away, even at the commit last indexed for the previous ref; otherwise
+// the next run follows the new interval.
+func (s *Scheduler) Update(ctx context.Context, id, ref string, interval time.Duration) (*database.TrackedRepo, error) {
+	interval, err := checkInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+	tracked, err := s.store.GetTrackedRepo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	ref = strings.TrimSpace(ref)
+	if ref != tracked.Ref {
+		tracked.Ref, tracked.NextRunAt, tracked.LastCommitSHA = ref, now, ""
+	} else {
+		last := tracked.CreatedAt
+		if tracked.LastCheckedAt != nil {
+			last = *tracked.LastCheckedAt
+		}
+		tracked.NextRunAt = last.Add(interval)
+		if tracked.NextRunAt.Before(now) {
+			tracked.NextRunAt = now
+		}
+	}
+	tracked.IntervalMinutes = int(interval / time.Minute)
+
+	if err := s.store.UpdateTrackedRepo(ctx, tracked); err != nil {
+		return nil, err
+	}
+	return tracked, nil
+}
+
+// Untrack stops tracking a repository. Its stored documentation is kept.
+func (s *Scheduler) Untrack(ctx context.Context, id string) error {
+	return s.store.DeleteTrackedRepo(ctx, id)
+}
+
+// Start starts looking for due repositories in the background
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go s.loop(ctx)
+	s.logger.Printf("Scheduler started: checking tracked repositories every %s, %d at a time", s.pollInterval, cap(s.slots))
+}
+
+// Stop stops the scheduler and waits for the refreshes in progress to let go of their
+// jobs. The jobs themselves are left to the job manager.
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+	s.logger.Println("Scheduler stopped")
+}
+
+// loop refreshes due repositories every poll interval until ctx is cancelled
+func (s *Scheduler) loop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.runDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDue starts the refresh of as many due repositories as there are free slots.
+// Each repository is claimed first so that concurrent instances do not refresh it twice.
+func (s *Scheduler) runDue(ctx context.Context) {
+	free := cap(s.slots) - len(s.slots)
+	if free == 0 {
+		return
+	}
+
+	due, err := s.store.DueTrackedRepos(ctx, time.Now(), free)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Printf("Failed to list due tracked repositories: %v", err)
+		}
+		return
+	}
+
+	for i := range due {
+		tracked := due[i]
+		claimed, err := s.store.ClaimTrackedRepo(ctx, &tracked, nextRun(time.Now(), tracked.Interval()))
+		if err != nil {
+			s.logger.Printf("Failed to claim the refresh of %s/%s: %v", tracked.Owner, tracked.Repo, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		// Slots are only taken here, so this never blocks
+		s.slots <- struct{}{}
+		s.wg.Add(1)
+		go func() {
+			defer func() {
+				<-s.slots
+				s.wg.Done()
+			}()
+			s.refresh(ctx, &tracked)
+		}()
+	}
+}
+
+// refresh re-indexes a repository unless its ref still points to the last indexed commit
+func (s *Scheduler) refresh(ctx context.Context, tracked *database.TrackedRepo) {
+	name := tracked.Owner + "/" + tracked.Repo
+	checked := time.Now()
+	tracked.LastCheckedAt = &checked
+
+	commitSHA, err := s.commits.ResolveCommit(ctx, tracked.Owner, tracked.Repo, tracked.Ref)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		s.logger.Printf("Scheduled refresh of %s (ref: %s) failed to resolve the ref: %v", name, tracked.Ref, err)
+		tracked.LastError = err.Error()
+		s.saveStatus(ctx, tracked)
+		return
+	}
+
+	if commitSHA == tracked.LastCommitSHA {
+		s.logger.Printf("Scheduled refresh of %s (ref: %s) skipped: still at %s", name, tracked.Ref, commitSHA)
+		tracked.LastError = ""
+		s.saveStatus(ctx, tracked)
+		return
+	}
+
+	job, err := s.jobs.Submit(ctx, tracked.Owner, tracked.Repo, tracked.Ref)
+	if err != nil {
+		s.logger.Printf("Scheduled refresh of %s (ref: %s) could not be queued: %v", name, tracked.Ref, err)
+		tracked.LastError = err.Error()
+		s.saveStatus(ctx, tracked)
+		return
+	}
+	tracked.LastJobID = job.ID
+	s.logger.Printf("Scheduled refresh of %s (ref: %s) queued as job %s for commit %s", name, tracked.Ref, job.ID, commitSHA)
+
+	job, err = s.wait(ctx, job.ID)
+	if ctx.Err() != nil {
+		return
+	}
+	switch {
+	case err != nil:
+		tracked.LastError = err.Error()
+	case job.State == jobs.StateSucceeded:
+		tracked.LastCommitSHA, tracked.LastRefreshedAt, tracked.LastError = job.CommitSHA, job.FinishedAt, ""
+	default:
+		tracked.LastError = job.Error
+	}
+	s.saveStatus(ctx, tracked)
+}
+
+// wait polls a job until it finishes or ctx is cancelled
+func (s *Scheduler) wait(ctx context.Context, id string) (*jobs.Job, error) {
+	ticker := time.NewTicker(s.jobPoll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		job, err := s.jobs.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if job.Finished() {
+			return job, nil
+		}
+	}
+}
+
+// saveStatus saves the outcome of a refresh, logging failures
+func (s *Scheduler) saveStatus(ctx context.Context, tracked *database.TrackedRepo) {
+	if err := s.store.SaveTrackedRepoStatus(ctx, tracked); err != nil && ctx.Err() == nil {
+		s.logger.Printf("Failed to save the refresh status of %s/%s: %v", tracked.Owner, tracked.Repo, err)
+	}
+}
+
+// checkInterval applies the default interval and rejects intervals that are too short
+func checkInterval(interval time.Duration) (time.Duration, error) {
+	if interval == 0 {
+		return DefaultInterval, nil
+	}
+	if interval < MinInterval {
+		return 0, ErrInvalidInterval
+	}
+	return interval.Truncate(time.Minute), nil
+}
+
+// nextRun returns the time of the next refresh, moved by a random jitter
+func nextRun(now time.Time, interval time.Duration) time.Time {
+	jitter := time.Duration((rand.Float64()*2 - 1) * jitterFraction * float64(interval))
+	return now.Add(interval + jitter)
+}
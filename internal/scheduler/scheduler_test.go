@@ -0,0 +1,210 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dtomacheski/extract-data-go/internal/database"
+	"github.com/dtomacheski/extract-data-go/internal/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// memoryStore keeps tracked repositories in a map
+type memoryStore struct {
+	mu    sync.Mutex
+	repos map[string]database.TrackedRepo
+}
+
+func (s *memoryStore) CreateTrackedRepo(ctx context.Context, repo *database.TrackedRepo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	repo.ID = bson.NewObjectID()
+	s.repos[repo.ID.Hex()] = *repo
+	return nil
+}
+
+func (s *memoryStore) ListTrackedRepos(ctx context.Context) ([]database.TrackedRepo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []database.TrackedRepo
+	for _, repo := range s.repos {
+		list = append(list, repo)
+	}
+	return list, nil
+}
+
+func (s *memoryStore) GetTrackedRepo(ctx context.Context, id string) (*database.TrackedRepo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	repo, ok := s.repos[id]
+	if !ok {
+		return nil, database.ErrTrackedRepoNotFound
+	}
+	return &repo, nil
+}
+
+func (s *memoryStore) UpdateTrackedRepo(ctx context.Context, repo *database.TrackedRepo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repos[repo.ID.Hex()] = *repo
+	return nil
+}
+
+func (s *memoryStore) DeleteTrackedRepo(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.repos, id)
+	return nil
+}
+
+func (s *memoryStore) DueTrackedRepos(ctx context.Context, now time.Time, limit int) ([]database.TrackedRepo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []database.TrackedRepo
+	for _, repo := range s.repos {
+		if !repo.NextRunAt.After(now) {
+			due = append(due, repo)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextRunAt.Before(due[j].NextRunAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (s *memoryStore) ClaimTrackedRepo(ctx context.Context, repo *database.TrackedRepo, nextRunAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := s.repos[repo.ID.Hex()]
+	if !stored.NextRunAt.Equal(repo.NextRunAt) {
+		return false, nil
+	}
+	stored.NextRunAt, repo.NextRunAt = nextRunAt, nextRunAt
+	s.repos[repo.ID.Hex()] = stored
+	return true, nil
+}
+
+func (s *memoryStore) SaveTrackedRepoStatus(ctx context.Context, repo *database.TrackedRepo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := s.repos[repo.ID.Hex()]
+	stored.LastCheckedAt, stored.LastRefreshedAt = repo.LastCheckedAt, repo.LastRefreshedAt
+	stored.LastCommitSHA, stored.LastJobID, stored.LastError = repo.LastCommitSHA, repo.LastJobID, repo.LastError
+	s.repos[repo.ID.Hex()] = stored
+	return nil
+}
+
+// fakeJobs finishes every job right away at commit "sha1", except for repo "broken"
+type fakeJobs struct {
+	mu        sync.Mutex
+	submitted []string
+	jobs      map[string]*jobs.Job
+}
+
+func (f *fakeJobs) Submit(ctx context.Context, owner, repo, ref string) (*jobs.Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	job := jobs.NewJob(owner, repo, ref)
+	finished := time.Now()
+	job.FinishedAt = &finished
+	if repo == "broken" {
+		job.State, job.Error = jobs.StateFailed, "no documentation files found"
+	} else {
+		job.State, job.CommitSHA = jobs.StateSucceeded, "sha1"
+	}
+	f.submitted = append(f.submitted, repo)
+	f.jobs[job.ID] = job
+	return job, nil
+}
+
+func (f *fakeJobs) Get(ctx context.Context, id string) (*jobs.Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.jobs[id], nil
+}
+
+func (f *fakeJobs) submissions() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.submitted...)
+}
+
+// fixedCommit resolves every ref to "sha1"
+type fixedCommit struct{}
+
+func (fixedCommit) ResolveCommit(ctx context.Context, owner, repo, ref string) (string, error) {
+	return "sha1", nil
+}
+
+// TestScheduler tests that due repositories are refreshed a limited number at a time
+// and skipped while their ref points to the last indexed commit
+func TestScheduler(t *testing.T) {
+	store := &memoryStore{repos: make(map[string]database.TrackedRepo)}
+	jobManager := &fakeJobs{jobs: make(map[string]*jobs.Job)}
+	s := NewScheduler(store, jobManager, fixedCommit{}, 1, time.Minute, log.New(io.Discard, "", 0))
+	s.jobPoll = time.Millisecond
+	ctx := context.Background()
+
+	_, err := s.Track(ctx, "owner", "repo", "", time.Minute)
+	assert.ErrorIs(t, err, ErrInvalidInterval)
+	_, err = s.Track(ctx, "", "repo", "", 0)
+	assert.ErrorIs(t, err, ErrInvalidRepository)
+
+	tracked, err := s.Track(ctx, "owner", "repo", "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 24*60, tracked.IntervalMinutes)
+	broken, err := s.Track(ctx, "owner", "broken", "main", time.Hour)
+	require.NoError(t, err)
+
+	// One refresh at a time: the most overdue repository goes first
+	s.runDue(ctx)
+	s.wg.Wait()
+	assert.Equal(t, []string{"repo"}, jobManager.submissions())
+	s.runDue(ctx)
+	s.wg.Wait()
+	assert.Equal(t, []string{"repo", "broken"}, jobManager.submissions())
+
+	refreshed, err := s.Get(ctx, tracked.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, "sha1", refreshed.LastCommitSHA)
+	assert.NotNil(t, refreshed.LastRefreshedAt)
+	assert.Empty(t, refreshed.LastError)
+	assert.WithinDuration(t, time.Now().Add(DefaultInterval), refreshed.NextRunAt, DefaultInterval/10+time.Minute)
+
+	failed, err := s.Get(ctx, broken.ID.Hex())
+	require.NoError(t, err)
+	assert.Empty(t, failed.LastCommitSHA)
+	assert.Equal(t, "no documentation files found", failed.LastError)
+
+	// Nothing is due until the next run
+	s.runDue(ctx)
+	s.wg.Wait()
+	assert.Len(t, jobManager.submissions(), 2)
+
+	// Due again but still at the indexed commit: no job
+	refreshed.NextRunAt = time.Now().Add(-time.Second)
+	require.NoError(t, store.UpdateTrackedRepo(ctx, refreshed))
+	s.runDue(ctx)
+	s.wg.Wait()
+	assert.Len(t, jobManager.submissions(), 2)
+	checked, err := s.Get(ctx, tracked.ID.Hex())
+	require.NoError(t, err)
+	assert.True(t, checked.NextRunAt.After(time.Now()))
+
+	// A new ref is refreshed right away, even when it points to the commit indexed for the old one
+	updated, err := s.Update(ctx, tracked.ID.Hex(), "v2", 0)
+	require.NoError(t, err)
+	assert.False(t, updated.NextRunAt.After(time.Now()))
+	assert.Empty(t, updated.LastCommitSHA)
+	s.runDue(ctx)
+	s.wg.Wait()
+	assert.Equal(t, []string{"repo", "broken", "repo"}, jobManager.submissions())
+}
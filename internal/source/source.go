@@ -0,0 +1,106 @@
+// Package source selects the forge documentation is fetched from by repository host.
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/dtomacheski/extract-data-go/internal/gitea"
+	"github.com/dtomacheski/extract-data-go/internal/github"
+	"github.com/dtomacheski/extract-data-go/internal/gitlab"
+	"github.com/dtomacheski/extract-data-go/internal/local"
+	"github.com/dtomacheski/extract-data-go/internal/models"
+)
+
+// GitHubHost is the host of github.com repositories
+const GitHubHost = "github.com"
+
+// ErrUnsupportedHost is returned for repository hosts without a configured source
+var ErrUnsupportedHost = errors.New("unsupported repository host")
+
+// DocSource is a forge that repository documentation can be fetched from
+type DocSource interface {
+	// GetRepository fetches the metadata of a repository
+	GetRepository(ctx context.Context, owner, repo string) (*models.Repository, error)
+
+	// GetRepositoryDocumentationInPath fetches the documentation of a repository at ref, or
+	// at defaultBranch when ref is empty, restricted to subPath when it is set
+	GetRepositoryDocumentationInPath(ctx context.Context, owner, repo, defaultBranch, ref, subPath string, concurrencyLimit int) ([]models.Documentation, error)
+
+	// ListTags lists the tag names of a repository
+	ListTags(ctx context.Context, owner, repo string) ([]string, error)
+}
+
+// Every client is a documentation source
+var (
+	_ DocSource = (*github.Client)(nil)
+	_ DocSource = (*gitlab.Client)(nil)
+	_ DocSource = (*gitea.Client)(nil)
+	_ DocSource = (*local.Source)(nil)
+)
+
+// Providers maps repository hosts to their documentation source
+type Providers struct {
+	sources map[string]DocSource
+}
+
+// NewProviders creates a provider set with github.com served by the given client
+func NewProviders(githubClient DocSource) *Providers {
+	p := &Providers{
+		sources: make(map[string]DocSource),
+	}
+	p.Register(GitHubHost, githubClient)
+	return p
+}
+
+// Register serves repositories on host from src
+func (p *Providers) Register(host string, src DocSource) {
+	p.sources[normalizeHost(host)] = src
+}
+
+// RegisterURL serves repositories on the host of baseURL from src
+func (p *Providers) RegisterURL(baseURL string, src DocSource) error {
+	host, err := HostOf(baseURL)
+	if err != nil {
+		return err
+	}
+	p.Register(host, src)
+	return nil
+}
+
+// ForHost returns the documentation source of a repository host
+func (p *Providers) ForHost(host string) (DocSource, error) {
+	src, ok := p.sources[normalizeHost(host)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s (supported: %s)", ErrUnsupportedHost, host, strings.Join(p.Hosts(), ", "))
+	}
+	return src, nil
+}
+
+// Hosts returns the configured hosts, sorted
+func (p *Providers) Hosts() []string {
+	hosts := make([]string, 0, len(p.sources))
+	for host := range p.sources {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// HostOf returns the host of a forge base URL such as https://gitlab.example.com
+func HostOf(baseURL string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid base URL %q", baseURL)
+	}
+	return normalizeHost(u.Host), nil
+}
+
+// normalizeHost lowercases a host and drops the www. prefix
+func normalizeHost(host string) string {
+	return strings.TrimPrefix(strings.ToLower(host), "www.")
+}
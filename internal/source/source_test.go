@@ -0,0 +1,38 @@
+package source
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dtomacheski/extract-data-go/internal/gitea"
+	"github.com/dtomacheski/extract-data-go/internal/github"
+	"github.com/dtomacheski/extract-data-go/internal/gitlab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestProviders tests source selection by host
+func TestProviders(t *testing.T) {
+	githubClient := github.NewClient("token", time.Second)
+	gitlabClient := gitlab.NewClient("", "", time.Second)
+	giteaClient := gitea.NewClient("https://codeberg.org", "", time.Second)
+
+	providers := NewProviders(githubClient)
+	require.NoError(t, providers.RegisterURL(gitlab.DefaultBaseURL, gitlabClient))
+	require.NoError(t, providers.RegisterURL("https://Codeberg.org/", giteaClient))
+	assert.Equal(t, []string{"codeberg.org", "github.com", "gitlab.com"}, providers.Hosts())
+
+	src, err := providers.ForHost("www.GitHub.com")
+	require.NoError(t, err)
+	assert.Same(t, githubClient, src)
+
+	src, err = providers.ForHost("codeberg.org")
+	require.NoError(t, err)
+	assert.Same(t, giteaClient, src)
+
+	_, err = providers.ForHost("bitbucket.org")
+	assert.True(t, errors.Is(err, ErrUnsupportedHost))
+
+	assert.Error(t, providers.RegisterURL("not a url", giteaClient))
+}
@@ -2,17 +2,160 @@ package utils
 
 import (
 	"fmt"
+	"net/url"
+	"path"
 	"strings"
 )
 
-// ExtractOwnerAndRepo extracts the owner and repository name from a GitHub URL.
+// FileHost is the host reported for file:// URLs of local directories and git repositories
+const FileHost = "file"
+
+// RepositoryURL is a repository URL broken into its parts
+type RepositoryURL struct {
+	Host    string // Forge host, e.g. github.com or gitlab.example.com
+	Owner   string // User or organization; GitLab owners may include subgroups, e.g. group/subgroup
+	Repo    string
+	Ref     string // Branch, tag or commit from the URL, if present
+	SubPath string // Path inside the repository after the ref, if present
+
+	refAndPath string // Unsplit part of the URL after the ref marker
+}
+
+// ExtractOwnerAndRepo extracts the owner and repository name from a repository URL.
 func ExtractOwnerAndRepo(repoURL string) (string, string, error) {
-	// Assume github.com URL for now
-	cleanedURL := strings.TrimPrefix(repoURL, "https://")
+	parsed, err := ParseRepositoryURL(repoURL)
+	if err != nil {
+		return "", "", err
+	}
+	return parsed.Owner, parsed.Repo, nil
+}
+
+// ParseRepositoryURL parses repository URLs of GitHub, GitLab and Gitea/Forgejo, such as
+// https://github.com/owner/repo(.git), https://github.com/owner/repo/tree/<ref>/<subpath>,
+// https://gitlab.com/group/subgroup/repo/-/tree/<ref>/<subpath> or
+// https://codeberg.org/owner/repo/src/branch/<ref>/<subpath>.
+// The ref is taken to be the first segment after the ref marker; use
+// OverrideRef when the ref itself may contain slashes.
+// Local repositories are addressed as file:///path/to/repo(.git)[@ref].
+func ParseRepositoryURL(repoURL string) (*RepositoryURL, error) {
+	cleanedURL := strings.TrimSpace(repoURL)
+	if strings.HasPrefix(cleanedURL, "file://") {
+		return parseFileURL(cleanedURL)
+	}
+
+	cleanedURL = strings.TrimPrefix(cleanedURL, "https://")
 	cleanedURL = strings.TrimPrefix(cleanedURL, "http://")
-	parts := strings.Split(cleanedURL, "/")
-	if len(parts) < 3 || parts[0] != "github.com" {
-		return "", "", fmt.Errorf("invalid or unsupported GitHub URL format: %s", repoURL)
+	cleanedURL = strings.TrimPrefix(cleanedURL, "www.")
+
+	// Drop query string and fragment
+	if i := strings.IndexAny(cleanedURL, "?#"); i >= 0 {
+		cleanedURL = cleanedURL[:i]
+	}
+
+	parts := strings.Split(strings.TrimRight(cleanedURL, "/"), "/")
+	if len(parts) < 3 || parts[0] == "" {
+		return nil, fmt.Errorf("invalid or unsupported repository URL format: %s", repoURL)
+	}
+
+	host := strings.ToLower(parts[0])
+	segments := parts[1:]
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, fmt.Errorf("invalid or unsupported repository URL format: %s", repoURL)
+		}
+	}
+
+	// Locate the end of the repository path and the start of the ref, if any
+	repoEnd, refStart := len(segments), -1
+	switch {
+	case len(segments) > 2 && (segments[2] == "tree" || segments[2] == "blob"):
+		// GitHub: owner/repo/tree/<ref>/<path>
+		repoEnd, refStart = 2, 3
+	case len(segments) > 3 && segments[2] == "src" && (segments[3] == "branch" || segments[3] == "tag" || segments[3] == "commit"):
+		// Gitea and Forgejo: owner/repo/src/branch/<ref>/<path>
+		repoEnd, refStart = 2, 4
+	default:
+		// GitLab: group/subgroup/repo/-/tree/<ref>/<path>
+		for i, segment := range segments {
+			if segment == "-" {
+				repoEnd = i
+				if i+1 < len(segments) && (segments[i+1] == "tree" || segments[i+1] == "blob") {
+					refStart = i + 2
+				} else {
+					return nil, fmt.Errorf("invalid or unsupported repository URL format: %s", repoURL)
+				}
+				break
+			}
+		}
+	}
+
+	// GitHub repositories are always owner/repo; other forges allow nested groups
+	if repoEnd < 2 || (host == "github.com" && repoEnd != 2) {
+		return nil, fmt.Errorf("invalid or unsupported repository URL format: %s", repoURL)
+	}
+
+	parsed := &RepositoryURL{
+		Host:  host,
+		Owner: strings.Join(segments[:repoEnd-1], "/"),
+		Repo:  strings.TrimSuffix(segments[repoEnd-1], ".git"),
+	}
+
+	if refStart >= 0 {
+		if refStart >= len(segments) {
+			return nil, fmt.Errorf("missing ref in repository URL: %s", repoURL)
+		}
+
+		rest, err := url.PathUnescape(strings.Join(segments[refStart:], "/"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid repository URL path: %s", repoURL)
+		}
+		parsed.refAndPath = rest
+		parsed.Ref, parsed.SubPath, _ = strings.Cut(rest, "/")
+	}
+
+	return parsed, nil
+}
+
+// parseFileURL parses file:///path/to/repo@ref into the parent directory (owner),
+// the directory name (repo) and the optional ref
+func parseFileURL(repoURL string) (*RepositoryURL, error) {
+	location, err := url.PathUnescape(strings.TrimPrefix(repoURL, "file://"))
+	if err != nil || !strings.HasPrefix(location, "/") {
+		return nil, fmt.Errorf("invalid file URL, expected file:///absolute/path: %s", repoURL)
+	}
+
+	var ref string
+	if i := strings.LastIndex(location, "@"); i > 0 {
+		location, ref = location[:i], location[i+1:]
+	}
+
+	location = path.Clean(location)
+	if location == "/" {
+		return nil, fmt.Errorf("invalid file URL, missing repository path: %s", repoURL)
+	}
+
+	return &RepositoryURL{
+		Host:       FileHost,
+		Owner:      path.Dir(location),
+		Repo:       path.Base(location),
+		Ref:        ref,
+		refAndPath: ref,
+	}, nil
+}
+
+// OverrideRef replaces the ref taken from the URL with an explicitly requested
+// one. When the URL path starts with that ref, as with refs containing slashes
+// such as release/v2, the sub path is split after it.
+func (u *RepositoryURL) OverrideRef(ref string) {
+	if ref == "" {
+		return
+	}
+
+	switch {
+	case u.refAndPath == ref:
+		u.SubPath = ""
+	case strings.HasPrefix(u.refAndPath, ref+"/"):
+		u.SubPath = strings.TrimPrefix(u.refAndPath, ref+"/")
 	}
-	return parts[1], parts[2], nil
+	u.Ref = ref
 }
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseRepositoryURL tests parsing of repository, tree and blob URLs of each forge
+func TestParseRepositoryURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		owner   string
+		repo    string
+		ref     string
+		subPath string
+	}{
+		{url: "https://github.com/google/go-github", owner: "google", repo: "go-github"},
+		{url: "github.com/google/go-github.git/", owner: "google", repo: "go-github"},
+		{url: "https://www.github.com/vercel/next.js/tree/canary", owner: "vercel", repo: "next.js", ref: "canary"},
+		{url: "https://github.com/vercel/next.js/tree/v14.0.0/docs/app?tab=readme#top", owner: "vercel", repo: "next.js", ref: "v14.0.0", subPath: "docs/app"},
+		{url: "https://github.com/owner/repo/blob/main/docs/guide.md", owner: "owner", repo: "repo", ref: "main", subPath: "docs/guide.md"},
+		{url: "https://gitlab.com/group/sub/repo", owner: "group/sub", repo: "repo"},
+		{url: "https://gitlab.com/group/repo/-/tree/v1.2/docs", owner: "group", repo: "repo", ref: "v1.2", subPath: "docs"},
+		{url: "https://codeberg.org/team/lib/src/branch/main/docs/api.md", owner: "team", repo: "lib", ref: "main", subPath: "docs/api.md"},
+		{url: "file:///srv/git/internal-sdk.git@v2.1", owner: "/srv/git", repo: "internal-sdk.git", ref: "v2.1"},
+		{url: "file:///srv/checkouts/sdk/", owner: "/srv/checkouts", repo: "sdk"},
+	}
+
+	for _, tt := range tests {
+		parsed, err := ParseRepositoryURL(tt.url)
+		require.NoError(t, err, tt.url)
+		assert.Equal(t, tt.owner, parsed.Owner, tt.url)
+		assert.Equal(t, tt.repo, parsed.Repo, tt.url)
+		assert.Equal(t, tt.ref, parsed.Ref, tt.url)
+		assert.Equal(t, tt.subPath, parsed.SubPath, tt.url)
+	}
+
+	for _, invalid := range []string{"https://gitlab.com/owner", "https://github.com/owner", "https://github.com/group/sub/repo", "https://gitlab.com/group/repo/-/issues", "file://relative/path", "file:///", "https://github.com/owner/repo/issues", "https://github.com/owner/repo/tree/"} {
+		_, err := ParseRepositoryURL(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+// TestOverrideRef tests that explicit refs win and may contain slashes
+func TestOverrideRef(t *testing.T) {
+	parsed, err := ParseRepositoryURL("https://github.com/owner/repo/tree/release/v2/docs")
+	require.NoError(t, err)
+	assert.Equal(t, "release", parsed.Ref)
+
+	parsed.OverrideRef("release/v2")
+	assert.Equal(t, "release/v2", parsed.Ref)
+	assert.Equal(t, "docs", parsed.SubPath)
+
+	parsed, err = ParseRepositoryURL("https://github.com/owner/repo/tree/main/docs")
+	require.NoError(t, err)
+	parsed.OverrideRef("v1.0.0")
+	assert.Equal(t, "v1.0.0", parsed.Ref)
+	assert.Equal(t, "docs", parsed.SubPath)
+
+	parsed.OverrideRef("")
+	assert.Equal(t, "v1.0.0", parsed.Ref)
+}
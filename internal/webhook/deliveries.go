@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dtomacheski/extract-data-go/internal/cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// deliveryRetention is how long delivery IDs are remembered; GitHub redeliveries
+// come from manual or automated retries within this window
+const deliveryRetention = 72 * time.Hour
+
+// Deliveries remembers the webhook deliveries already received
+type Deliveries interface {
+	// Mark records a delivery and reports whether it is the first time it is seen
+	Mark(ctx context.Context, id string) (bool, error)
+
+	// Forget removes a delivery so that a retry of it is handled again
+	Forget(ctx context.Context, id string) error
+}
+
+// MemoryDeliveries keeps delivery IDs in process memory
+type MemoryDeliveries struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// NewMemoryDeliveries creates an empty in-memory delivery log
+func NewMemoryDeliveries() *MemoryDeliveries {
+	return &MemoryDeliveries{seen: make(map[string]time.Time)}
+}
+
+// Mark implements Deliveries
+func (d *MemoryDeliveries) Mark(ctx context.Context, id string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for seenID, at := range d.seen {
+		if now.Sub(at) > deliveryRetention {
+			delete(d.seen, seenID)
+		}
+	}
+
+	if _, ok := d.seen[id]; ok {
+		return false, nil
+	}
+	d.seen[id] = now
+	return true, nil
+}
+
+// Forget implements Deliveries
+func (d *MemoryDeliveries) Forget(ctx context.Context, id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.seen, id)
+	return nil
+}
+
+// RedisDeliveries keeps delivery IDs in Redis, shared by all server instances
+type RedisDeliveries struct {
+	client *redis.Client
+	keys   *cache.KeyBuilder
+}
+
+// NewRedisDeliveries creates a delivery log stored in Redis
+func NewRedisDeliveries(client *redis.Client, keys *cache.KeyBuilder) *RedisDeliveries {
+	return &RedisDeliveries{client: client, keys: keys}
+}
+
+// Mark implements Deliveries
+func (d *RedisDeliveries) Mark(ctx context.Context, id string) (bool, error) {
+	return d.client.SetNX(ctx, d.keys.WebhookDeliveryKey(id), time.Now().Unix(), deliveryRetention).Result()
+}
+
+// Forget implements Deliveries
+func (d *RedisDeliveries) Forget(ctx context.Context, id string) error {
+	return d.client.Del(ctx, d.keys.WebhookDeliveryKey(id)).Err()
+}
@@ -0,0 +1,321 @@
+// Package webhook receives GitHub webhook deliveries and keeps the documentation of the
+// repositories that send them up to date: cached documentation of a ref is dropped and
+// a re-index is queued when a push touches documentation files or a release is published.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/dtomacheski/extract-data-go/internal/cache"
+	"github.com/dtomacheski/extract-data-go/internal/discovery"
+	"github.com/dtomacheski/extract-data-go/internal/jobs"
+)
+
+// githubHost is the host of the documentation cache keys of GitHub repositories
+const githubHost = "github.com"
+
+// maxPushCommits is the number of commits GitHub lists at most in a push payload;
+// files changed by the commits left out are unknown
+const maxPushCommits = 2048
+
+var (
+	// ErrInvalidSignature is returned when X-Hub-Signature-256 does not match the payload
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+
+	// ErrMalformedPayload is returned for payloads that cannot be decoded
+	ErrMalformedPayload = errors.New("malformed webhook payload")
+)
+
+// Outcomes of a delivery
+const (
+	StatusQueued    = "queued"    // Caches invalidated and a re-index queued
+	StatusDropped   = "dropped"   // Caches invalidated only, e.g. for a deleted branch
+	StatusIgnored   = "ignored"   // Nothing to do for this event
+	StatusDuplicate = "duplicate" // Delivery already received
+)
+
+// Delivery is a webhook request as sent by GitHub
+type Delivery struct {
+	ID        string // X-GitHub-Delivery
+	Event     string // X-GitHub-Event
+	Signature string // X-Hub-Signature-256
+	Body      []byte
+}
+
+// Result reports what was done for a delivery
+type Result struct {
+	Status     string `json:"status"`
+	Reason     string `json:"reason,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	Ref        string `json:"ref,omitempty"`
+	JobID      string `json:"job_id,omitempty"`
+}
+
+// JobSubmitter queues re-indexing jobs
+type JobSubmitter interface {
+	Submit(ctx context.Context, owner, repo, ref string) (*jobs.Job, error)
+}
+
+// Receiver handles GitHub webhook deliveries
+type Receiver struct {
+	secret     []byte
+	cache      cache.Cache
+	keys       *cache.KeyBuilder
+	jobs       JobSubmitter
+	deliveries Deliveries
+	logger     *log.Logger
+}
+
+// NewReceiver creates a receiver of deliveries signed with secret
+func NewReceiver(secret string, cacheClient cache.Cache, keys *cache.KeyBuilder, jobSubmitter JobSubmitter, deliveries Deliveries, logger *log.Logger) *Receiver {
+	return &Receiver{
+		secret:     []byte(secret),
+		cache:      cacheClient,
+		keys:       keys,
+		jobs:       jobSubmitter,
+		deliveries: deliveries,
+		logger:     logger,
+	}
+}
+
+// VerifySignature checks an X-Hub-Signature-256 header ("sha256=" and the hex HMAC-SHA256
+// of the body keyed with the webhook secret)
+func VerifySignature(secret, body []byte, signature string) error {
+	digest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return ErrInvalidSignature
+	}
+	received, err := hex.DecodeString(digest)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	if !hmac.Equal(received, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// target is the ref of a repository a delivery is about
+type target struct {
+	owner, repo   string
+	ref           string
+	defaultBranch bool
+	reindex       bool // Queue a re-index, otherwise only invalidate caches
+}
+
+// Handle verifies and handles a delivery. A delivery whose handling failed can be retried.
+func (r *Receiver) Handle(ctx context.Context, d Delivery) (*Result, error) {
+	if err := VerifySignature(r.secret, d.Body, d.Signature); err != nil {
+		return nil, err
+	}
+
+	if d.ID != "" && r.deliveries != nil {
+		first, err := r.deliveries.Mark(ctx, d.ID)
+		if err != nil {
+			// Handling a delivery twice is harmless, so carry on
+			r.logger.Printf("Failed to record webhook delivery %s: %v", d.ID, err)
+		} else if !first {
+			return &Result{Status: StatusDuplicate}, nil
+		}
+	}
+
+	result, err := r.handle(ctx, d)
+	if err != nil && d.ID != "" && r.deliveries != nil {
+		if forgetErr := r.deliveries.Forget(ctx, d.ID); forgetErr != nil {
+			r.logger.Printf("Failed to forget webhook delivery %s: %v", d.ID, forgetErr)
+		}
+	}
+	return result, err
+}
+
+// handle acts on a verified delivery
+func (r *Receiver) handle(ctx context.Context, d Delivery) (*Result, error) {
+	t, reason, err := parseEvent(d.Event, d.Body)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return &Result{Status: StatusIgnored, Reason: reason}, nil
+	}
+
+	result := &Result{Repository: t.owner + "/" + t.repo, Ref: t.ref, Reason: reason}
+	r.invalidate(ctx, t)
+
+	if !t.reindex {
+		result.Status = StatusDropped
+		r.logger.Printf("Webhook %s %s: dropped cached documentation of %s (ref: %s)", d.Event, d.ID, result.Repository, t.ref)
+		return result, nil
+	}
+
+	// Documentation of the default branch is cached and indexed under the empty ref
+	jobRef := t.ref
+	if t.defaultBranch {
+		jobRef = ""
+	}
+	job, err := r.jobs.Submit(ctx, t.owner, t.repo, jobRef)
+	if err != nil {
+		return nil, err
+	}
+	result.Status, result.JobID = StatusQueued, job.ID
+	r.logger.Printf("Webhook %s %s: queued re-index job %s for %s (ref: %s)", d.Event, d.ID, job.ID, result.Repository, t.ref)
+	return result, nil
+}
+
+// invalidate deletes the cached documentation of the target ref, including the documentation
+// cached for its sub paths. The per-document contents, keyed by path and blob SHA, and the index
+// incremental re-indexing compares against are kept.
+func (r *Receiver) invalidate(ctx context.Context, t *target) {
+	if r.cache == nil || !r.cache.IsEnabled() {
+		return
+	}
+
+	keys := []string{r.keys.RepositoryKey(t.owner, t.repo), r.keys.RepositoryVersionsKey(t.owner, t.repo)}
+	var prefixes []string
+	refs := []string{t.ref}
+	if t.defaultBranch {
+		refs = append(refs, "")
+	}
+	for _, ref := range refs {
+		keys = append(keys,
+			r.keys.RepositoryDocumentationKey(t.owner, t.repo, ref),
+			r.keys.RepositoryDocumentationMetadataKey(t.owner, t.repo, ref),
+		)
+		prefixes = append(prefixes, r.keys.RepositoryDocumentationPathPrefix(githubHost, t.owner, t.repo, ref))
+	}
+
+	for _, key := range keys {
+		if err := r.cache.Delete(ctx, key); err != nil {
+			r.logger.Printf("Failed to invalidate cache key %s: %v", key, err)
+		}
+	}
+	for _, prefix := range prefixes {
+		if err := r.cache.DeleteByPrefix(ctx, prefix); err != nil {
+			r.logger.Printf("Failed to invalidate cache keys %s*: %v", prefix, err)
+		}
+	}
+}
+
+// repositoryPayload is the repository of an event payload
+type repositoryPayload struct {
+	FullName      string `json:"full_name"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+// split returns the owner and name of the repository
+func (p repositoryPayload) split() (string, string, bool) {
+	owner, repo, ok := strings.Cut(p.FullName, "/")
+	return owner, repo, ok && owner != "" && repo != ""
+}
+
+// pushPayload is the part of a push event payload used here
+type pushPayload struct {
+	Ref     string `json:"ref"`
+	Deleted bool   `json:"deleted"`
+	Forced  bool   `json:"forced"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Modified []string `json:"modified"`
+		Removed  []string `json:"removed"`
+	} `json:"commits"`
+	Repository repositoryPayload `json:"repository"`
+}
+
+// releasePayload is the part of a release event payload used here
+type releasePayload struct {
+	Action  string `json:"action"`
+	Release struct {
+		TagName string `json:"tag_name"`
+	} `json:"release"`
+	Repository repositoryPayload `json:"repository"`
+}
+
+// createPayload is the part of a create event payload used here
+type createPayload struct {
+	Ref        string            `json:"ref"`
+	RefType    string            `json:"ref_type"`
+	Repository repositoryPayload `json:"repository"`
+}
+
+// parseEvent returns the ref an event is about, or nil and the reason it is ignored
+func parseEvent(event string, body []byte) (*target, string, error) {
+	var (
+		repository repositoryPayload
+		t          = &target{reindex: true}
+		reason     string
+	)
+
+	switch event {
+	case "push":
+		var payload pushPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, "", fmt.Errorf("%w: %v", ErrMalformedPayload, err)
+		}
+		repository = payload.Repository
+		t.ref = strings.TrimPrefix(strings.TrimPrefix(payload.Ref, "refs/heads/"), "refs/tags/")
+		switch {
+		case payload.Deleted:
+			t.reindex, reason = false, "ref deleted"
+		case payload.Forced:
+			reason = "forced push"
+		case len(payload.Commits) >= maxPushCommits:
+			reason = "too many commits to check"
+		default:
+			touched := false
+			for _, commit := range payload.Commits {
+				for _, files := range [][]string{commit.Added, commit.Modified, commit.Removed} {
+					for _, file := range files {
+						touched = touched || discovery.IsMarkdownFile(file)
+					}
+				}
+			}
+			if !touched {
+				return nil, "push did not touch documentation files", nil
+			}
+			reason = "documentation files changed"
+		}
+
+	case "release":
+		var payload releasePayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, "", fmt.Errorf("%w: %v", ErrMalformedPayload, err)
+		}
+		if payload.Action != "published" {
+			return nil, "release " + payload.Action, nil
+		}
+		repository, t.ref, reason = payload.Repository, payload.Release.TagName, "release published"
+
+	case "create":
+		var payload createPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, "", fmt.Errorf("%w: %v", ErrMalformedPayload, err)
+		}
+		if payload.RefType != "tag" {
+			return nil, payload.RefType + " created", nil
+		}
+		repository, t.ref, reason = payload.Repository, payload.Ref, "tag created"
+
+	case "ping":
+		return nil, "pong", nil
+
+	default:
+		return nil, "unsupported event " + event, nil
+	}
+
+	var ok bool
+	if t.owner, t.repo, ok = repository.split(); !ok || t.ref == "" {
+		return nil, "", fmt.Errorf("%w: missing repository or ref", ErrMalformedPayload)
+	}
+	t.defaultBranch = t.ref == repository.DefaultBranch
+	return t, reason, nil
+}
@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/dtomacheski/extract-data-go/internal/cache"
+	"github.com/dtomacheski/extract-data-go/internal/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "webhook-secret"
+
+// recordingJobs records the refs re-indexing jobs are submitted for
+type recordingJobs struct {
+	refs []string
+}
+
+func (r *recordingJobs) Submit(ctx context.Context, owner, repo, ref string) (*jobs.Job, error) {
+	r.refs = append(r.refs, owner+"/"+repo+"@"+ref)
+	return jobs.NewJob(owner, repo, ref), nil
+}
+
+// sign returns the X-Hub-Signature-256 header of a body
+func sign(body string) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// delivery builds a signed delivery
+func delivery(id, event, body string) Delivery {
+	return Delivery{ID: id, Event: event, Signature: sign(body), Body: []byte(body)}
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"zen":"Keep it logically awesome."}`)
+	assert.NoError(t, VerifySignature([]byte(testSecret), body, sign(string(body))))
+	assert.ErrorIs(t, VerifySignature([]byte("other"), body, sign(string(body))), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature([]byte(testSecret), body, "sha1=abc"), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature([]byte(testSecret), body, ""), ErrInvalidSignature)
+}
+
+// TestReceiver tests that documentation changes invalidate the cache and queue a re-index once per delivery
+func TestReceiver(t *testing.T) {
+	server := miniredis.RunT(t)
+	store, err := cache.NewRedisClient(cache.RedisConfig{RedisURI: "redis://" + server.Addr(), Enabled: true, Logger: log.New(io.Discard, "", 0)})
+	require.NoError(t, err)
+	defer store.Close()
+
+	keys := cache.NewKeyBuilder("test")
+	submitted := &recordingJobs{}
+	receiver := NewReceiver(testSecret, store, keys, submitted, NewRedisDeliveries(store.Client(), keys), log.New(io.Discard, "", 0))
+	ctx := context.Background()
+
+	metadataKey := keys.RepositoryDocumentationMetadataKey("acme", "widgets", "")
+	require.NoError(t, store.Set(ctx, metadataKey, "cached"))
+	rootKey := keys.RepositoryDocumentationPathKey(githubHost, "acme", "widgets", "main", "")
+	subPathKey := keys.RepositoryDocumentationPathKey(githubHost, "acme", "widgets", "main", "docs/guides")
+	otherRefKey := keys.RepositoryDocumentationPathKey(githubHost, "acme", "widgets", "main-old", "docs")
+	for _, key := range []string{rootKey, subPathKey, otherRefKey} {
+		require.NoError(t, store.Set(ctx, key, "cached"))
+	}
+
+	_, err = receiver.Handle(ctx, Delivery{ID: "0", Event: "push", Signature: sign("{}"), Body: []byte(`{"forced":true}`)})
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	codeOnly := `{"ref":"refs/heads/main","commits":[{"added":["main.go"],"modified":["go.mod"]}],
+		"repository":{"full_name":"acme/widgets","default_branch":"main"}}`
+	result, err := receiver.Handle(ctx, delivery("1", "push", codeOnly))
+	require.NoError(t, err)
+	assert.Equal(t, StatusIgnored, result.Status)
+	assert.True(t, server.Exists(metadataKey))
+	assert.True(t, server.Exists(subPathKey))
+
+	docs := `{"ref":"refs/heads/main","commits":[{"modified":["main.go"]},{"removed":["docs/Guide.MD"]}],
+		"repository":{"full_name":"acme/widgets","default_branch":"main"}}`
+	result, err = receiver.Handle(ctx, delivery("2", "push", docs))
+	require.NoError(t, err)
+	assert.Equal(t, StatusQueued, result.Status)
+	assert.Equal(t, "acme/widgets", result.Repository)
+	assert.Equal(t, "main", result.Ref)
+	assert.NotEmpty(t, result.JobID)
+	assert.False(t, server.Exists(metadataKey))
+
+	// The documentation cached for every sub path of the ref goes, other refs stay
+	assert.False(t, server.Exists(rootKey))
+	assert.False(t, server.Exists(subPathKey))
+	assert.True(t, server.Exists(otherRefKey))
+
+	// A redelivery is recognized
+	result, err = receiver.Handle(ctx, delivery("2", "push", docs))
+	require.NoError(t, err)
+	assert.Equal(t, StatusDuplicate, result.Status)
+
+	release := `{"action":"published","release":{"tag_name":"v2.0.0"},"repository":{"full_name":"acme/widgets","default_branch":"main"}}`
+	result, err = receiver.Handle(ctx, delivery("3", "release", release))
+	require.NoError(t, err)
+	assert.Equal(t, StatusQueued, result.Status)
+
+	tag := `{"ref":"v2.0.1","ref_type":"tag","repository":{"full_name":"acme/widgets","default_branch":"main"}}`
+	result, err = receiver.Handle(ctx, delivery("4", "create", tag))
+	require.NoError(t, err)
+	assert.Equal(t, StatusQueued, result.Status)
+
+	branch := `{"ref":"feature","ref_type":"branch","repository":{"full_name":"acme/widgets","default_branch":"main"}}`
+	result, err = receiver.Handle(ctx, delivery("5", "create", branch))
+	require.NoError(t, err)
+	assert.Equal(t, StatusIgnored, result.Status)
+
+	deleted := `{"ref":"refs/heads/feature","deleted":true,"repository":{"full_name":"acme/widgets","default_branch":"main"}}`
+	result, err = receiver.Handle(ctx, delivery("6", "push", deleted))
+	require.NoError(t, err)
+	assert.Equal(t, StatusDropped, result.Status)
+
+	// Malformed deliveries can be sent again once fixed
+	_, err = receiver.Handle(ctx, delivery("7", "push", `{"ref":`))
+	assert.ErrorIs(t, err, ErrMalformedPayload)
+	_, err = receiver.Handle(ctx, delivery("7", "push", codeOnly))
+	require.NoError(t, err)
+
+	// The default branch is re-indexed under the empty ref
+	assert.Equal(t, []string{"acme/widgets@", "acme/widgets@v2.0.0", "acme/widgets@v2.0.1"}, submitted.refs)
+}
@@ -15,13 +15,33 @@ import (
 	"github.com/dtomacheski/extract-data-go/internal/auth"
 	"github.com/dtomacheski/extract-data-go/internal/cache"
 	"github.com/dtomacheski/extract-data-go/internal/database"
+	"github.com/dtomacheski/extract-data-go/internal/gitea"
 	"github.com/dtomacheski/extract-data-go/internal/github"
+	"github.com/dtomacheski/extract-data-go/internal/gitlab"
+	"github.com/dtomacheski/extract-data-go/internal/jobs"
+	"github.com/dtomacheski/extract-data-go/internal/local"
+	"github.com/dtomacheski/extract-data-go/internal/mcp"
 	"github.com/dtomacheski/extract-data-go/internal/repository"
+	"github.com/dtomacheski/extract-data-go/internal/scheduler"
+	"github.com/dtomacheski/extract-data-go/internal/source"
+	"github.com/dtomacheski/extract-data-go/internal/utils"
+	"github.com/dtomacheski/extract-data-go/internal/webhook"
 )
 
+// serverVersion is reported to MCP clients during initialization
+const serverVersion = "0.1.0"
+
 func main() {
+	// Determine run mode: "mcp" speaks MCP over stdio, anything else starts the HTTP API
+	mcpMode := len(os.Args) > 1 && os.Args[1] == "mcp"
+
 	// Initialize logger
-	logger := log.New(os.Stdout, "[GITHUB-DOC-API] ", log.LstdFlags)
+	// In MCP mode stdout carries the protocol, so logs must go to stderr
+	logOutput := os.Stdout
+	if mcpMode {
+		logOutput = os.Stderr
+	}
+	logger := log.New(logOutput, "[GITHUB-DOC-API] ", log.LstdFlags)
 
 	// Load configuration
 	cfg, err := config.Load()
@@ -30,7 +50,17 @@ func main() {
 	}
 
 	// Initialize GitHub client
-	githubClient := github.NewClient(cfg.GitHubToken, cfg.RequestTimeout)
+	githubClient := github.NewClientWithTokens(cfg.GitHubTokens, cfg.RequestTimeout)
+	githubClient.SetArchiveFetchThreshold(cfg.ArchiveFetchThreshold)
+	githubClient.SetGraphQLFetchThreshold(cfg.GraphQLFetchThreshold)
+	if cfg.GitHubAppID != 0 {
+		app, err := github.NewApp(cfg.GitHubAppID, cfg.GitHubAppPrivateKey, "")
+		if err != nil {
+			logger.Fatalf("Failed to configure GitHub App authentication: %v", err)
+		}
+		githubClient.UseApp(app)
+		logger.Printf("Using GitHub App %d installation tokens for accounts the app is installed on", cfg.GitHubAppID)
+	}
 
 	// Initialize MongoDB client if enabled
 	var mongoClient *database.Client
@@ -41,6 +71,7 @@ func main() {
 			logger.Fatalf("Failed to connect to MongoDB: %v", err)
 		}
 		logger.Println("Successfully connected to MongoDB")
+		mongoClient.SetHistoryRetention(cfg.DocHistoryLimit, cfg.DocHistoryMaxAge)
 		
 		// Ensure MongoDB client is closed on shutdown
 		defer func() {
@@ -55,6 +86,11 @@ func main() {
 	// Initialize document repository
 	docRepo := repository.NewDocumentRepository(mongoClient, logger)
 
+	if mcpMode {
+		runMCPServer(cfg, githubClient, docRepo, logger)
+		return
+	}
+
 	// Initialize Redis cache if enabled
 	var cacheClient cache.Cache
 	if cfg.EnableCache {
@@ -94,8 +130,29 @@ func main() {
 
 	// Create API handler
 	handler := api.NewHandler(githubClient, docRepo, cacheClient, logger, cfg.WorkerPoolSize, userStore, jwtService)
+
+	// Revalidate GitHub responses with ETags so that unchanged data does not count against the rate limit
+	githubClient.EnableConditionalRequests(cacheClient, handler.KeyBuilder)
 	// Set minimum days between refreshes from config
 	handler.MinDaysBetweenRefreshes = cfg.MinDaysBetweenRefreshes
+	// Serve repository URLs of the configured GitLab and Gitea instances
+	handler.Sources = newDocSources(cfg, githubClient, logger)
+	// Run indexing jobs in the background, queued in Redis when the cache is available
+	handler.Jobs = newJobManager(cfg, cacheClient, handler, docRepo, logger)
+	handler.Jobs.Start()
+	// Refresh tracked repositories on their schedule; the list is kept in MongoDB
+	if mongoClient != nil {
+		handler.Scheduler = scheduler.NewScheduler(mongoClient, handler.Jobs, githubClient, cfg.SchedulerConcurrency, cfg.SchedulerPollInterval, logger)
+		handler.Scheduler.Start()
+	} else {
+		logger.Println("Scheduled refreshes disabled - MongoDB is required to track repositories")
+	}
+	// Re-index repositories when GitHub reports documentation changes
+	if cfg.GitHubWebhookSecret != "" {
+		handler.Webhooks = newWebhookReceiver(cfg, cacheClient, handler)
+	}
+	// Expose the MCP server over HTTP under /mcp
+	handler.MCPTransport = mcp.NewHTTPTransport(newMCPServer(cfg, githubClient, docRepo, logger), "/mcp/messages", logger)
 
 	// Set up router
 	router := api.SetupRouter(handler)
@@ -123,6 +180,9 @@ func main() {
 	<-quit
 	logger.Println("Shutting down server...")
 
+	// Close MCP sessions first so their open streams do not hold up shutdown
+	handler.MCPTransport.Shutdown()
+
 	// Create a deadline for graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -132,5 +192,96 @@ func main() {
 		logger.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Stop scheduling refreshes before the job workers they wait for
+	if handler.Scheduler != nil {
+		handler.Scheduler.Stop()
+	}
+
+	// Stop the job workers; interrupted jobs are resumed on the next start
+	handler.Jobs.Stop()
+
 	logger.Println("Server exited gracefully")
 }
+
+// newDocSources maps repository hosts to their documentation source: github.com
+// plus the GitLab and Gitea/Forgejo instances and local root enabled in the configuration
+func newDocSources(cfg *config.Config, githubClient *github.Client, logger *log.Logger) *source.Providers {
+	providers := source.NewProviders(githubClient)
+
+	if cfg.GitLabURL != "" {
+		if err := providers.RegisterURL(cfg.GitLabURL, gitlab.NewClient(cfg.GitLabURL, cfg.GitLabToken, cfg.RequestTimeout)); err != nil {
+			logger.Fatalf("Invalid GITLAB_URL: %v", err)
+		}
+	}
+	if cfg.GiteaURL != "" {
+		if err := providers.RegisterURL(cfg.GiteaURL, gitea.NewClient(cfg.GiteaURL, cfg.GiteaToken, cfg.RequestTimeout)); err != nil {
+			logger.Fatalf("Invalid GITEA_URL: %v", err)
+		}
+	}
+
+	if cfg.LocalSourceRoot != "" {
+		localSource, err := local.NewSource(cfg.LocalSourceRoot, cfg.RequestTimeout)
+		if err != nil {
+			logger.Fatalf("Invalid LOCAL_SOURCE_ROOT: %v", err)
+		}
+		providers.Register(utils.FileHost, localSource)
+	}
+
+	logger.Printf("Documentation sources enabled for: %v", providers.Hosts())
+	return providers
+}
+
+// newJobManager creates the indexing job manager. Jobs are kept in Redis when the cache is
+// connected, so that they survive restarts, and in process memory otherwise.
+func newJobManager(cfg *config.Config, cacheClient cache.Cache, handler *api.Handler, docRepo *repository.DocumentRepository, logger *log.Logger) *jobs.Manager {
+	var queue jobs.Queue = jobs.NewMemoryQueue(0)
+	if redisClient, ok := cacheClient.(*cache.RedisClient); ok && redisClient.IsEnabled() {
+		queue = jobs.NewRedisQueue(redisClient.Client(), handler.KeyBuilder)
+		logger.Println("Indexing jobs are queued in Redis")
+	} else {
+		logger.Println("Indexing jobs are queued in memory and lost on restart")
+	}
+
+	pipeline := jobs.NewPipeline(handler.GitHubClient, handler.Indexer, docRepo, cfg.WorkerPoolSize, logger)
+	return jobs.NewManager(queue, pipeline, cfg.IndexJobWorkers, cfg.IndexJobTimeout, logger)
+}
+
+// newWebhookReceiver creates the GitHub webhook receiver. Delivery IDs are kept in Redis
+// when the cache is connected, so that redeliveries to any instance are recognized.
+func newWebhookReceiver(cfg *config.Config, cacheClient cache.Cache, handler *api.Handler) *webhook.Receiver {
+	var deliveries webhook.Deliveries = webhook.NewMemoryDeliveries()
+	if redisClient, ok := cacheClient.(*cache.RedisClient); ok && redisClient.IsEnabled() {
+		deliveries = webhook.NewRedisDeliveries(redisClient.Client(), handler.KeyBuilder)
+	}
+	return webhook.NewReceiver(cfg.GitHubWebhookSecret, cacheClient, handler.KeyBuilder, handler.Jobs, deliveries, handler.Logger)
+}
+
+// runMCPServer serves the Model Context Protocol over stdin/stdout until the
+// input is closed or the process receives SIGINT/SIGTERM
+func runMCPServer(cfg *config.Config, githubClient *github.Client, docRepo *repository.DocumentRepository, logger *log.Logger) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	server := newMCPServer(cfg, githubClient, docRepo, logger)
+
+	logger.Println("Starting MCP server on stdio")
+	if err := server.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil && err != context.Canceled {
+		logger.Fatalf("MCP server failed: %v", err)
+	}
+	logger.Println("MCP server exited")
+}
+
+// newMCPServer creates the MCP server with the documentation tools registered.
+// Stored documentation is exposed as resources when MongoDB is enabled.
+func newMCPServer(cfg *config.Config, githubClient *github.Client, docRepo *repository.DocumentRepository, logger *log.Logger) *mcp.Server {
+	server := mcp.NewServer("go-mcpdocs", serverVersion, logger)
+	server.SetInstructions("Use resolve-library-id to find a library ID, then get-library-docs to fetch its documentation snippets.")
+	mcp.NewDocsTools(githubClient, cfg.WorkerPoolSize, logger).Register(server)
+
+	if docRepo.IsEnabled() {
+		resources := mcp.NewDocsResources(docRepo)
+		server.SetResourceProvider(resources)
+		docRepo.OnDocumentStored(resources.UpdateListener(server))
+	}
+	return server
+}